@@ -3,21 +3,160 @@ package configs
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
 	AppPort     string
+	FrontendURL string
 	DatabaseURL string
 	JWTSecret   string
+	JWT         JWTConfig
+	MFA         MFAConfig
+	Login       LoginProtectionConfig
+	Password    PasswordConfig
+	Email       EmailVerificationConfig
+	Links       LinkConfig
+	Cookie      CookieConfig
+	OIDC        OIDCConfig
 	SMTP        SMTPConfig
 	Auth        AuthConfig
+	Policy      PolicyConfig
+	Users       UsersConfig
 	// RedisCfg    RedisConfig
 	RateLimiter RateLimiterConfig
 }
 
+type JWTConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	SessionCacheTTL time.Duration
+	// PermissionCacheTTL is how long the permissions of a role are cached in memory.
+	PermissionCacheTTL time.Duration
+	// AuthVersionCacheTTL is how long a user's auth version is cached in memory. Bumps
+	// made by this process clear the cached entry at once; bumps made by another
+	// instance are only seen once the entry expires, so it bounds how long an access
+	// token issued before a role or password change keeps working there.
+	AuthVersionCacheTTL time.Duration
+	// Issuer and Audience are set on issued tokens and required on validation.
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated when validating exp, nbf and iat.
+	Leeway time.Duration
+	// SigningKeyFile is a PEM encoded RSA, ECDSA or Ed25519 private key. When empty,
+	// tokens are signed with JWTSecret using HS256.
+	SigningKeyFile string
+	// RetiredKeyFiles are keys that no longer sign tokens but are still accepted
+	// during validation, so tokens issued before a rotation stay valid until they expire.
+	RetiredKeyFiles []string
+}
+
+type MFAConfig struct {
+	// Issuer is the account label shown in authenticator apps.
+	Issuer string
+	// EncryptionKey encrypts TOTP secrets at rest. Falls back to JWTSecret, with a
+	// warning, when MFA_ENCRYPTION_KEY is not set.
+	EncryptionKey   string
+	PendingTokenTTL time.Duration
+	// RequiredForAdmin makes /admin routes reject tokens that were not issued
+	// after a second factor was verified. It is off by default: admins have to
+	// enroll in MFA before it is turned on, or they lose access to /admin.
+	RequiredForAdmin bool
+}
+
+// LoginProtectionConfig controls backoff and lockout after failed logins.
+type LoginProtectionConfig struct {
+	FreeAttempts            int
+	AccountLockoutThreshold int
+	IPLockoutThreshold      int
+	BaseDelay               time.Duration
+	MaxDelay                time.Duration
+	LockoutDuration         time.Duration
+	FailureWindow           time.Duration
+}
+
+type PasswordConfig struct {
+	// HashAlgorithm is "argon2id" or "bcrypt". Hashes made with the other one are
+	// still accepted and replaced on the next successful login.
+	HashAlgorithm string
+	BcryptCost    int
+	// Argon2Memory is in KiB.
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	// Policy applied to new passwords.
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// DisallowUserInfo rejects passwords containing the username or email.
+	DisallowUserInfo bool
+	// BreachedFile lists SHA-1 hashes of breached passwords, which are rejected. The
+	// check is disabled when it is empty.
+	BreachedFile string
+}
+
+type EmailVerificationConfig struct {
+	TokenTTL time.Duration
+	// UnverifiedLoginMode is "allow", "restricted" or "deny".
+	UnverifiedLoginMode string
+}
+
+// LinkConfig sets how long the single-use links emailed to users stay valid.
+type LinkConfig struct {
+	PasswordResetTTL time.Duration
+	MagicLinkTTL     time.Duration
+}
+
+// CookieConfig sets the attributes of cookies issued by the API.
+type CookieConfig struct {
+	// Secure restricts cookies to HTTPS. Only turn it off for local development over
+	// plain HTTP.
+	Secure bool
+	// Domain is the cookie domain. Empty scopes cookies to the API host.
+	Domain   string
+	SameSite http.SameSite
+	// AuthEnabled lets logins that ask for it with X-Auth-Mode: cookie set the access
+	// and refresh tokens as HttpOnly cookies, which AuthMiddleware accepts in place of
+	// the Authorization header.
+	AuthEnabled bool
+	// RememberMeTTL is how long a remember-me cookie stays valid after it was issued
+	// or last used.
+	RememberMeTTL time.Duration
+}
+
+// OIDCConfig lists the OpenID Connect providers users can log in with.
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	// AuthRequestTTL is how long a user has to complete a login at a provider.
+	AuthRequestTTL time.Duration
+}
+
+// OIDCProviderConfig is a provider set up with OIDC_PROVIDERS. Its settings are read
+// from OIDC_<NAME>_* variables, e.g. OIDC_OKTA_ISSUER for a provider named okta.
+type OIDCProviderConfig struct {
+	// Name identifies the provider in /login/oidc/{provider} and in linked identities.
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback registered at the provider, normally
+	// <api>/api/v1/login/oidc/<name>/callback or a frontend page that forwards to it.
+	RedirectURL string
+	Scopes      []string
+	// AutoProvision creates an account on the first login of an identity whose email
+	// does not belong to an existing account.
+	AutoProvision bool
+	// DefaultRole is the role of provisioned accounts.
+	DefaultRole string
+}
+
 type SMTPConfig struct {
 	Host        string
 	Port        int
@@ -35,6 +174,27 @@ type BasicAuthConfig struct {
 	Pass string
 }
 
+// PolicyConfig points to the YAML access policy file evaluated on every API request.
+type PolicyConfig struct {
+	// File is the policy file path. Policy checks are disabled when it is empty.
+	File string
+	// ReloadInterval is how often the file is checked for changes.
+	ReloadInterval time.Duration
+}
+
+type UsersConfig struct {
+	// DeletedRetention is how long soft-deleted users are kept before they are purged
+	// for good. Zero keeps them until an admin deletes them.
+	DeletedRetention time.Duration
+	// PurgeInterval is how often users past DeletedRetention are looked for.
+	PurgeInterval time.Duration
+	// ErasureGracePeriod is how long after an erasure request the user is erased, so
+	// the request can still be cancelled.
+	ErasureGracePeriod time.Duration
+	// ErasureInterval is how often users past their grace period are looked for.
+	ErasureInterval time.Duration
+}
+
 // type RedisConfig struct {
 // 	Enabled  bool
 // 	Addr     string
@@ -58,7 +218,253 @@ func LoadConfig() *Config {
 	dbPort := getEnv("POSTGRES_PORT", "5432")
 
 	appPort := getEnv("APP_PORT", "8080")
+	frontendURL := strings.TrimRight(getEnv("FRONTEND_URL", "http://localhost:3000"), "/")
 	jwtSecret := getEnv("JWT_SECRET", "secret_jwt_key")
+	accessTokenTTLStr := getEnv("JWT_ACCESS_TOKEN_TTL", "15m")
+	accessTokenTTL, err := time.ParseDuration(accessTokenTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid JWT_ACCESS_TOKEN_TTL value, using 15m: %v", err)
+		accessTokenTTL = 15 * time.Minute
+	}
+	refreshTokenTTLStr := getEnv("JWT_REFRESH_TOKEN_TTL", "720h")
+	refreshTokenTTL, err := time.ParseDuration(refreshTokenTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid JWT_REFRESH_TOKEN_TTL value, using 720h: %v", err)
+		refreshTokenTTL = 720 * time.Hour
+	}
+	sessionCacheTTLStr := getEnv("JWT_SESSION_CACHE_TTL", "30s")
+	sessionCacheTTL, err := time.ParseDuration(sessionCacheTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid JWT_SESSION_CACHE_TTL value, using 30s: %v", err)
+		sessionCacheTTL = 30 * time.Second
+	}
+	permissionCacheTTLStr := getEnv("JWT_PERMISSION_CACHE_TTL", "1m")
+	permissionCacheTTL, err := time.ParseDuration(permissionCacheTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid JWT_PERMISSION_CACHE_TTL value, using 1m: %v", err)
+		permissionCacheTTL = time.Minute
+	}
+	authVersionCacheTTLStr := getEnv("JWT_AUTH_VERSION_CACHE_TTL", "30s")
+	authVersionCacheTTL, err := time.ParseDuration(authVersionCacheTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid JWT_AUTH_VERSION_CACHE_TTL value, using 30s: %v", err)
+		authVersionCacheTTL = 30 * time.Second
+	}
+	jwtIssuer := getEnv("JWT_ISSUER", "external-backend-go")
+	jwtAudience := getEnv("JWT_AUDIENCE", "external-backend-go")
+	jwtLeewayStr := getEnv("JWT_LEEWAY", "30s")
+	jwtLeeway, err := time.ParseDuration(jwtLeewayStr)
+	if err != nil {
+		log.Printf("Warning: Invalid JWT_LEEWAY value, using 30s: %v", err)
+		jwtLeeway = 30 * time.Second
+	}
+	signingKeyFile := getEnv("JWT_SIGNING_KEY_FILE", "")
+	retiredKeyFiles := splitList(getEnv("JWT_RETIRED_KEY_FILES", ""))
+
+	mfaIssuer := getEnv("MFA_ISSUER", "external-backend-go")
+	mfaEncryptionKey := getEnv("MFA_ENCRYPTION_KEY", "")
+	if mfaEncryptionKey == "" {
+		log.Printf("Warning: MFA_ENCRYPTION_KEY is not set, encrypting TOTP secrets with JWT_SECRET; set a separate key so rotating one does not affect the other")
+		mfaEncryptionKey = jwtSecret
+	}
+	mfaPendingTokenTTLStr := getEnv("MFA_PENDING_TOKEN_TTL", "5m")
+	mfaPendingTokenTTL, err := time.ParseDuration(mfaPendingTokenTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid MFA_PENDING_TOKEN_TTL value, using 5m: %v", err)
+		mfaPendingTokenTTL = 5 * time.Minute
+	}
+	mfaRequiredForAdminStr := getEnv("MFA_REQUIRED_FOR_ADMIN", "false")
+	mfaRequiredForAdmin, err := strconv.ParseBool(mfaRequiredForAdminStr)
+	if err != nil {
+		log.Printf("Warning: Invalid MFA_REQUIRED_FOR_ADMIN value, using false: %v", err)
+		mfaRequiredForAdmin = false
+	}
+
+	loginFreeAttemptsStr := getEnv("LOGIN_FREE_ATTEMPTS", "3")
+	loginFreeAttempts, err := strconv.Atoi(loginFreeAttemptsStr)
+	if err != nil {
+		log.Printf("Warning: Invalid LOGIN_FREE_ATTEMPTS value, using 3: %v", err)
+		loginFreeAttempts = 3
+	}
+	loginAccountLockoutThresholdStr := getEnv("LOGIN_ACCOUNT_LOCKOUT_THRESHOLD", "10")
+	loginAccountLockoutThreshold, err := strconv.Atoi(loginAccountLockoutThresholdStr)
+	if err != nil {
+		log.Printf("Warning: Invalid LOGIN_ACCOUNT_LOCKOUT_THRESHOLD value, using 10: %v", err)
+		loginAccountLockoutThreshold = 10
+	}
+	loginIPLockoutThresholdStr := getEnv("LOGIN_IP_LOCKOUT_THRESHOLD", "50")
+	loginIPLockoutThreshold, err := strconv.Atoi(loginIPLockoutThresholdStr)
+	if err != nil {
+		log.Printf("Warning: Invalid LOGIN_IP_LOCKOUT_THRESHOLD value, using 50: %v", err)
+		loginIPLockoutThreshold = 50
+	}
+	loginBackoffBaseStr := getEnv("LOGIN_BACKOFF_BASE", "1s")
+	loginBackoffBase, err := time.ParseDuration(loginBackoffBaseStr)
+	if err != nil {
+		log.Printf("Warning: Invalid LOGIN_BACKOFF_BASE value, using 1s: %v", err)
+		loginBackoffBase = time.Second
+	}
+	loginBackoffMaxStr := getEnv("LOGIN_BACKOFF_MAX", "5m")
+	loginBackoffMax, err := time.ParseDuration(loginBackoffMaxStr)
+	if err != nil {
+		log.Printf("Warning: Invalid LOGIN_BACKOFF_MAX value, using 5m: %v", err)
+		loginBackoffMax = 5 * time.Minute
+	}
+	loginLockoutDurationStr := getEnv("LOGIN_LOCKOUT_DURATION", "15m")
+	loginLockoutDuration, err := time.ParseDuration(loginLockoutDurationStr)
+	if err != nil {
+		log.Printf("Warning: Invalid LOGIN_LOCKOUT_DURATION value, using 15m: %v", err)
+		loginLockoutDuration = 15 * time.Minute
+	}
+	loginFailureWindowStr := getEnv("LOGIN_FAILURE_WINDOW", "24h")
+	loginFailureWindow, err := time.ParseDuration(loginFailureWindowStr)
+	if err != nil {
+		log.Printf("Warning: Invalid LOGIN_FAILURE_WINDOW value, using 24h: %v", err)
+		loginFailureWindow = 24 * time.Hour
+	}
+
+	passwordHashAlgorithm := getEnv("PASSWORD_HASH_ALGORITHM", "argon2id")
+	switch passwordHashAlgorithm {
+	case "argon2id", "bcrypt":
+	default:
+		log.Printf("Warning: Invalid PASSWORD_HASH_ALGORITHM value %q, using argon2id", passwordHashAlgorithm)
+		passwordHashAlgorithm = "argon2id"
+	}
+	bcryptCostStr := getEnv("BCRYPT_COST", "12")
+	bcryptCost, err := strconv.Atoi(bcryptCostStr)
+	if err != nil || bcryptCost < 4 || bcryptCost > 31 {
+		log.Printf("Warning: Invalid BCRYPT_COST value, using 12: %v", err)
+		bcryptCost = 12
+	}
+	argon2MemoryStr := getEnv("ARGON2_MEMORY_KIB", "65536")
+	argon2Memory, err := strconv.ParseUint(argon2MemoryStr, 10, 32)
+	if err != nil || argon2Memory < 8*1024 {
+		log.Printf("Warning: Invalid ARGON2_MEMORY_KIB value, using 65536: %v", err)
+		argon2Memory = 64 * 1024
+	}
+	argon2IterationsStr := getEnv("ARGON2_ITERATIONS", "3")
+	argon2Iterations, err := strconv.ParseUint(argon2IterationsStr, 10, 32)
+	if err != nil || argon2Iterations < 1 {
+		log.Printf("Warning: Invalid ARGON2_ITERATIONS value, using 3: %v", err)
+		argon2Iterations = 3
+	}
+	argon2ParallelismStr := getEnv("ARGON2_PARALLELISM", "4")
+	argon2Parallelism, err := strconv.ParseUint(argon2ParallelismStr, 10, 8)
+	if err != nil || argon2Parallelism < 1 {
+		log.Printf("Warning: Invalid ARGON2_PARALLELISM value, using 4: %v", err)
+		argon2Parallelism = 4
+	}
+	passwordMinLengthStr := getEnv("PASSWORD_MIN_LENGTH", "8")
+	passwordMinLength, err := strconv.Atoi(passwordMinLengthStr)
+	if err != nil || passwordMinLength < 1 {
+		log.Printf("Warning: Invalid PASSWORD_MIN_LENGTH value, using 8: %v", err)
+		passwordMinLength = 8
+	}
+	passwordMaxLengthStr := getEnv("PASSWORD_MAX_LENGTH", "255")
+	passwordMaxLength, err := strconv.Atoi(passwordMaxLengthStr)
+	if err != nil || passwordMaxLength < passwordMinLength {
+		log.Printf("Warning: Invalid PASSWORD_MAX_LENGTH value, using 255: %v", err)
+		passwordMaxLength = 255
+	}
+	passwordRequireUppercaseStr := getEnv("PASSWORD_REQUIRE_UPPERCASE", "false")
+	passwordRequireUppercase, err := strconv.ParseBool(passwordRequireUppercaseStr)
+	if err != nil {
+		log.Printf("Warning: Invalid PASSWORD_REQUIRE_UPPERCASE value, using false: %v", err)
+		passwordRequireUppercase = false
+	}
+	passwordRequireLowercaseStr := getEnv("PASSWORD_REQUIRE_LOWERCASE", "false")
+	passwordRequireLowercase, err := strconv.ParseBool(passwordRequireLowercaseStr)
+	if err != nil {
+		log.Printf("Warning: Invalid PASSWORD_REQUIRE_LOWERCASE value, using false: %v", err)
+		passwordRequireLowercase = false
+	}
+	passwordRequireDigitStr := getEnv("PASSWORD_REQUIRE_DIGIT", "false")
+	passwordRequireDigit, err := strconv.ParseBool(passwordRequireDigitStr)
+	if err != nil {
+		log.Printf("Warning: Invalid PASSWORD_REQUIRE_DIGIT value, using false: %v", err)
+		passwordRequireDigit = false
+	}
+	passwordRequireSymbolStr := getEnv("PASSWORD_REQUIRE_SYMBOL", "false")
+	passwordRequireSymbol, err := strconv.ParseBool(passwordRequireSymbolStr)
+	if err != nil {
+		log.Printf("Warning: Invalid PASSWORD_REQUIRE_SYMBOL value, using false: %v", err)
+		passwordRequireSymbol = false
+	}
+	passwordDisallowUserInfoStr := getEnv("PASSWORD_DISALLOW_USER_INFO", "true")
+	passwordDisallowUserInfo, err := strconv.ParseBool(passwordDisallowUserInfoStr)
+	if err != nil {
+		log.Printf("Warning: Invalid PASSWORD_DISALLOW_USER_INFO value, using true: %v", err)
+		passwordDisallowUserInfo = true
+	}
+	passwordBreachedFile := getEnv("PASSWORD_BREACHED_FILE", "configs/breached-passwords.txt")
+
+	emailVerificationTTLStr := getEnv("EMAIL_VERIFICATION_TOKEN_TTL", "24h")
+	emailVerificationTTL, err := time.ParseDuration(emailVerificationTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid EMAIL_VERIFICATION_TOKEN_TTL value, using 24h: %v", err)
+		emailVerificationTTL = 24 * time.Hour
+	}
+	passwordResetTTLStr := getEnv("PASSWORD_RESET_TOKEN_TTL", "15m")
+	passwordResetTTL, err := time.ParseDuration(passwordResetTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid PASSWORD_RESET_TOKEN_TTL value, using 15m: %v", err)
+		passwordResetTTL = 15 * time.Minute
+	}
+	magicLinkTTLStr := getEnv("MAGIC_LINK_TTL", "15m")
+	magicLinkTTL, err := time.ParseDuration(magicLinkTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid MAGIC_LINK_TTL value, using 15m: %v", err)
+		magicLinkTTL = 15 * time.Minute
+	}
+	rememberMeTTLStr := getEnv("REMEMBER_ME_TTL", "720h")
+	rememberMeTTL, err := time.ParseDuration(rememberMeTTLStr)
+	if err != nil || rememberMeTTL <= 0 {
+		log.Printf("Warning: Invalid REMEMBER_ME_TTL value, using 720h: %v", err)
+		rememberMeTTL = 720 * time.Hour
+	}
+	cookieSecureStr := getEnv("COOKIE_SECURE", "true")
+	cookieSecure, err := strconv.ParseBool(cookieSecureStr)
+	if err != nil {
+		log.Printf("Warning: Invalid COOKIE_SECURE value, using true: %v", err)
+		cookieSecure = true
+	}
+	cookieDomain := getEnv("COOKIE_DOMAIN", "")
+	cookieSameSiteStr := getEnv("COOKIE_SAMESITE", "strict")
+	var cookieSameSite http.SameSite
+	switch cookieSameSiteStr {
+	case "strict":
+		cookieSameSite = http.SameSiteStrictMode
+	case "lax":
+		cookieSameSite = http.SameSiteLaxMode
+	case "none":
+		cookieSameSite = http.SameSiteNoneMode
+		if !cookieSecure {
+			log.Printf("Warning: COOKIE_SAMESITE=none requires COOKIE_SECURE=true, browsers will reject the cookies")
+		}
+	default:
+		log.Printf("Warning: Invalid COOKIE_SAMESITE value %q, using strict", cookieSameSiteStr)
+		cookieSameSite = http.SameSiteStrictMode
+	}
+	cookieAuthEnabledStr := getEnv("COOKIE_AUTH_ENABLED", "false")
+	cookieAuthEnabled, err := strconv.ParseBool(cookieAuthEnabledStr)
+	if err != nil {
+		log.Printf("Warning: Invalid COOKIE_AUTH_ENABLED value, using false: %v", err)
+		cookieAuthEnabled = false
+	}
+	oidcProviders := loadOIDCProviders(splitList(getEnv("OIDC_PROVIDERS", "")))
+	oidcAuthRequestTTLStr := getEnv("OIDC_AUTH_REQUEST_TTL", "10m")
+	oidcAuthRequestTTL, err := time.ParseDuration(oidcAuthRequestTTLStr)
+	if err != nil {
+		log.Printf("Warning: Invalid OIDC_AUTH_REQUEST_TTL value, using 10m: %v", err)
+		oidcAuthRequestTTL = 10 * time.Minute
+	}
+	unverifiedLoginMode := getEnv("UNVERIFIED_LOGIN_MODE", "allow")
+	switch unverifiedLoginMode {
+	case "allow", "restricted", "deny":
+	default:
+		log.Printf("Warning: Invalid UNVERIFIED_LOGIN_MODE value %q, using allow", unverifiedLoginMode)
+		unverifiedLoginMode = "allow"
+	}
 
 	smtpHost := getEnv("SMTP_HOST", "")
 	smtpPortStr := getEnv("SMTP_PORT", "0")
@@ -69,6 +475,39 @@ func LoadConfig() *Config {
 	basicAuthUser := getEnv("BASIC_AUTH_USER", "admin")
 	basicAuthPass := getEnv("BASIC_AUTH_PASS", "password")
 
+	policyFile := getEnv("POLICY_FILE", "configs/policies.yaml")
+	policyReloadIntervalStr := getEnv("POLICY_RELOAD_INTERVAL", "10s")
+	policyReloadInterval, err := time.ParseDuration(policyReloadIntervalStr)
+	if err != nil || policyReloadInterval <= 0 {
+		log.Printf("Warning: Invalid POLICY_RELOAD_INTERVAL value, using 10s: %v", err)
+		policyReloadInterval = 10 * time.Second
+	}
+
+	deletedUserRetentionStr := getEnv("DELETED_USER_RETENTION", "720h")
+	deletedUserRetention, err := time.ParseDuration(deletedUserRetentionStr)
+	if err != nil || deletedUserRetention < 0 {
+		log.Printf("Warning: Invalid DELETED_USER_RETENTION value, using 720h: %v", err)
+		deletedUserRetention = 720 * time.Hour
+	}
+	deletedUserPurgeIntervalStr := getEnv("DELETED_USER_PURGE_INTERVAL", "1h")
+	deletedUserPurgeInterval, err := time.ParseDuration(deletedUserPurgeIntervalStr)
+	if err != nil || deletedUserPurgeInterval <= 0 {
+		log.Printf("Warning: Invalid DELETED_USER_PURGE_INTERVAL value, using 1h: %v", err)
+		deletedUserPurgeInterval = time.Hour
+	}
+	userErasureGracePeriodStr := getEnv("USER_ERASURE_GRACE_PERIOD", "168h")
+	userErasureGracePeriod, err := time.ParseDuration(userErasureGracePeriodStr)
+	if err != nil || userErasureGracePeriod < 0 {
+		log.Printf("Warning: Invalid USER_ERASURE_GRACE_PERIOD value, using 168h: %v", err)
+		userErasureGracePeriod = 168 * time.Hour
+	}
+	userErasureIntervalStr := getEnv("USER_ERASURE_INTERVAL", "1h")
+	userErasureInterval, err := time.ParseDuration(userErasureIntervalStr)
+	if err != nil || userErasureInterval <= 0 {
+		log.Printf("Warning: Invalid USER_ERASURE_INTERVAL value, using 1h: %v", err)
+		userErasureInterval = time.Hour
+	}
+
 	// redisEnabledStr := getEnv("REDIS_ENABLED", "false")
 	// redisEnabled, err := strconv.ParseBool(redisEnabledStr)
 	// if err != nil {
@@ -122,10 +561,72 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		AppPort: appPort,
+		AppPort:     appPort,
+		FrontendURL: frontendURL,
 		DatabaseURL: fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable",
 			dbUser, dbPassword, dbHost, dbPort, dbName),
 		JWTSecret: jwtSecret,
+		JWT: JWTConfig{
+			AccessTokenTTL:      accessTokenTTL,
+			RefreshTokenTTL:     refreshTokenTTL,
+			SessionCacheTTL:     sessionCacheTTL,
+			PermissionCacheTTL:  permissionCacheTTL,
+			AuthVersionCacheTTL: authVersionCacheTTL,
+			Issuer:              jwtIssuer,
+			Audience:            jwtAudience,
+			Leeway:              jwtLeeway,
+			SigningKeyFile:      signingKeyFile,
+			RetiredKeyFiles:     retiredKeyFiles,
+		},
+		MFA: MFAConfig{
+			Issuer:           mfaIssuer,
+			EncryptionKey:    mfaEncryptionKey,
+			PendingTokenTTL:  mfaPendingTokenTTL,
+			RequiredForAdmin: mfaRequiredForAdmin,
+		},
+		Login: LoginProtectionConfig{
+			FreeAttempts:            loginFreeAttempts,
+			AccountLockoutThreshold: loginAccountLockoutThreshold,
+			IPLockoutThreshold:      loginIPLockoutThreshold,
+			BaseDelay:               loginBackoffBase,
+			MaxDelay:                loginBackoffMax,
+			LockoutDuration:         loginLockoutDuration,
+			FailureWindow:           loginFailureWindow,
+		},
+		Password: PasswordConfig{
+			HashAlgorithm:     passwordHashAlgorithm,
+			BcryptCost:        bcryptCost,
+			Argon2Memory:      uint32(argon2Memory),
+			Argon2Iterations:  uint32(argon2Iterations),
+			Argon2Parallelism: uint8(argon2Parallelism),
+			MinLength:         passwordMinLength,
+			MaxLength:         passwordMaxLength,
+			RequireUppercase:  passwordRequireUppercase,
+			RequireLowercase:  passwordRequireLowercase,
+			RequireDigit:      passwordRequireDigit,
+			RequireSymbol:     passwordRequireSymbol,
+			DisallowUserInfo:  passwordDisallowUserInfo,
+			BreachedFile:      passwordBreachedFile,
+		},
+		Email: EmailVerificationConfig{
+			TokenTTL:            emailVerificationTTL,
+			UnverifiedLoginMode: unverifiedLoginMode,
+		},
+		Links: LinkConfig{
+			PasswordResetTTL: passwordResetTTL,
+			MagicLinkTTL:     magicLinkTTL,
+		},
+		Cookie: CookieConfig{
+			Secure:        cookieSecure,
+			Domain:        cookieDomain,
+			SameSite:      cookieSameSite,
+			AuthEnabled:   cookieAuthEnabled,
+			RememberMeTTL: rememberMeTTL,
+		},
+		OIDC: OIDCConfig{
+			Providers:      oidcProviders,
+			AuthRequestTTL: oidcAuthRequestTTL,
+		},
 		SMTP: SMTPConfig{
 			Host:        smtpHost,
 			Port:        smtpPort,
@@ -139,6 +640,16 @@ func LoadConfig() *Config {
 				Pass: basicAuthPass,
 			},
 		},
+		Policy: PolicyConfig{
+			File:           policyFile,
+			ReloadInterval: policyReloadInterval,
+		},
+		Users: UsersConfig{
+			DeletedRetention:   deletedUserRetention,
+			PurgeInterval:      deletedUserPurgeInterval,
+			ErasureGracePeriod: userErasureGracePeriod,
+			ErasureInterval:    userErasureInterval,
+		},
 		// RedisCfg: RedisConfig{
 		// 	Enabled:  redisEnabled,
 		// 	Addr:     redisAddr,
@@ -155,6 +666,47 @@ func LoadConfig() *Config {
 	}
 }
 
+// loadOIDCProviders reads the settings of the named providers. A provider without an
+// issuer or client ID is skipped.
+func loadOIDCProviders(names []string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range names {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+			DefaultRole:  getEnv(prefix+"DEFAULT_ROLE", "user"),
+		}
+		autoProvision, err := strconv.ParseBool(getEnv(prefix+"AUTO_PROVISION", "false"))
+		if err != nil {
+			log.Printf("Warning: Invalid %sAUTO_PROVISION value, using false: %v", prefix, err)
+			autoProvision = false
+		}
+		provider.AutoProvision = autoProvision
+
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			log.Printf("Warning: OIDC provider %q has no %sISSUER or %sCLIENT_ID, skipping it", name, prefix, prefix)
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
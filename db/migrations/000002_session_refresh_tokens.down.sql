@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS sessions_user_id_idx;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS previous_refresh_token_hash,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS refresh_expires_at,
+    DROP COLUMN IF EXISTS refresh_token_hash;
@@ -0,0 +1,10 @@
+ALTER TABLE sessions
+    ADD COLUMN refresh_token_hash VARCHAR(255) NULL,
+    ADD COLUMN refresh_expires_at TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- The hash of the refresh token a session had before its last rotation. Presenting
+    -- it again means the token was copied, which revokes the session; any other unknown
+    -- token is merely rejected.
+    ADD COLUMN previous_refresh_token_hash VARCHAR(255) NULL;
+
+CREATE INDEX ON sessions (user_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS mfa_pending_redemptions;
+
+DROP TABLE IF EXISTS mfa_recovery_codes;
+
+DROP TABLE IF EXISTS user_mfa;
@@ -0,0 +1,32 @@
+CREATE TABLE user_mfa (
+    user_id INT PRIMARY KEY NOT NULL,
+    totp_secret VARCHAR(255) NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    code_hash VARCHAR(255) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX ON mfa_recovery_codes (user_id);
+
+-- Second-factor steps of logins that were completed. An mfa_pending token is only
+-- accepted once; rows are kept until the token would have expired.
+CREATE TABLE mfa_pending_redemptions (
+    jti VARCHAR(255) PRIMARY KEY,
+    user_id INT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    redeemed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX ON mfa_pending_redemptions (expires_at);
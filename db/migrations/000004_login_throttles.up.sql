@@ -0,0 +1,8 @@
+CREATE TABLE login_throttles (
+    scope VARCHAR(16) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP WITH TIME ZONE NULL,
+    PRIMARY KEY (scope, subject)
+);
@@ -0,0 +1,6 @@
+DELETE FROM password_reset_tokens;
+
+ALTER TABLE password_reset_tokens DROP COLUMN expires_at;
+ALTER TABLE password_reset_tokens ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE password_reset_tokens ALTER COLUMN created_at DROP DEFAULT;
+ALTER TABLE password_reset_tokens RENAME COLUMN token_hash TO token;
@@ -0,0 +1,7 @@
+-- Existing tokens were stored in plaintext and cannot be converted.
+DELETE FROM password_reset_tokens;
+
+ALTER TABLE password_reset_tokens RENAME COLUMN token TO token_hash;
+ALTER TABLE password_reset_tokens ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE password_reset_tokens ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE password_reset_tokens ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DELETE FROM roles r
+WHERE r.name = 'editor' AND NOT EXISTS (SELECT 1 FROM users u WHERE u.role_id = r.id);
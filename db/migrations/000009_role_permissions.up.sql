@@ -0,0 +1,7 @@
+INSERT INTO permissions (name, description) VALUES
+('roles:read', 'View roles'),
+('roles:write', 'Create, update and delete roles');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('roles:read', 'roles:write')
+WHERE r.name = 'admin';
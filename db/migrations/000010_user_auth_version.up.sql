@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN auth_version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_roles;
+ALTER TABLE roles DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,23 @@
+ALTER TABLE roles ADD COLUMN parent_id INT NULL;
+ALTER TABLE roles ADD FOREIGN KEY (parent_id) REFERENCES roles(id) ON DELETE SET NULL;
+
+-- A role inherits everything granted to its parent: admin > editor > user.
+UPDATE roles SET parent_id = (SELECT id FROM roles WHERE name = 'user')
+WHERE name = 'editor';
+UPDATE roles SET parent_id = (SELECT id FROM roles WHERE name = 'editor')
+WHERE name = 'admin';
+
+CREATE TABLE user_roles (
+    user_id INT NOT NULL,
+    role_id INT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX ON user_roles (role_id);
+
+-- users.role_id stays as the primary role and is always present in user_roles too.
+INSERT INTO user_roles (user_id, role_id)
+SELECT id, role_id FROM users;
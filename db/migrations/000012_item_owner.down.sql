@@ -0,0 +1,11 @@
+UPDATE roles SET parent_id = (SELECT id FROM roles WHERE name = 'user')
+WHERE name = 'editor';
+
+DELETE FROM roles r
+WHERE r.name = 'author'
+  AND NOT EXISTS (SELECT 1 FROM users u WHERE u.role_id = r.id)
+  AND NOT EXISTS (SELECT 1 FROM user_roles ur WHERE ur.role_id = r.id);
+
+DELETE FROM permissions WHERE name = 'items:write_own';
+
+ALTER TABLE items DROP COLUMN IF EXISTS owner_id;
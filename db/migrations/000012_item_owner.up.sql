@@ -0,0 +1,20 @@
+ALTER TABLE items ADD COLUMN owner_id INT NULL;
+ALTER TABLE items ADD FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX ON items (owner_id);
+
+INSERT INTO permissions (name, description) VALUES
+('items:write_own', 'Create items and update or delete the items you own');
+
+-- Authors sit between user and editor, so editors and admins can do everything an
+-- author can: admin > editor > author > user.
+INSERT INTO roles (name, description, parent_id)
+SELECT 'author', 'Writes items and manages the items they own', id FROM roles WHERE name = 'user'
+ON CONFLICT (name) DO NOTHING;
+
+UPDATE roles SET parent_id = (SELECT id FROM roles WHERE name = 'author')
+WHERE name = 'editor';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'items:write_own'
+WHERE r.name = 'author';
@@ -0,0 +1,6 @@
+INSERT INTO permissions (name, description) VALUES
+('policies:read', 'Inspect access policies and explain their decisions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'policies:read'
+WHERE r.name = 'admin';
@@ -0,0 +1,8 @@
+-- Fails if a soft-deleted user shares a username or email with another user; purge or
+-- rename those users first.
+DROP INDEX IF EXISTS users_deleted_at_idx;
+DROP INDEX IF EXISTS users_email_active_key;
+DROP INDEX IF EXISTS users_username_active_key;
+
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,9 @@
+-- Usernames and emails only have to be unique among users that are not soft-deleted,
+-- so a deleted account does not block someone from registering the same name.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+
+CREATE UNIQUE INDEX users_username_active_key ON users (username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX users_email_active_key ON users (email) WHERE deleted_at IS NULL;
+
+CREATE INDEX ON users (deleted_at) WHERE deleted_at IS NOT NULL;
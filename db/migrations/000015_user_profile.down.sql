@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS email_change_tokens;
+
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;
+ALTER TABLE users DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,17 @@
+ALTER TABLE users ADD COLUMN display_name VARCHAR(100) NULL;
+ALTER TABLE users ADD COLUMN avatar_url VARCHAR(2048) NULL;
+
+-- A pending change of a user's email address. The address is only updated once the
+-- link sent to new_email has been followed.
+CREATE TABLE email_change_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(255) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX ON email_change_tokens (user_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_erasure_scheduled_at_idx;
+
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
+ALTER TABLE users DROP COLUMN IF EXISTS erasure_scheduled_at;
@@ -0,0 +1,7 @@
+-- Users can ask for their account to be erased. The request takes effect once
+-- erasure_scheduled_at has passed, after which personal data is anonymized and
+-- erased_at is set.
+ALTER TABLE users ADD COLUMN erasure_scheduled_at TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMP WITH TIME ZONE NULL;
+
+CREATE INDEX ON users (erasure_scheduled_at) WHERE erasure_scheduled_at IS NOT NULL;
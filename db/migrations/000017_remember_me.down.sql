@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS remember_tokens;
+
+ALTER TABLE users ADD COLUMN remember_token_uuid VARCHAR(255) NULL;
@@ -0,0 +1,20 @@
+-- Remember-me tokens, one per remembered device. selector is the first half of the
+-- token and looks it up; validator_hash is the SHA-256 of the second half. session_id
+-- is the session the token last started, so logging that session out forgets only its
+-- device. It is not a foreign key: the token outlives the session it started.
+CREATE TABLE remember_tokens (
+    selector VARCHAR(255) PRIMARY KEY,
+    user_id INT NOT NULL,
+    validator_hash VARCHAR(255) NOT NULL,
+    session_id VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX ON remember_tokens (user_id);
+CREATE INDEX ON remember_tokens (session_id);
+CREATE INDEX ON remember_tokens (expires_at);
+
+-- The column on users was never written; remembered devices live in remember_tokens.
+ALTER TABLE users DROP COLUMN remember_token_uuid;
@@ -0,0 +1,28 @@
+-- Accounts at external OpenID Connect providers linked to local users. subject is the
+-- provider's "sub" claim, which is stable for a user, unlike their email address.
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    provider VARCHAR(100) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP WITH TIME ZONE NULL,
+    UNIQUE (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX ON user_identities (user_id);
+
+-- An OpenID Connect login that was sent to the provider and has not come back yet.
+-- It is looked up by the hash of the state parameter and deleted when redeemed.
+CREATE TABLE oidc_auth_requests (
+    state_hash VARCHAR(255) PRIMARY KEY,
+    provider VARCHAR(100) NOT NULL,
+    code_verifier VARCHAR(255) NOT NULL,
+    nonce VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX ON oidc_auth_requests (expires_at);
@@ -0,0 +1,21 @@
+-- Email Change Tokens Queries
+-- name: CreateEmailChangeToken :one
+INSERT INTO email_change_tokens (
+    user_id,
+    new_email,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- Marks a token as used in the same statement that checks it, so it can only be redeemed once.
+-- name: ConsumeEmailChangeToken :one
+UPDATE email_change_tokens
+SET used_at = NOW()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+RETURNING *;
+
+-- name: DeleteEmailChangeTokensByUserID :exec
+DELETE FROM email_change_tokens
+WHERE user_id = $1;
@@ -0,0 +1,21 @@
+-- Email Verification Tokens Queries
+-- name: CreateEmailVerificationToken :one
+INSERT INTO email_verification_tokens (
+    user_id,
+    email,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- Marks a token as used in the same statement that checks it, so it can only be redeemed once.
+-- name: ConsumeEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = NOW()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+RETURNING *;
+
+-- name: DeleteEmailVerificationTokensByUserID :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1;
@@ -2,9 +2,10 @@
 -- name: CreateItem :one
 INSERT INTO items (
     name,
-    description
+    description,
+    owner_id
 ) VALUES (
-    $1, $2
+    $1, $2, $3
 ) RETURNING *;
 
 -- name: GetItemByID :one
@@ -32,3 +33,12 @@ LIMIT $2 OFFSET $1;
 -- name: CountItems :one
 SELECT COUNT(*) FROM items;
 
+-- name: ListItemsByOwnerID :many
+SELECT * FROM items
+WHERE owner_id = $1
+ORDER BY id;
+
+-- name: DeleteItemsByOwnerID :many
+DELETE FROM items
+WHERE owner_id = $1
+RETURNING id;
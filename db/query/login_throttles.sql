@@ -0,0 +1,32 @@
+-- Login Throttle Queries
+-- name: GetLoginThrottle :one
+SELECT * FROM login_throttles
+WHERE scope = $1 AND subject = $2 LIMIT 1;
+
+-- Failures older than window_start no longer count, so the counter restarts at 1.
+-- name: RecordLoginFailure :one
+INSERT INTO login_throttles (
+    scope,
+    subject,
+    failed_count,
+    last_failed_at
+) VALUES (
+    sqlc.arg(scope), sqlc.arg(subject), 1, NOW()
+)
+ON CONFLICT (scope, subject) DO UPDATE
+SET
+    failed_count = CASE
+        WHEN login_throttles.last_failed_at < sqlc.arg(window_start) THEN 1
+        ELSE login_throttles.failed_count + 1
+    END,
+    last_failed_at = NOW()
+RETURNING *;
+
+-- name: LockLoginThrottle :exec
+UPDATE login_throttles
+SET locked_until = $3
+WHERE scope = $1 AND subject = $2;
+
+-- name: ClearLoginThrottle :exec
+DELETE FROM login_throttles
+WHERE scope = $1 AND subject = $2;
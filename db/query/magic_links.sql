@@ -0,0 +1,14 @@
+-- Magic Link Redemptions Queries
+-- Records a magic link as used. The primary key on jti makes a second redemption insert nothing.
+-- name: RedeemMagicLink :execrows
+INSERT INTO magic_link_redemptions (
+    jti,
+    user_id,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (jti) DO NOTHING;
+
+-- name: DeleteExpiredMagicLinkRedemptions :exec
+DELETE FROM magic_link_redemptions
+WHERE expires_at < NOW();
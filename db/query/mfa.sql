@@ -0,0 +1,74 @@
+-- User MFA Queries
+-- name: UpsertUserMFA :one
+INSERT INTO user_mfa (
+    user_id,
+    totp_secret
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    totp_secret = EXCLUDED.totp_secret,
+    enabled_at = NULL,
+    last_used_step = 0,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetUserMFA :one
+SELECT * FROM user_mfa
+WHERE user_id = $1 LIMIT 1;
+
+-- name: EnableUserMFA :one
+UPDATE user_mfa
+SET
+    enabled_at = NOW(),
+    updated_at = NOW()
+WHERE user_id = $1
+RETURNING *;
+
+-- name: AdvanceUserMFAStep :execrows
+UPDATE user_mfa
+SET
+    last_used_step = $2,
+    updated_at = NOW()
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa
+WHERE user_id = $1;
+
+
+-- MFA Recovery Codes Queries
+-- name: CreateMFARecoveryCode :exec
+INSERT INTO mfa_recovery_codes (
+    user_id,
+    code_hash
+) VALUES (
+    $1, $2
+);
+
+-- name: UseMFARecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET
+    used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: DeleteMFARecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
+
+
+-- MFA Pending Redemptions Queries
+-- Records an mfa_pending token as used. The primary key on jti makes a second redemption insert nothing.
+-- name: RedeemMFAPendingToken :execrows
+INSERT INTO mfa_pending_redemptions (
+    jti,
+    user_id,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (jti) DO NOTHING;
+
+-- name: DeleteExpiredMFAPendingRedemptions :exec
+DELETE FROM mfa_pending_redemptions
+WHERE expires_at < NOW();
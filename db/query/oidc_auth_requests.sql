@@ -0,0 +1,21 @@
+-- OIDC Auth Requests Queries
+-- name: CreateOIDCAuthRequest :exec
+INSERT INTO oidc_auth_requests (
+    state_hash,
+    provider,
+    code_verifier,
+    nonce,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- Deletes the request in the same statement that reads it, so a state can only be redeemed once.
+-- name: ConsumeOIDCAuthRequest :one
+DELETE FROM oidc_auth_requests
+WHERE state_hash = $1 AND expires_at > NOW()
+RETURNING *;
+
+-- name: DeleteExpiredOIDCAuthRequests :exec
+DELETE FROM oidc_auth_requests
+WHERE expires_at < NOW();
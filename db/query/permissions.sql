@@ -0,0 +1,7 @@
+-- Permissions Queries
+-- name: ListPermissionNamesByRoleName :many
+SELECT p.name FROM permissions p
+JOIN role_permissions rp ON rp.permission_id = p.id
+JOIN roles r ON r.id = rp.role_id
+WHERE r.name = $1
+ORDER BY p.name;
@@ -0,0 +1,47 @@
+-- Remember Tokens Queries
+-- name: CreateRememberToken :exec
+INSERT INTO remember_tokens (
+    selector,
+    user_id,
+    validator_hash,
+    session_id,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: GetRememberToken :one
+SELECT * FROM remember_tokens
+WHERE selector = $1 LIMIT 1;
+
+-- Replaces a remember-me token only while it is still the one that was presented and
+-- has not expired, so concurrent requests cannot both redeem it.
+-- name: RotateRememberToken :execrows
+UPDATE remember_tokens
+SET
+    selector = sqlc.arg(new_selector),
+    validator_hash = sqlc.arg(new_validator_hash),
+    session_id = sqlc.arg(session_id),
+    expires_at = sqlc.arg(expires_at)
+WHERE selector = sqlc.arg(old_selector) AND expires_at > NOW();
+
+-- name: DeleteRememberToken :exec
+DELETE FROM remember_tokens
+WHERE selector = $1;
+
+-- name: DeleteRememberTokensBySessionID :exec
+DELETE FROM remember_tokens
+WHERE session_id = $1;
+
+-- name: DeleteRememberTokensByUserID :exec
+DELETE FROM remember_tokens
+WHERE user_id = $1;
+
+-- Forgets every device of a user except the one remembered by the given session.
+-- name: DeleteOtherRememberTokensByUserID :exec
+DELETE FROM remember_tokens
+WHERE user_id = $1 AND session_id <> $2;
+
+-- name: DeleteExpiredRememberTokens :exec
+DELETE FROM remember_tokens
+WHERE expires_at < NOW();
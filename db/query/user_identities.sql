@@ -0,0 +1,27 @@
+-- User Identities Queries
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+    user_id,
+    provider,
+    subject,
+    email,
+    last_login_at
+) VALUES (
+    $1, $2, $3, $4, NOW()
+) RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE provider = $1 AND subject = $2 LIMIT 1;
+
+-- Records a login through the identity and the email address the provider reported.
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET
+    email = $2,
+    last_login_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteUserIdentitiesByUserID :exec
+DELETE FROM user_identities
+WHERE user_id = $1;
@@ -0,0 +1,28 @@
+-- User Roles Queries
+-- The primary role is included even if its user_roles row is missing.
+-- name: ListUserRoleNames :many
+SELECT r.name FROM roles r
+WHERE r.id IN (
+    SELECT ur.role_id FROM user_roles ur WHERE ur.user_id = $1
+    UNION
+    SELECT u.role_id FROM users u WHERE u.id = $1
+)
+ORDER BY r.name;
+
+-- name: AddUserRole :exec
+INSERT INTO user_roles (
+    user_id,
+    role_id
+) VALUES (
+    $1, $2
+) ON CONFLICT (user_id, role_id) DO NOTHING;
+
+-- name: RemoveUserRole :execrows
+DELETE FROM user_roles
+WHERE user_id = $1 AND role_id = $2;
+
+-- Returns every role with the name of the role it inherits from, if any.
+-- name: ListRoleParents :many
+SELECT r.name, p.name AS parent_name FROM roles r
+LEFT JOIN roles p ON p.id = r.parent_id
+ORDER BY r.name;
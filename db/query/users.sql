@@ -11,14 +11,20 @@ INSERT INTO users (
 
 -- name: GetUserByUsername :one
 SELECT * FROM users
-WHERE username = $1 LIMIT 1;
+WHERE username = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
-WHERE email = $1 LIMIT 1;
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1;
 
+-- Like the other lookups, leaves out soft-deleted users. Admin tools that need them
+-- use GetUserByIDIncludingDeleted.
 -- name: GetUserByID :one
 SELECT * FROM users
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetUserByIDIncludingDeleted :one
+SELECT * FROM users
 WHERE id = $1 LIMIT 1;
 
 -- name: UpdateUser :one
@@ -29,9 +35,8 @@ SET
     email = $4,
     email_verified_at = $5,
     role_id = $6,
-    remember_token_uuid = $7,
     updated_at = NOW(),
-    deleted_at = $8
+    deleted_at = $7
 WHERE id = $1
 RETURNING *;
 
@@ -43,12 +48,15 @@ SET
 WHERE id = $1
 RETURNING *;
 
+-- Bumps auth_version as well, so access tokens issued before the deletion stop being accepted.
+-- Users that are already deleted keep their original deletion time.
 -- name: SoftDeleteUser :one
 UPDATE users
 SET
     deleted_at = NOW(),
+    auth_version = auth_version + 1,
     updated_at = NOW()
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: RestoreUser :one
@@ -56,7 +64,7 @@ UPDATE users
 SET
     deleted_at = NULL,
     updated_at = NOW()
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NOT NULL AND erased_at IS NULL
 RETURNING *;
 
 -- name: VerifyUserEmail :one
@@ -67,26 +75,180 @@ SET
 WHERE id = $1
 RETURNING *;
 
+-- Only touches the fields users may edit on their own profile.
+-- name: UpdateUserProfile :one
+UPDATE users
+SET
+    username = $2,
+    display_name = $3,
+    avatar_url = $4,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET
+    hashed_password = $2,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- The new address counts as verified, since it is only set once a link sent to it was followed.
+-- name: UpdateUserEmail :one
+UPDATE users
+SET
+    email = $2,
+    email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ScheduleUserErasure :one
+UPDATE users
+SET
+    erasure_scheduled_at = $2,
+    updated_at = NOW()
+WHERE id = $1 AND erased_at IS NULL
+RETURNING *;
+
+-- name: CancelUserErasure :one
+UPDATE users
+SET
+    erasure_scheduled_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND erasure_scheduled_at IS NOT NULL AND erased_at IS NULL
+RETURNING *;
+
+-- Users whose erasure grace period is over, oldest request first.
+-- name: ListUsersDueForErasure :many
+SELECT id FROM users
+WHERE erasure_scheduled_at <= NOW() AND erased_at IS NULL
+ORDER BY erasure_scheduled_at
+LIMIT $1;
+
+-- Replaces the user's personal data with placeholders and soft-deletes the account.
+-- The row is kept so references to it stay valid. The empty password hash matches no
+-- password, and the bumped auth_version invalidates outstanding access tokens.
+-- name: AnonymizeUser :one
+UPDATE users
+SET
+    username = 'erased-' || id,
+    email = 'erased-' || id || '@erased.invalid',
+    hashed_password = '',
+    email_verified_at = NULL,
+    display_name = NULL,
+    avatar_url = NULL,
+    erasure_scheduled_at = NULL,
+    erased_at = NOW(),
+    deleted_at = COALESCE(deleted_at, NOW()),
+    auth_version = auth_version + 1,
+    updated_at = NOW()
+WHERE id = $1 AND erased_at IS NULL
+RETURNING *;
+
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
 
+-- Soft-deleted users have no auth version, so none of their tokens are accepted.
+-- name: GetUserAuthVersion :one
+SELECT auth_version FROM users
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: BumpUserAuthVersion :one
+UPDATE users
+SET
+    auth_version = auth_version + 1,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING auth_version;
+
 -- name: ListUsers :many
 SELECT * FROM users
+WHERE deleted_at IS NULL
 ORDER BY id
 LIMIT $2 OFFSET $1;
 
 -- name: CountUsers :one
-SELECT COUNT(*) FROM users;
+SELECT COUNT(*) FROM users
+WHERE deleted_at IS NULL;
+
+-- Permanently deletes users that were soft-deleted before the cutoff, together with
+-- reset tokens sent to their email unless an active user has the same address. Users
+-- with a pending erasure are left to the erasure, which also removes their items.
+-- name: PurgeDeletedUsers :many
+WITH purged AS (
+    DELETE FROM users
+    WHERE deleted_at IS NOT NULL AND deleted_at < sqlc.arg(cutoff)::timestamptz
+      AND erasure_scheduled_at IS NULL
+    RETURNING id, email
+), purged_tokens AS (
+    DELETE FROM password_reset_tokens t
+    USING purged p
+    WHERE t.email = p.email
+      AND NOT EXISTS (SELECT 1 FROM users u WHERE u.email = t.email AND u.deleted_at IS NULL)
+)
+SELECT id FROM purged;
+
+-- Lists users for the admin area. Each filter is skipped when NULL: role matches the
+-- primary role or a role from user_roles, search matches part of the username or email
+-- regardless of case. sort_by is one of id, username, email or created_at; ties are
+-- broken by id.
+-- name: SearchUsers :many
+SELECT u.* FROM users u
+WHERE (sqlc.narg(role)::text IS NULL OR EXISTS (
+        SELECT 1 FROM roles r
+        WHERE r.name = sqlc.narg(role)::text AND (
+            r.id = u.role_id
+            OR EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id AND ur.role_id = r.id)
+        )
+    ))
+    AND (sqlc.narg(verified)::boolean IS NULL OR (u.email_verified_at IS NOT NULL) = sqlc.narg(verified)::boolean)
+    AND (sqlc.narg(deleted)::boolean IS NULL OR (u.deleted_at IS NOT NULL) = sqlc.narg(deleted)::boolean)
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR u.created_at >= sqlc.narg(created_from)::timestamptz)
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR u.created_at < sqlc.narg(created_to)::timestamptz)
+    AND (sqlc.narg(search)::text IS NULL
+        OR strpos(lower(u.username), lower(sqlc.narg(search)::text)) > 0
+        OR strpos(lower(u.email), lower(sqlc.narg(search)::text)) > 0)
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'username' AND NOT sqlc.arg(sort_desc)::boolean THEN u.username END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'username' AND sqlc.arg(sort_desc)::boolean THEN u.username END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'email' AND NOT sqlc.arg(sort_desc)::boolean THEN u.email END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'email' AND sqlc.arg(sort_desc)::boolean THEN u.email END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND NOT sqlc.arg(sort_desc)::boolean THEN u.created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND sqlc.arg(sort_desc)::boolean THEN u.created_at END DESC,
+    CASE WHEN sqlc.arg(sort_desc)::boolean THEN u.id END DESC,
+    u.id ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- Counts the users SearchUsers would list without paging.
+-- name: CountSearchUsers :one
+SELECT COUNT(*) FROM users u
+WHERE (sqlc.narg(role)::text IS NULL OR EXISTS (
+        SELECT 1 FROM roles r
+        WHERE r.name = sqlc.narg(role)::text AND (
+            r.id = u.role_id
+            OR EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id AND ur.role_id = r.id)
+        )
+    ))
+    AND (sqlc.narg(verified)::boolean IS NULL OR (u.email_verified_at IS NOT NULL) = sqlc.narg(verified)::boolean)
+    AND (sqlc.narg(deleted)::boolean IS NULL OR (u.deleted_at IS NOT NULL) = sqlc.narg(deleted)::boolean)
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR u.created_at >= sqlc.narg(created_from)::timestamptz)
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR u.created_at < sqlc.narg(created_to)::timestamptz)
+    AND (sqlc.narg(search)::text IS NULL
+        OR strpos(lower(u.username), lower(sqlc.narg(search)::text)) > 0
+        OR strpos(lower(u.email), lower(sqlc.narg(search)::text)) > 0);
 
 
 -- Roles Queries
 -- name: CreateRole :one
 INSERT INTO roles (
     name,
-    description
+    description,
+    parent_id
 ) VALUES (
-    $1, $2
+    $1, $2, $3
 ) RETURNING *;
 
 -- name: GetRoleByID :one
@@ -102,6 +264,7 @@ UPDATE roles
 SET
     name = $2,
     description = $3,
+    parent_id = $4,
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -123,16 +286,28 @@ SELECT COUNT(*) FROM roles;
 -- name: CreatePasswordResetToken :one
 INSERT INTO password_reset_tokens (
     email,
-    token,
-    created_at
+    token_hash,
+    expires_at
 ) VALUES (
     $1, $2, $3
-) RETURNING *;
+)
+ON CONFLICT (email) DO UPDATE
+SET
+    token_hash = EXCLUDED.token_hash,
+    expires_at = EXCLUDED.expires_at,
+    created_at = NOW()
+RETURNING *;
 
 -- name: GetPasswordResetToken :one
 SELECT * FROM password_reset_tokens
 WHERE email = $1 LIMIT 1;
 
+-- Deletes the token only if it matches and has not expired, so it can be redeemed once.
+-- name: ConsumePasswordResetToken :one
+DELETE FROM password_reset_tokens
+WHERE email = $1 AND token_hash = $2 AND expires_at > NOW()
+RETURNING *;
+
 -- name: DeletePasswordResetToken :exec
 DELETE FROM password_reset_tokens
 WHERE email = $1;
@@ -146,9 +321,11 @@ INSERT INTO sessions (
     ip_address,
     user_agent,
     payload,
-    last_activity
+    last_activity,
+    refresh_token_hash,
+    refresh_expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetSessionByID :one
@@ -166,10 +343,42 @@ SET
 WHERE id = $1
 RETURNING *;
 
+-- name: RotateSessionRefreshToken :one
+UPDATE sessions
+SET
+    previous_refresh_token_hash = refresh_token_hash,
+    refresh_token_hash = sqlc.arg(new_refresh_token_hash),
+    refresh_expires_at = sqlc.arg(refresh_expires_at),
+    ip_address = sqlc.arg(ip_address),
+    user_agent = sqlc.arg(user_agent),
+    last_activity = sqlc.arg(last_activity)
+WHERE id = sqlc.arg(id) AND refresh_token_hash = sqlc.arg(current_refresh_token_hash)
+RETURNING *;
+
 -- name: DeleteSession :exec
 DELETE FROM sessions
 WHERE id = $1;
 
+-- name: DeleteSessionsByUserID :exec
+DELETE FROM sessions
+WHERE user_id = $1;
+
+-- Signs a user out of every session except the one given.
+-- name: DeleteOtherSessionsByUserID :exec
+DELETE FROM sessions
+WHERE user_id = $1 AND id <> $2;
+
+-- Deletes a session only if it belongs to the given user, so one user cannot revoke another's sessions.
+-- name: DeleteUserSession :execrows
+DELETE FROM sessions
+WHERE id = $1 AND user_id = $2;
+
+-- Sessions whose refresh token has expired can no longer be used and are left out.
+-- name: ListSessionsByUserID :many
+SELECT * FROM sessions
+WHERE user_id = $1 AND (refresh_expires_at IS NULL OR refresh_expires_at > NOW())
+ORDER BY last_activity DESC;
+
 -- name: DeleteExpiredSessions :exec
 DELETE FROM sessions
 WHERE last_activity < $1;
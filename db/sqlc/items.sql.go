@@ -24,20 +24,22 @@ func (q *Queries) CountItems(ctx context.Context) (int64, error) {
 const createItem = `-- name: CreateItem :one
 INSERT INTO items (
     name,
-    description
+    description,
+    owner_id
 ) VALUES (
-    $1, $2
-) RETURNING id, name, description, created_at, updated_at
+    $1, $2, $3
+) RETURNING id, name, description, created_at, updated_at, owner_id
 `
 
 type CreateItemParams struct {
 	Name        string         `json:"name"`
 	Description sql.NullString `json:"description"`
+	OwnerID     sql.NullInt32  `json:"owner_id"`
 }
 
 // Items Queries
 func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, error) {
-	row := q.db.QueryRowContext(ctx, createItem, arg.Name, arg.Description)
+	row := q.db.QueryRowContext(ctx, createItem, arg.Name, arg.Description, arg.OwnerID)
 	var i Item
 	err := row.Scan(
 		&i.ID,
@@ -45,6 +47,7 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, e
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OwnerID,
 	)
 	return i, err
 }
@@ -59,8 +62,37 @@ func (q *Queries) DeleteItem(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteItemsByOwnerID = `-- name: DeleteItemsByOwnerID :many
+DELETE FROM items
+WHERE owner_id = $1
+RETURNING id
+`
+
+func (q *Queries) DeleteItemsByOwnerID(ctx context.Context, ownerID sql.NullInt32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, deleteItemsByOwnerID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getItemByID = `-- name: GetItemByID :one
-SELECT id, name, description, created_at, updated_at FROM items
+SELECT id, name, description, created_at, updated_at, owner_id FROM items
 WHERE id = $1 LIMIT 1
 `
 
@@ -73,12 +105,13 @@ func (q *Queries) GetItemByID(ctx context.Context, id int32) (Item, error) {
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OwnerID,
 	)
 	return i, err
 }
 
 const listItems = `-- name: ListItems :many
-SELECT id, name, description, created_at, updated_at FROM items
+SELECT id, name, description, created_at, updated_at, owner_id FROM items
 ORDER BY id
 LIMIT $2 OFFSET $1
 `
@@ -103,6 +136,43 @@ func (q *Queries) ListItems(ctx context.Context, arg ListItemsParams) ([]Item, e
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OwnerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listItemsByOwnerID = `-- name: ListItemsByOwnerID :many
+SELECT id, name, description, created_at, updated_at, owner_id FROM items
+WHERE owner_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListItemsByOwnerID(ctx context.Context, ownerID sql.NullInt32) ([]Item, error) {
+	rows, err := q.db.QueryContext(ctx, listItemsByOwnerID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Item{}
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerID,
 		); err != nil {
 			return nil, err
 		}
@@ -124,7 +194,7 @@ SET
     description = $3,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, description, created_at, updated_at
+RETURNING id, name, description, created_at, updated_at, owner_id
 `
 
 type UpdateItemParams struct {
@@ -142,6 +212,7 @@ func (q *Queries) UpdateItem(ctx context.Context, arg UpdateItemParams) (Item, e
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OwnerID,
 	)
 	return i, err
 }
@@ -9,18 +9,95 @@ import (
 	"time"
 )
 
+type EmailChangeToken struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	NewEmail  string       `json:"new_email"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type EmailVerificationToken struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	Email     string       `json:"email"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
 type Item struct {
 	ID          int32          `json:"id"`
 	Name        string         `json:"name"`
 	Description sql.NullString `json:"description"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	OwnerID     sql.NullInt32  `json:"owner_id"`
+}
+
+type LoginThrottle struct {
+	Scope        string       `json:"scope"`
+	Subject      string       `json:"subject"`
+	FailedCount  int32        `json:"failed_count"`
+	LastFailedAt time.Time    `json:"last_failed_at"`
+	LockedUntil  sql.NullTime `json:"locked_until"`
+}
+
+type MagicLinkRedemption struct {
+	Jti        string    `json:"jti"`
+	UserID     int32     `json:"user_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
+
+type MfaPendingRedemption struct {
+	Jti        string    `json:"jti"`
+	UserID     int32     `json:"user_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
+
+type MfaRecoveryCode struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type OidcAuthRequest struct {
+	StateHash    string    `json:"state_hash"`
+	Provider     string    `json:"provider"`
+	CodeVerifier string    `json:"code_verifier"`
+	Nonce        string    `json:"nonce"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type PasswordResetToken struct {
-	Email     string       `json:"email"`
-	Token     string       `json:"token"`
-	CreatedAt sql.NullTime `json:"created_at"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"token_hash"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type Permission struct {
+	ID          int32          `json:"id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type RememberToken struct {
+	Selector      string    `json:"selector"`
+	UserID        int32     `json:"user_id"`
+	ValidatorHash string    `json:"validator_hash"`
+	SessionID     string    `json:"session_id"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type Role struct {
@@ -29,26 +106,65 @@ type Role struct {
 	Description sql.NullString `json:"description"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	ParentID    sql.NullInt32  `json:"parent_id"`
+}
+
+type RolePermission struct {
+	RoleID       int32 `json:"role_id"`
+	PermissionID int32 `json:"permission_id"`
 }
 
 type Session struct {
-	ID           string         `json:"id"`
-	UserID       sql.NullInt32  `json:"user_id"`
-	IpAddress    sql.NullString `json:"ip_address"`
-	UserAgent    sql.NullString `json:"user_agent"`
-	Payload      string         `json:"payload"`
-	LastActivity int32          `json:"last_activity"`
+	ID                       string         `json:"id"`
+	UserID                   sql.NullInt32  `json:"user_id"`
+	IpAddress                sql.NullString `json:"ip_address"`
+	UserAgent                sql.NullString `json:"user_agent"`
+	Payload                  string         `json:"payload"`
+	LastActivity             int32          `json:"last_activity"`
+	RefreshTokenHash         sql.NullString `json:"refresh_token_hash"`
+	RefreshExpiresAt         sql.NullTime   `json:"refresh_expires_at"`
+	CreatedAt                time.Time      `json:"created_at"`
+	PreviousRefreshTokenHash sql.NullString `json:"previous_refresh_token_hash"`
 }
 
 type User struct {
-	ID                int32          `json:"id"`
-	Username          string         `json:"username"`
-	HashedPassword    string         `json:"hashed_password"`
-	Email             string         `json:"email"`
-	EmailVerifiedAt   sql.NullTime   `json:"email_verified_at"`
-	RoleID            int32          `json:"role_id"`
-	RememberTokenUuid sql.NullString `json:"remember_token_uuid"`
-	CreatedAt         time.Time      `json:"created_at"`
-	UpdatedAt         time.Time      `json:"updated_at"`
-	DeletedAt         sql.NullTime   `json:"deleted_at"`
+	ID                 int32          `json:"id"`
+	Username           string         `json:"username"`
+	HashedPassword     string         `json:"hashed_password"`
+	Email              string         `json:"email"`
+	EmailVerifiedAt    sql.NullTime   `json:"email_verified_at"`
+	RoleID             int32          `json:"role_id"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          sql.NullTime   `json:"deleted_at"`
+	AuthVersion        int32          `json:"auth_version"`
+	DisplayName        sql.NullString `json:"display_name"`
+	AvatarUrl          sql.NullString `json:"avatar_url"`
+	ErasureScheduledAt sql.NullTime   `json:"erasure_scheduled_at"`
+	ErasedAt           sql.NullTime   `json:"erased_at"`
+}
+
+type UserIdentity struct {
+	ID          int32          `json:"id"`
+	UserID      int32          `json:"user_id"`
+	Provider    string         `json:"provider"`
+	Subject     string         `json:"subject"`
+	Email       sql.NullString `json:"email"`
+	CreatedAt   time.Time      `json:"created_at"`
+	LastLoginAt sql.NullTime   `json:"last_login_at"`
+}
+
+type UserRole struct {
+	UserID    int32     `json:"user_id"`
+	RoleID    int32     `json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserMfa struct {
+	UserID       int32        `json:"user_id"`
+	TotpSecret   string       `json:"totp_secret"`
+	EnabledAt    sql.NullTime `json:"enabled_at"`
+	LastUsedStep int64        `json:"last_used_step"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
 }
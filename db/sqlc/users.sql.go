@@ -8,8 +8,123 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const anonymizeUser = `-- name: AnonymizeUser :one
+UPDATE users
+SET
+    username = 'erased-' || id,
+    email = 'erased-' || id || '@erased.invalid',
+    hashed_password = '',
+    email_verified_at = NULL,
+    display_name = NULL,
+    avatar_url = NULL,
+    erasure_scheduled_at = NULL,
+    erased_at = NOW(),
+    deleted_at = COALESCE(deleted_at, NOW()),
+    auth_version = auth_version + 1,
+    updated_at = NOW()
+WHERE id = $1 AND erased_at IS NULL
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
+`
+
+// Replaces the user's personal data with placeholders and soft-deletes the account.
+// The row is kept so references to it stay valid. The empty password hash matches no
+// password, and the bumped auth_version invalidates outstanding access tokens.
+func (q *Queries) AnonymizeUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, anonymizeUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerifiedAt,
+		&i.RoleID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const bumpUserAuthVersion = `-- name: BumpUserAuthVersion :one
+UPDATE users
+SET
+    auth_version = auth_version + 1,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING auth_version
+`
+
+func (q *Queries) BumpUserAuthVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, bumpUserAuthVersion, id)
+	var auth_version int32
+	err := row.Scan(&auth_version)
+	return auth_version, err
+}
+
+const cancelUserErasure = `-- name: CancelUserErasure :one
+UPDATE users
+SET
+    erasure_scheduled_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND erasure_scheduled_at IS NOT NULL AND erased_at IS NULL
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
+`
+
+func (q *Queries) CancelUserErasure(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, cancelUserErasure, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerifiedAt,
+		&i.RoleID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const consumePasswordResetToken = `-- name: ConsumePasswordResetToken :one
+DELETE FROM password_reset_tokens
+WHERE email = $1 AND token_hash = $2 AND expires_at > NOW()
+RETURNING email, token_hash, created_at, expires_at
+`
+
+type ConsumePasswordResetTokenParams struct {
+	Email     string `json:"email"`
+	TokenHash string `json:"token_hash"`
+}
+
+// Deletes the token only if it matches and has not expired, so it can be redeemed once.
+func (q *Queries) ConsumePasswordResetToken(ctx context.Context, arg ConsumePasswordResetTokenParams) (PasswordResetToken, error) {
+	row := q.db.QueryRowContext(ctx, consumePasswordResetToken, arg.Email, arg.TokenHash)
+	var i PasswordResetToken
+	err := row.Scan(
+		&i.Email,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
 const countRoles = `-- name: CountRoles :one
 SELECT COUNT(*) FROM roles
 `
@@ -21,8 +136,51 @@ func (q *Queries) CountRoles(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countSearchUsers = `-- name: CountSearchUsers :one
+SELECT COUNT(*) FROM users u
+WHERE ($1::text IS NULL OR EXISTS (
+        SELECT 1 FROM roles r
+        WHERE r.name = $1::text AND (
+            r.id = u.role_id
+            OR EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id AND ur.role_id = r.id)
+        )
+    ))
+    AND ($2::boolean IS NULL OR (u.email_verified_at IS NOT NULL) = $2::boolean)
+    AND ($3::boolean IS NULL OR (u.deleted_at IS NOT NULL) = $3::boolean)
+    AND ($4::timestamptz IS NULL OR u.created_at >= $4::timestamptz)
+    AND ($5::timestamptz IS NULL OR u.created_at < $5::timestamptz)
+    AND ($6::text IS NULL
+        OR strpos(lower(u.username), lower($6::text)) > 0
+        OR strpos(lower(u.email), lower($6::text)) > 0)
+`
+
+type CountSearchUsersParams struct {
+	Role        sql.NullString `json:"role"`
+	Verified    sql.NullBool   `json:"verified"`
+	Deleted     sql.NullBool   `json:"deleted"`
+	CreatedFrom sql.NullTime   `json:"created_from"`
+	CreatedTo   sql.NullTime   `json:"created_to"`
+	Search      sql.NullString `json:"search"`
+}
+
+// Counts the users SearchUsers would list without paging.
+func (q *Queries) CountSearchUsers(ctx context.Context, arg CountSearchUsersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchUsers,
+		arg.Role,
+		arg.Verified,
+		arg.Deleted,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Search,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUsers = `-- name: CountUsers :one
 SELECT COUNT(*) FROM users
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
@@ -35,44 +193,57 @@ func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
 const createPasswordResetToken = `-- name: CreatePasswordResetToken :one
 INSERT INTO password_reset_tokens (
     email,
-    token,
-    created_at
+    token_hash,
+    expires_at
 ) VALUES (
     $1, $2, $3
-) RETURNING email, token, created_at
+)
+ON CONFLICT (email) DO UPDATE
+SET
+    token_hash = EXCLUDED.token_hash,
+    expires_at = EXCLUDED.expires_at,
+    created_at = NOW()
+RETURNING email, token_hash, created_at, expires_at
 `
 
 type CreatePasswordResetTokenParams struct {
-	Email     string       `json:"email"`
-	Token     string       `json:"token"`
-	CreatedAt sql.NullTime `json:"created_at"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // Password Reset Tokens Queries
 func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error) {
-	row := q.db.QueryRowContext(ctx, createPasswordResetToken, arg.Email, arg.Token, arg.CreatedAt)
+	row := q.db.QueryRowContext(ctx, createPasswordResetToken, arg.Email, arg.TokenHash, arg.ExpiresAt)
 	var i PasswordResetToken
-	err := row.Scan(&i.Email, &i.Token, &i.CreatedAt)
+	err := row.Scan(
+		&i.Email,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
 	return i, err
 }
 
 const createRole = `-- name: CreateRole :one
 INSERT INTO roles (
     name,
-    description
+    description,
+    parent_id
 ) VALUES (
-    $1, $2
-) RETURNING id, name, description, created_at, updated_at
+    $1, $2, $3
+) RETURNING id, name, description, created_at, updated_at, parent_id
 `
 
 type CreateRoleParams struct {
 	Name        string         `json:"name"`
 	Description sql.NullString `json:"description"`
+	ParentID    sql.NullInt32  `json:"parent_id"`
 }
 
 // Roles Queries
 func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error) {
-	row := q.db.QueryRowContext(ctx, createRole, arg.Name, arg.Description)
+	row := q.db.QueryRowContext(ctx, createRole, arg.Name, arg.Description, arg.ParentID)
 	var i Role
 	err := row.Scan(
 		&i.ID,
@@ -80,6 +251,7 @@ func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (Role, e
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentID,
 	)
 	return i, err
 }
@@ -91,19 +263,23 @@ INSERT INTO sessions (
     ip_address,
     user_agent,
     payload,
-    last_activity
+    last_activity,
+    refresh_token_hash,
+    refresh_expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
-) RETURNING id, user_id, ip_address, user_agent, payload, last_activity
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, user_id, ip_address, user_agent, payload, last_activity, refresh_token_hash, refresh_expires_at, created_at, previous_refresh_token_hash
 `
 
 type CreateSessionParams struct {
-	ID           string         `json:"id"`
-	UserID       sql.NullInt32  `json:"user_id"`
-	IpAddress    sql.NullString `json:"ip_address"`
-	UserAgent    sql.NullString `json:"user_agent"`
-	Payload      string         `json:"payload"`
-	LastActivity int32          `json:"last_activity"`
+	ID               string         `json:"id"`
+	UserID           sql.NullInt32  `json:"user_id"`
+	IpAddress        sql.NullString `json:"ip_address"`
+	UserAgent        sql.NullString `json:"user_agent"`
+	Payload          string         `json:"payload"`
+	LastActivity     int32          `json:"last_activity"`
+	RefreshTokenHash sql.NullString `json:"refresh_token_hash"`
+	RefreshExpiresAt sql.NullTime   `json:"refresh_expires_at"`
 }
 
 // Sessions Queries
@@ -115,6 +291,8 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		arg.UserAgent,
 		arg.Payload,
 		arg.LastActivity,
+		arg.RefreshTokenHash,
+		arg.RefreshExpiresAt,
 	)
 	var i Session
 	err := row.Scan(
@@ -124,6 +302,10 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.UserAgent,
 		&i.Payload,
 		&i.LastActivity,
+		&i.RefreshTokenHash,
+		&i.RefreshExpiresAt,
+		&i.CreatedAt,
+		&i.PreviousRefreshTokenHash,
 	)
 	return i, err
 }
@@ -136,7 +318,7 @@ INSERT INTO users (
     role_id
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at
+) RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
 `
 
 type CreateUserParams struct {
@@ -162,10 +344,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
 	)
 	return i, err
 }
@@ -180,6 +366,22 @@ func (q *Queries) DeleteExpiredSessions(ctx context.Context, lastActivity int32)
 	return err
 }
 
+const deleteOtherSessionsByUserID = `-- name: DeleteOtherSessionsByUserID :exec
+DELETE FROM sessions
+WHERE user_id = $1 AND id <> $2
+`
+
+type DeleteOtherSessionsByUserIDParams struct {
+	UserID sql.NullInt32 `json:"user_id"`
+	ID     string        `json:"id"`
+}
+
+// Signs a user out of every session except the one given.
+func (q *Queries) DeleteOtherSessionsByUserID(ctx context.Context, arg DeleteOtherSessionsByUserIDParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOtherSessionsByUserID, arg.UserID, arg.ID)
+	return err
+}
+
 const deletePasswordResetToken = `-- name: DeletePasswordResetToken :exec
 DELETE FROM password_reset_tokens
 WHERE email = $1
@@ -210,6 +412,16 @@ func (q *Queries) DeleteSession(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteSessionsByUserID = `-- name: DeleteSessionsByUserID :exec
+DELETE FROM sessions
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteSessionsByUserID(ctx context.Context, userID sql.NullInt32) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionsByUserID, userID)
+	return err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1
@@ -220,20 +432,44 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteUserSession = `-- name: DeleteUserSession :execrows
+DELETE FROM sessions
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteUserSessionParams struct {
+	ID     string        `json:"id"`
+	UserID sql.NullInt32 `json:"user_id"`
+}
+
+// Deletes a session only if it belongs to the given user, so one user cannot revoke another's sessions.
+func (q *Queries) DeleteUserSession(ctx context.Context, arg DeleteUserSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUserSession, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getPasswordResetToken = `-- name: GetPasswordResetToken :one
-SELECT email, token, created_at FROM password_reset_tokens
+SELECT email, token_hash, created_at, expires_at FROM password_reset_tokens
 WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetPasswordResetToken(ctx context.Context, email string) (PasswordResetToken, error) {
 	row := q.db.QueryRowContext(ctx, getPasswordResetToken, email)
 	var i PasswordResetToken
-	err := row.Scan(&i.Email, &i.Token, &i.CreatedAt)
+	err := row.Scan(
+		&i.Email,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
 	return i, err
 }
 
 const getRoleByID = `-- name: GetRoleByID :one
-SELECT id, name, description, created_at, updated_at FROM roles
+SELECT id, name, description, created_at, updated_at, parent_id FROM roles
 WHERE id = $1 LIMIT 1
 `
 
@@ -246,12 +482,13 @@ func (q *Queries) GetRoleByID(ctx context.Context, id int32) (Role, error) {
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentID,
 	)
 	return i, err
 }
 
 const getRoleByName = `-- name: GetRoleByName :one
-SELECT id, name, description, created_at, updated_at FROM roles
+SELECT id, name, description, created_at, updated_at, parent_id FROM roles
 WHERE name = $1 LIMIT 1
 `
 
@@ -264,12 +501,13 @@ func (q *Queries) GetRoleByName(ctx context.Context, name string) (Role, error)
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentID,
 	)
 	return i, err
 }
 
 const getSessionByID = `-- name: GetSessionByID :one
-SELECT id, user_id, ip_address, user_agent, payload, last_activity FROM sessions
+SELECT id, user_id, ip_address, user_agent, payload, last_activity, refresh_token_hash, refresh_expires_at, created_at, previous_refresh_token_hash FROM sessions
 WHERE id = $1 LIMIT 1
 `
 
@@ -283,13 +521,30 @@ func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error
 		&i.UserAgent,
 		&i.Payload,
 		&i.LastActivity,
+		&i.RefreshTokenHash,
+		&i.RefreshExpiresAt,
+		&i.CreatedAt,
+		&i.PreviousRefreshTokenHash,
 	)
 	return i, err
 }
 
+const getUserAuthVersion = `-- name: GetUserAuthVersion :one
+SELECT auth_version FROM users
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+// Soft-deleted users have no auth version, so none of their tokens are accepted.
+func (q *Queries) GetUserAuthVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getUserAuthVersion, id)
+	var auth_version int32
+	err := row.Scan(&auth_version)
+	return auth_version, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at FROM users
-WHERE email = $1 LIMIT 1
+SELECT id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at FROM users
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -302,19 +557,25 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at FROM users
-WHERE id = $1 LIMIT 1
+SELECT id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at FROM users
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
+// Like the other lookups, leaves out soft-deleted users. Admin tools that need them
+// use GetUserByIDIncludingDeleted.
 func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 	row := q.db.QueryRowContext(ctx, getUserByID, id)
 	var i User
@@ -325,17 +586,48 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const getUserByIDIncludingDeleted = `-- name: GetUserByIDIncludingDeleted :one
+SELECT id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at FROM users
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByIDIncludingDeleted(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIDIncludingDeleted, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerifiedAt,
+		&i.RoleID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at FROM users
-WHERE username = $1 LIMIT 1
+SELECT id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at FROM users
+WHERE username = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
@@ -348,16 +640,20 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
 	)
 	return i, err
 }
 
 const listRoles = `-- name: ListRoles :many
-SELECT id, name, description, created_at, updated_at FROM roles
+SELECT id, name, description, created_at, updated_at, parent_id FROM roles
 ORDER BY id
 LIMIT $2 OFFSET $1
 `
@@ -382,6 +678,48 @@ func (q *Queries) ListRoles(ctx context.Context, arg ListRolesParams) ([]Role, e
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionsByUserID = `-- name: ListSessionsByUserID :many
+SELECT id, user_id, ip_address, user_agent, payload, last_activity, refresh_token_hash, refresh_expires_at, created_at, previous_refresh_token_hash FROM sessions
+WHERE user_id = $1 AND (refresh_expires_at IS NULL OR refresh_expires_at > NOW())
+ORDER BY last_activity DESC
+`
+
+// Sessions whose refresh token has expired can no longer be used and are left out.
+func (q *Queries) ListSessionsByUserID(ctx context.Context, userID sql.NullInt32) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.Payload,
+			&i.LastActivity,
+			&i.RefreshTokenHash,
+			&i.RefreshExpiresAt,
+			&i.CreatedAt,
+			&i.PreviousRefreshTokenHash,
 		); err != nil {
 			return nil, err
 		}
@@ -397,7 +735,8 @@ func (q *Queries) ListRoles(ctx context.Context, arg ListRolesParams) ([]Role, e
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at FROM users
+SELECT id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at FROM users
+WHERE deleted_at IS NULL
 ORDER BY id
 LIMIT $2 OFFSET $1
 `
@@ -423,10 +762,14 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.Email,
 			&i.EmailVerifiedAt,
 			&i.RoleID,
-			&i.RememberTokenUuid,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.AuthVersion,
+			&i.DisplayName,
+			&i.AvatarUrl,
+			&i.ErasureScheduledAt,
+			&i.ErasedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -441,13 +784,85 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const listUsersDueForErasure = `-- name: ListUsersDueForErasure :many
+SELECT id FROM users
+WHERE erasure_scheduled_at <= NOW() AND erased_at IS NULL
+ORDER BY erasure_scheduled_at
+LIMIT $1
+`
+
+// Users whose erasure grace period is over, oldest request first.
+func (q *Queries) ListUsersDueForErasure(ctx context.Context, limit int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersDueForErasure, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :many
+WITH purged AS (
+    DELETE FROM users
+    WHERE deleted_at IS NOT NULL AND deleted_at < $1::timestamptz
+      AND erasure_scheduled_at IS NULL
+    RETURNING id, email
+), purged_tokens AS (
+    DELETE FROM password_reset_tokens t
+    USING purged p
+    WHERE t.email = p.email
+      AND NOT EXISTS (SELECT 1 FROM users u WHERE u.email = t.email AND u.deleted_at IS NULL)
+)
+SELECT id FROM purged
+`
+
+// Permanently deletes users that were soft-deleted before the cutoff, together with
+// reset tokens sent to their email unless an active user has the same address. Users
+// with a pending erasure are left to the erasure, which also removes their items.
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, cutoff time.Time) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedUsers, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const restoreUser = `-- name: RestoreUser :one
 UPDATE users
 SET
     deleted_at = NULL,
     updated_at = NOW()
-WHERE id = $1
-RETURNING id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at
+WHERE id = $1 AND deleted_at IS NOT NULL AND erased_at IS NULL
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
 `
 
 func (q *Queries) RestoreUser(ctx context.Context, id int32) (User, error) {
@@ -460,23 +875,209 @@ func (q *Queries) RestoreUser(ctx context.Context, id int32) (User, error) {
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const rotateSessionRefreshToken = `-- name: RotateSessionRefreshToken :one
+UPDATE sessions
+SET
+    previous_refresh_token_hash = refresh_token_hash,
+    refresh_token_hash = $1,
+    refresh_expires_at = $2,
+    ip_address = $3,
+    user_agent = $4,
+    last_activity = $5
+WHERE id = $6 AND refresh_token_hash = $7
+RETURNING id, user_id, ip_address, user_agent, payload, last_activity, refresh_token_hash, refresh_expires_at, created_at, previous_refresh_token_hash
+`
+
+type RotateSessionRefreshTokenParams struct {
+	NewRefreshTokenHash     sql.NullString `json:"new_refresh_token_hash"`
+	RefreshExpiresAt        sql.NullTime   `json:"refresh_expires_at"`
+	IpAddress               sql.NullString `json:"ip_address"`
+	UserAgent               sql.NullString `json:"user_agent"`
+	LastActivity            int32          `json:"last_activity"`
+	ID                      string         `json:"id"`
+	CurrentRefreshTokenHash sql.NullString `json:"current_refresh_token_hash"`
+}
+
+func (q *Queries) RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, rotateSessionRefreshToken,
+		arg.NewRefreshTokenHash,
+		arg.RefreshExpiresAt,
+		arg.IpAddress,
+		arg.UserAgent,
+		arg.LastActivity,
+		arg.ID,
+		arg.CurrentRefreshTokenHash,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.Payload,
+		&i.LastActivity,
+		&i.RefreshTokenHash,
+		&i.RefreshExpiresAt,
+		&i.CreatedAt,
+		&i.PreviousRefreshTokenHash,
 	)
 	return i, err
 }
 
+const scheduleUserErasure = `-- name: ScheduleUserErasure :one
+UPDATE users
+SET
+    erasure_scheduled_at = $2,
+    updated_at = NOW()
+WHERE id = $1 AND erased_at IS NULL
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
+`
+
+type ScheduleUserErasureParams struct {
+	ID                 int32        `json:"id"`
+	ErasureScheduledAt sql.NullTime `json:"erasure_scheduled_at"`
+}
+
+func (q *Queries) ScheduleUserErasure(ctx context.Context, arg ScheduleUserErasureParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, scheduleUserErasure, arg.ID, arg.ErasureScheduledAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerifiedAt,
+		&i.RoleID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT u.id, u.username, u.hashed_password, u.email, u.email_verified_at, u.role_id, u.created_at, u.updated_at, u.deleted_at, u.auth_version, u.display_name, u.avatar_url, u.erasure_scheduled_at, u.erased_at FROM users u
+WHERE ($1::text IS NULL OR EXISTS (
+        SELECT 1 FROM roles r
+        WHERE r.name = $1::text AND (
+            r.id = u.role_id
+            OR EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id AND ur.role_id = r.id)
+        )
+    ))
+    AND ($2::boolean IS NULL OR (u.email_verified_at IS NOT NULL) = $2::boolean)
+    AND ($3::boolean IS NULL OR (u.deleted_at IS NOT NULL) = $3::boolean)
+    AND ($4::timestamptz IS NULL OR u.created_at >= $4::timestamptz)
+    AND ($5::timestamptz IS NULL OR u.created_at < $5::timestamptz)
+    AND ($6::text IS NULL
+        OR strpos(lower(u.username), lower($6::text)) > 0
+        OR strpos(lower(u.email), lower($6::text)) > 0)
+ORDER BY
+    CASE WHEN $7::text = 'username' AND NOT $8::boolean THEN u.username END ASC,
+    CASE WHEN $7::text = 'username' AND $8::boolean THEN u.username END DESC,
+    CASE WHEN $7::text = 'email' AND NOT $8::boolean THEN u.email END ASC,
+    CASE WHEN $7::text = 'email' AND $8::boolean THEN u.email END DESC,
+    CASE WHEN $7::text = 'created_at' AND NOT $8::boolean THEN u.created_at END ASC,
+    CASE WHEN $7::text = 'created_at' AND $8::boolean THEN u.created_at END DESC,
+    CASE WHEN $8::boolean THEN u.id END DESC,
+    u.id ASC
+LIMIT $9 OFFSET $10
+`
+
+type SearchUsersParams struct {
+	Role        sql.NullString `json:"role"`
+	Verified    sql.NullBool   `json:"verified"`
+	Deleted     sql.NullBool   `json:"deleted"`
+	CreatedFrom sql.NullTime   `json:"created_from"`
+	CreatedTo   sql.NullTime   `json:"created_to"`
+	Search      sql.NullString `json:"search"`
+	SortBy      string         `json:"sort_by"`
+	SortDesc    bool           `json:"sort_desc"`
+	Limit       int32          `json:"limit"`
+	Offset      int32          `json:"offset"`
+}
+
+// Lists users for the admin area. Each filter is skipped when NULL: role matches the
+// primary role or a role from user_roles, search matches part of the username or email
+// regardless of case. sort_by is one of id, username, email or created_at; ties are
+// broken by id.
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers,
+		arg.Role,
+		arg.Verified,
+		arg.Deleted,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Search,
+		arg.SortBy,
+		arg.SortDesc,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.HashedPassword,
+			&i.Email,
+			&i.EmailVerifiedAt,
+			&i.RoleID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.AuthVersion,
+			&i.DisplayName,
+			&i.AvatarUrl,
+			&i.ErasureScheduledAt,
+			&i.ErasedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const softDeleteUser = `-- name: SoftDeleteUser :one
 UPDATE users
 SET
     deleted_at = NOW(),
+    auth_version = auth_version + 1,
     updated_at = NOW()
-WHERE id = $1
-RETURNING id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
 `
 
+// Bumps auth_version as well, so access tokens issued before the deletion stop being accepted.
+// Users that are already deleted keep their original deletion time.
 func (q *Queries) SoftDeleteUser(ctx context.Context, id int32) (User, error) {
 	row := q.db.QueryRowContext(ctx, softDeleteUser, id)
 	var i User
@@ -487,10 +1088,14 @@ func (q *Queries) SoftDeleteUser(ctx context.Context, id int32) (User, error) {
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
 	)
 	return i, err
 }
@@ -500,19 +1105,26 @@ UPDATE roles
 SET
     name = $2,
     description = $3,
+    parent_id = $4,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, description, created_at, updated_at
+RETURNING id, name, description, created_at, updated_at, parent_id
 `
 
 type UpdateRoleParams struct {
 	ID          int32          `json:"id"`
 	Name        string         `json:"name"`
 	Description sql.NullString `json:"description"`
+	ParentID    sql.NullInt32  `json:"parent_id"`
 }
 
 func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, error) {
-	row := q.db.QueryRowContext(ctx, updateRole, arg.ID, arg.Name, arg.Description)
+	row := q.db.QueryRowContext(ctx, updateRole,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.ParentID,
+	)
 	var i Role
 	err := row.Scan(
 		&i.ID,
@@ -520,6 +1132,7 @@ func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, e
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentID,
 	)
 	return i, err
 }
@@ -533,7 +1146,7 @@ SET
     payload = $5,
     last_activity = $6
 WHERE id = $1
-RETURNING id, user_id, ip_address, user_agent, payload, last_activity
+RETURNING id, user_id, ip_address, user_agent, payload, last_activity, refresh_token_hash, refresh_expires_at, created_at, previous_refresh_token_hash
 `
 
 type UpdateSessionParams struct {
@@ -562,6 +1175,10 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		&i.UserAgent,
 		&i.Payload,
 		&i.LastActivity,
+		&i.RefreshTokenHash,
+		&i.RefreshExpiresAt,
+		&i.CreatedAt,
+		&i.PreviousRefreshTokenHash,
 	)
 	return i, err
 }
@@ -574,22 +1191,20 @@ SET
     email = $4,
     email_verified_at = $5,
     role_id = $6,
-    remember_token_uuid = $7,
     updated_at = NOW(),
-    deleted_at = $8
+    deleted_at = $7
 WHERE id = $1
-RETURNING id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
 `
 
 type UpdateUserParams struct {
-	ID                int32          `json:"id"`
-	Username          string         `json:"username"`
-	HashedPassword    string         `json:"hashed_password"`
-	Email             string         `json:"email"`
-	EmailVerifiedAt   sql.NullTime   `json:"email_verified_at"`
-	RoleID            int32          `json:"role_id"`
-	RememberTokenUuid sql.NullString `json:"remember_token_uuid"`
-	DeletedAt         sql.NullTime   `json:"deleted_at"`
+	ID              int32        `json:"id"`
+	Username        string       `json:"username"`
+	HashedPassword  string       `json:"hashed_password"`
+	Email           string       `json:"email"`
+	EmailVerifiedAt sql.NullTime `json:"email_verified_at"`
+	RoleID          int32        `json:"role_id"`
+	DeletedAt       sql.NullTime `json:"deleted_at"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -600,7 +1215,6 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Email,
 		arg.EmailVerifiedAt,
 		arg.RoleID,
-		arg.RememberTokenUuid,
 		arg.DeletedAt,
 	)
 	var i User
@@ -611,10 +1225,134 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET
+    email = $2,
+    email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
+`
+
+type UpdateUserEmailParams struct {
+	ID    int32  `json:"id"`
+	Email string `json:"email"`
+}
+
+// The new address counts as verified, since it is only set once a link sent to it was followed.
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmail, arg.ID, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerifiedAt,
+		&i.RoleID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET
+    hashed_password = $2,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
+`
+
+type UpdateUserPasswordParams struct {
+	ID             int32  `json:"id"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerifiedAt,
+		&i.RoleID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET
+    username = $2,
+    display_name = $3,
+    avatar_url = $4,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
+`
+
+type UpdateUserProfileParams struct {
+	ID          int32          `json:"id"`
+	Username    string         `json:"username"`
+	DisplayName sql.NullString `json:"display_name"`
+	AvatarUrl   sql.NullString `json:"avatar_url"`
+}
+
+// Only touches the fields users may edit on their own profile.
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.ID,
+		arg.Username,
+		arg.DisplayName,
+		arg.AvatarUrl,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Email,
+		&i.EmailVerifiedAt,
+		&i.RoleID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
 	)
 	return i, err
 }
@@ -625,7 +1363,7 @@ SET
     role_id = $2,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
 `
 
 type UpdateUserRoleParams struct {
@@ -643,10 +1381,14 @@ func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams)
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
 	)
 	return i, err
 }
@@ -657,7 +1399,7 @@ SET
     email_verified_at = NOW(),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, hashed_password, email, email_verified_at, role_id, remember_token_uuid, created_at, updated_at, deleted_at
+RETURNING id, username, hashed_password, email, email_verified_at, role_id, created_at, updated_at, deleted_at, auth_version, display_name, avatar_url, erasure_scheduled_at, erased_at
 `
 
 func (q *Queries) VerifyUserEmail(ctx context.Context, id int32) (User, error) {
@@ -670,10 +1412,14 @@ func (q *Queries) VerifyUserEmail(ctx context.Context, id int32) (User, error) {
 		&i.Email,
 		&i.EmailVerifiedAt,
 		&i.RoleID,
-		&i.RememberTokenUuid,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.AuthVersion,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.ErasureScheduledAt,
+		&i.ErasedAt,
 	)
 	return i, err
 }
@@ -42,5 +42,5 @@ require (
 	go.uber.org/atomic v1.9.0 // indirect
 	golang.org/x/net v0.40.0 // indirect
 	golang.org/x/tools v0.33.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -11,12 +11,16 @@ import (
 
 	"external-backend-go/configs"
 	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/auth"
 	"external-backend-go/internal/database"
 	"external-backend-go/internal/elasticsearch"
 	"external-backend-go/internal/email"
 	"external-backend-go/internal/handler"
 	"external-backend-go/internal/logger"
 	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/oidc"
+	"external-backend-go/internal/password"
+	"external-backend-go/internal/policy"
 	"external-backend-go/internal/routes"
 	"external-backend-go/internal/service"
 	"external-backend-go/internal/store"
@@ -32,17 +36,39 @@ type App struct {
 	RoleStore               store.RoleStore
 	PasswordResetTokenStore store.PasswordResetTokenStore
 	SessionStore            store.SessionStore
+	PermissionStore         store.PermissionStore
+	AuthVersionStore        store.AuthVersionStore
+	UserRoleStore           store.UserRoleStore
+	RoleHierarchyStore      store.RoleHierarchyStore
+	MFAStore                store.MFAStore
+	LoginThrottleStore      store.LoginThrottleStore
+	EmailVerificationStore  store.EmailVerificationTokenStore
+	EmailChangeStore        store.EmailChangeTokenStore
+	MagicLinkStore          store.MagicLinkStore
+	RememberTokenStore      store.RememberTokenStore
+	UserIdentityStore       store.UserIdentityStore
+	OIDCAuthRequestStore    store.OIDCAuthRequestStore
 	SearchStore             store.SearchStore
 	ElasticsearchClient     *elasticsearch.Client
+	KeyRing                 *auth.KeyRing
+	Tokens                  *auth.TokenConfig
+	PolicyEngine            *policy.Engine
 
-	AuthService *service.AuthService
-	ItemService *service.ItemService
-	AuthHandler *handler.AuthHandler
-	ItemHandler *handler.ItemHandler
-	EmailSender email.EmailSender
-	RateLimiter *middleware.RateLimiter
-	Logger      *logger.Logger
-	Validator   *validator.Validate
+	AuthService    *service.AuthService
+	ItemService    *service.ItemService
+	RoleService    *service.RoleService
+	UserService    *service.UserService
+	PrivacyService *service.PrivacyService
+	AuthHandler    *handler.AuthHandler
+	ItemHandler    *handler.ItemHandler
+	RoleHandler    *handler.RoleHandler
+	UserHandler    *handler.UserHandler
+	PrivacyHandler *handler.PrivacyHandler
+	PolicyHandler  *handler.PolicyHandler
+	EmailSender    email.EmailSender
+	RateLimiter    *middleware.RateLimiter
+	Logger         *logger.Logger
+	Validator      *validator.Validate
 }
 
 func NewApp(cfg *configs.Config) *App {
@@ -87,7 +113,19 @@ func (a *App) Initialize() {
 	a.ItemStore = store.NewItemStore(a.DB, a.Queries, baseRepo)
 	a.RoleStore = store.NewRoleStore(a.DB, a.Queries, baseRepo)
 	a.PasswordResetTokenStore = store.NewPasswordResetTokenStore(a.DB, a.Queries, baseRepo)
-	a.SessionStore = store.NewSessionStore(a.DB, a.Queries, baseRepo)
+	a.SessionStore = store.NewCachedSessionStore(store.NewSessionStore(a.DB, a.Queries, baseRepo), a.Config.JWT.SessionCacheTTL)
+	a.PermissionStore = store.NewCachedPermissionStore(store.NewPermissionStore(a.DB, a.Queries, baseRepo), a.Config.JWT.PermissionCacheTTL)
+	a.AuthVersionStore = store.NewCachedAuthVersionStore(store.NewAuthVersionStore(a.DB, a.Queries, baseRepo), a.Config.JWT.AuthVersionCacheTTL)
+	a.UserRoleStore = store.NewUserRoleStore(a.DB, a.Queries, baseRepo)
+	a.RoleHierarchyStore = store.NewCachedRoleHierarchyStore(store.NewRoleHierarchyStore(a.DB, a.Queries, baseRepo), a.Config.JWT.PermissionCacheTTL)
+	a.MFAStore = store.NewMFAStore(a.DB, a.Queries, baseRepo)
+	a.LoginThrottleStore = store.NewLoginThrottleStore(a.DB, a.Queries, baseRepo)
+	a.EmailVerificationStore = store.NewEmailVerificationTokenStore(a.DB, a.Queries, baseRepo)
+	a.EmailChangeStore = store.NewEmailChangeTokenStore(a.DB, a.Queries, baseRepo)
+	a.MagicLinkStore = store.NewMagicLinkStore(a.DB, a.Queries, baseRepo)
+	a.RememberTokenStore = store.NewRememberTokenStore(a.DB, a.Queries, baseRepo)
+	a.UserIdentityStore = store.NewUserIdentityStore(a.DB, a.Queries, baseRepo)
+	a.OIDCAuthRequestStore = store.NewOIDCAuthRequestStore(a.DB, a.Queries, baseRepo)
 
 	a.ElasticsearchClient, err = elasticsearch.NewElasticsearchClient("http://elasticsearch:9200")
 	if err != nil {
@@ -107,13 +145,171 @@ func (a *App) Initialize() {
 
 	a.Validator = validator.New()
 
+	if a.Config.JWT.SigningKeyFile != "" {
+		a.KeyRing, err = auth.LoadKeyRing(a.Config.JWT.SigningKeyFile, a.Config.JWT.RetiredKeyFiles)
+		if err != nil {
+			a.Logger.Fatal("Failed to load JWT signing keys: %v", err)
+		}
+		a.Logger.Info("JWT key ring loaded. Active key: %s (%s), retired keys: %d", a.KeyRing.Active().ID, a.KeyRing.Active().Method.Alg(), len(a.Config.JWT.RetiredKeyFiles))
+	} else {
+		a.KeyRing = auth.NewHMACKeyRing(a.Config.JWTSecret)
+		a.Logger.Warn("JWT_SIGNING_KEY_FILE not set, signing tokens with the shared HS256 secret.")
+	}
+
+	a.Tokens = &auth.TokenConfig{
+		Keys:     a.KeyRing,
+		Issuer:   a.Config.JWT.Issuer,
+		Audience: a.Config.JWT.Audience,
+		Leeway:   a.Config.JWT.Leeway,
+	}
+
+	if a.Config.Policy.File != "" {
+		a.PolicyEngine = policy.NewEngine(a.Config.Policy.File, a.RoleHierarchyStore, a.PermissionStore, a.Logger)
+		a.PolicyEngine.RegisterResource("item", policy.ItemResource(a.ItemStore))
+		if err := a.PolicyEngine.Load(); err != nil {
+			a.Logger.Fatal("Failed to load policy file: %v", err)
+		}
+		a.PolicyEngine.Watch(a.Config.Policy.ReloadInterval)
+		a.Logger.Info("Policy file %s loaded (%d rules), checking for changes every %s", a.Config.Policy.File, a.PolicyEngine.Info().Rules, a.Config.Policy.ReloadInterval)
+	} else {
+		a.Logger.Warn("POLICY_FILE not set, access policies are disabled.")
+	}
+
+	mfaSecretBox, err := auth.NewSecretBox(a.Config.MFA.EncryptionKey)
+	if err != nil {
+		a.Logger.Fatal("Failed to initialize MFA secret encryption: %v", err)
+	}
+
+	passwordHasher, err := password.NewHasher(a.Config.Password.HashAlgorithm,
+		password.Argon2id{
+			Memory:      a.Config.Password.Argon2Memory,
+			Iterations:  a.Config.Password.Argon2Iterations,
+			Parallelism: a.Config.Password.Argon2Parallelism,
+			SaltLength:  password.DefaultArgon2id.SaltLength,
+			KeyLength:   password.DefaultArgon2id.KeyLength,
+		},
+		password.Bcrypt{Cost: a.Config.Password.BcryptCost},
+	)
+	if err != nil {
+		a.Logger.Fatal("Failed to initialize password hasher: %v", err)
+	}
+	a.Logger.Info("New passwords are hashed with %s", passwordHasher.Algorithm())
+
+	passwordPolicy := &password.Policy{
+		MinLength:        a.Config.Password.MinLength,
+		MaxLength:        a.Config.Password.MaxLength,
+		RequireUpper:     a.Config.Password.RequireUppercase,
+		RequireLower:     a.Config.Password.RequireLowercase,
+		RequireDigit:     a.Config.Password.RequireDigit,
+		RequireSymbol:    a.Config.Password.RequireSymbol,
+		DisallowUserInfo: a.Config.Password.DisallowUserInfo,
+	}
+	if a.Config.Password.BreachedFile != "" {
+		passwordPolicy.Breached, err = password.LoadBreachedList(a.Config.Password.BreachedFile)
+		if err != nil {
+			a.Logger.Fatal("Failed to load breached passwords: %v", err)
+		}
+		a.Logger.Info("Loaded %d breached password hashes from %s", passwordPolicy.Breached.Size(), a.Config.Password.BreachedFile)
+	} else {
+		a.Logger.Warn("PASSWORD_BREACHED_FILE not set, passwords are not checked against breached passwords.")
+	}
+
+	// Discovery and keys of OIDC providers are fetched on first use, so a provider
+	// that is down does not keep the API from starting.
+	oidcClient := &http.Client{Timeout: 10 * time.Second}
+	oidcProviders := make(map[string]*service.OIDCProvider, len(a.Config.OIDC.Providers))
+	for _, p := range a.Config.OIDC.Providers {
+		oidcProviders[p.Name] = &service.OIDCProvider{
+			Provider: oidc.NewProvider(oidc.Config{
+				IssuerURL:    p.IssuerURL,
+				ClientID:     p.ClientID,
+				ClientSecret: p.ClientSecret,
+				RedirectURL:  p.RedirectURL,
+				Scopes:       p.Scopes,
+				Leeway:       a.Config.JWT.Leeway,
+			}, oidcClient),
+			AutoProvision: p.AutoProvision,
+			DefaultRole:   p.DefaultRole,
+		}
+		a.Logger.Info("OIDC login enabled for provider %s (%s), auto-provisioning: %t", p.Name, p.IssuerURL, p.AutoProvision)
+	}
+
 	// Initialize services
-	a.AuthService = service.NewAuthService(a.UserStore, a.RoleStore, a.SessionStore, a.PasswordResetTokenStore, a.Config.JWTSecret, a.EmailSender)
+	a.AuthService = service.NewAuthService(service.AuthStores{
+		UserStore:               a.UserStore,
+		RoleStore:               a.RoleStore,
+		UserRoleStore:           a.UserRoleStore,
+		SessionStore:            a.SessionStore,
+		PasswordResetTokenStore: a.PasswordResetTokenStore,
+		MFAStore:                a.MFAStore,
+		LoginThrottleStore:      a.LoginThrottleStore,
+		EmailVerificationStore:  a.EmailVerificationStore,
+		EmailChangeStore:        a.EmailChangeStore,
+		MagicLinkStore:          a.MagicLinkStore,
+		RememberTokenStore:      a.RememberTokenStore,
+		AuthVersionStore:        a.AuthVersionStore,
+		UserIdentityStore:       a.UserIdentityStore,
+		OIDCAuthRequestStore:    a.OIDCAuthRequestStore,
+	}, a.Tokens, mfaSecretBox, passwordHasher, passwordPolicy, a.EmailSender, oidcProviders, service.AuthSettings{
+		AccessTokenTTL:     a.Config.JWT.AccessTokenTTL,
+		RefreshTokenTTL:    a.Config.JWT.RefreshTokenTTL,
+		MFAIssuer:          a.Config.MFA.Issuer,
+		MFAPendingTokenTTL: a.Config.MFA.PendingTokenTTL,
+		LoginProtection: service.LoginProtectionSettings{
+			FreeAttempts:            int32(a.Config.Login.FreeAttempts),
+			AccountLockoutThreshold: int32(a.Config.Login.AccountLockoutThreshold),
+			IPLockoutThreshold:      int32(a.Config.Login.IPLockoutThreshold),
+			BaseDelay:               a.Config.Login.BaseDelay,
+			MaxDelay:                a.Config.Login.MaxDelay,
+			LockoutDuration:         a.Config.Login.LockoutDuration,
+			FailureWindow:           a.Config.Login.FailureWindow,
+		},
+		FrontendURL:          a.Config.FrontendURL,
+		EmailVerificationTTL: a.Config.Email.TokenTTL,
+		UnverifiedLoginMode:  a.Config.Email.UnverifiedLoginMode,
+		PasswordResetTTL:     a.Config.Links.PasswordResetTTL,
+		MagicLinkTTL:         a.Config.Links.MagicLinkTTL,
+		RememberMeTTL:        a.Config.Cookie.RememberMeTTL,
+		OIDCAuthRequestTTL:   a.Config.OIDC.AuthRequestTTL,
+	})
 	a.ItemService = service.NewItemService(a.ItemStore, a.SearchStore)
+	a.RoleService = service.NewRoleService(a.RoleStore, a.UserStore, a.UserRoleStore, a.RoleHierarchyStore, a.AuthVersionStore)
+	a.UserService = service.NewUserService(a.UserStore, a.SessionStore, a.RememberTokenStore, a.AuthVersionStore, a.EmailVerificationStore, a.PasswordResetTokenStore)
+	if a.Config.Users.DeletedRetention > 0 {
+		a.UserService.SchedulePurge(a.Config.Users.PurgeInterval, a.Config.Users.DeletedRetention)
+		a.Logger.Info("Soft-deleted users are purged after %s, checking every %s", a.Config.Users.DeletedRetention, a.Config.Users.PurgeInterval)
+	} else {
+		a.Logger.Warn("DELETED_USER_RETENTION is 0, soft-deleted users are kept until deleted permanently.")
+	}
+	a.PrivacyService = service.NewPrivacyService(service.PrivacyStores{
+		UserStore:               a.UserStore,
+		UserRoleStore:           a.UserRoleStore,
+		SessionStore:            a.SessionStore,
+		RememberTokenStore:      a.RememberTokenStore,
+		ItemStore:               a.ItemStore,
+		SearchStore:             a.SearchStore,
+		MFAStore:                a.MFAStore,
+		PasswordResetTokenStore: a.PasswordResetTokenStore,
+		EmailVerificationStore:  a.EmailVerificationStore,
+		EmailChangeStore:        a.EmailChangeStore,
+		LoginThrottleStore:      a.LoginThrottleStore,
+		UserIdentityStore:       a.UserIdentityStore,
+	}, a.EmailSender, a.AuthService, a.ItemService.ItemIndexName, a.Config.Users.ErasureGracePeriod)
+	a.PrivacyService.ScheduleErasure(a.Config.Users.ErasureInterval)
+	a.Logger.Info("Users are erased %s after requesting it, checking every %s", a.Config.Users.ErasureGracePeriod, a.Config.Users.ErasureInterval)
 
 	// Initialize handlers, passing logger and validator
-	a.ItemHandler = handler.NewItemHandler(a.ItemService, a.Logger, a.Validator)
-	a.AuthHandler = handler.NewAuthHandler(a.AuthService, a.Logger, a.Validator)
+	a.ItemHandler = handler.NewItemHandler(a.ItemService, middleware.NewPolicy(a.RoleHierarchyStore), a.Logger, a.Validator)
+	a.AuthHandler = handler.NewAuthHandler(a.AuthService, a.Logger, a.Validator, handler.CookieSettings{
+		Secure:      a.Config.Cookie.Secure,
+		Domain:      a.Config.Cookie.Domain,
+		SameSite:    a.Config.Cookie.SameSite,
+		AuthEnabled: a.Config.Cookie.AuthEnabled,
+	})
+	a.RoleHandler = handler.NewRoleHandler(a.RoleService, a.Logger, a.Validator)
+	a.UserHandler = handler.NewUserHandler(a.UserService, a.Logger)
+	a.PrivacyHandler = handler.NewPrivacyHandler(a.PrivacyService, a.Logger, a.Validator)
+	a.PolicyHandler = handler.NewPolicyHandler(a.PolicyEngine, a.RoleService, a.Logger)
 
 	// Initialize Rate Limiter
 	a.RateLimiter = middleware.NewRateLimiter(
@@ -125,17 +321,28 @@ func (a *App) Initialize() {
 	a.Logger.Info("Rate Limiter initialized. Enabled: %t, RPS: %.2f, Burst: %d", a.Config.RateLimiter.Enabled, a.Config.RateLimiter.RPS, a.Config.RateLimiter.Burst)
 
 	routes.SetupAPIRoutes(routes.AppDependencies{
-		Router:        a.Router,
-		AuthHandler:   a.AuthHandler,
-		ItemHandler:   a.ItemHandler,
-		JWTSecret:     a.Config.JWTSecret,
-		UserStore:     a.UserStore,
-		RoleStore:     a.RoleStore,
-		RateLimiter:   a.RateLimiter,
-		BasicAuthUser: a.Config.Auth.Basic.User,
-		BasicAuthPass: a.Config.Auth.Basic.Pass,
-		AppLogger:     a.Logger,
-		SearchStore:   a.SearchStore,
+		Router:             a.Router,
+		AuthHandler:        a.AuthHandler,
+		ItemHandler:        a.ItemHandler,
+		RoleHandler:        a.RoleHandler,
+		UserHandler:        a.UserHandler,
+		PrivacyHandler:     a.PrivacyHandler,
+		PolicyHandler:      a.PolicyHandler,
+		PolicyEngine:       a.PolicyEngine,
+		Tokens:             a.Tokens,
+		UserStore:          a.UserStore,
+		RoleStore:          a.RoleStore,
+		SessionStore:       a.SessionStore,
+		PermissionStore:    a.PermissionStore,
+		AuthVersionStore:   a.AuthVersionStore,
+		RoleHierarchyStore: a.RoleHierarchyStore,
+		MFARequired:        a.Config.MFA.RequiredForAdmin,
+		CookieAuth:         a.Config.Cookie.AuthEnabled,
+		RateLimiter:        a.RateLimiter,
+		BasicAuthUser:      a.Config.Auth.Basic.User,
+		BasicAuthPass:      a.Config.Auth.Basic.Pass,
+		AppLogger:          a.Logger,
+		SearchStore:        a.SearchStore,
 		// Validator:     a.Validator,
 	})
 
@@ -3,37 +3,192 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5" // Sử dụng jwt.v5
+	"github.com/google/uuid"
 )
 
+// ScopeMFAPending marks a token that only proves the password step of a login. It can
+// be exchanged at /login/2fa and is rejected everywhere else.
+const ScopeMFAPending = "mfa_pending"
+
+// ScopeUnverified marks an access token issued to a user whose email address is not
+// verified yet, when unverified logins run in restricted mode.
+const ScopeUnverified = "unverified"
+
+// ScopeMagicLink marks the token embedded in a magic login link. It can only be
+// redeemed at /login/magic-link/verify.
+const ScopeMagicLink = "magic_link"
+
+// Authentication method references (RFC 8176) recorded in the "amr" claim.
 const (
-	tokenTTL = time.Hour * 24
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+	// AMREmail is not registered in RFC 8176; it records a login through a link
+	// sent to the user's email address.
+	AMREmail = "email"
+	// AMRRememberMe is not registered in RFC 8176 either; it records a login with a
+	// remember-me cookie, which does not count as a second factor.
+	AMRRememberMe = "remember"
+	// AMRFederated is not registered in RFC 8176 either; it records a login through
+	// an external OpenID Connect provider.
+	AMRFederated = "fed"
 )
 
-func GenerateToken(userID int32, username, roleName, jwtSecret string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub":      userID,
-		"username": username,
-		"role":     roleName,
-		"exp":      time.Now().Add(tokenTTL).Unix(),
-	})
+// TokenConfig holds everything needed to issue and validate tokens. Issuer and
+// Audience are written into every token and required on validation, so tokens minted
+// by another service sharing the same key are rejected.
+type TokenConfig struct {
+	Keys     *KeyRing
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat.
+	Leeway time.Duration
+}
+
+// Claims are the claims carried by access tokens and MFA pending tokens. Role is the
+// user's primary role and Roles every role assigned to them, without the roles they
+// inherit. AuthVersion is the user's auth version when the token was issued;
+// AuthMiddleware rejects the token once the version in the database has moved on.
+type Claims struct {
+	Username    string   `json:"username,omitempty"`
+	Role        string   `json:"role,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	SessionID   string   `json:"sid,omitempty"`
+	AMR         []string `json:"amr,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	AuthVersion int32    `json:"ver,omitempty"`
+	// RememberMe is only set on MFA pending tokens, carrying the remember-me choice
+	// of the first step to the end of the login.
+	RememberMe bool `json:"rmb,omitempty"`
+	jwt.RegisteredClaims
+
+	// UserID is the parsed form of the "sub" claim, set by ValidateToken.
+	UserID int32 `json:"-"`
+}
+
+// Validate is called by the parser after the standard checks. Every token this
+// service issues carries a jti and nbf, so a token without them was not minted here.
+func (c *Claims) Validate() error {
+	if c.ID == "" {
+		return errors.New("token is missing the jti claim")
+	}
+	if c.NotBefore == nil {
+		return errors.New("token is missing the nbf claim")
+	}
+	return nil
+}
 
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+// HasAMR reports whether method is one of the authentication methods the token was
+// issued for.
+func (c *Claims) HasAMR(method string) bool {
+	for _, m := range c.AMR {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleNames returns the roles assigned to the user. Tokens issued before Roles was
+// introduced only carry the primary role.
+func (c *Claims) RoleNames() []string {
+	if len(c.Roles) > 0 {
+		return c.Roles
+	}
+	if c.Role != "" {
+		return []string{c.Role}
+	}
+	return nil
+}
+
+func GenerateToken(userID int32, username, roleName string, roleNames []string, sessionID string, authVersion int32, scope string, amr []string, cfg *TokenConfig, ttl time.Duration) (string, error) {
+	return signClaims(&Claims{
+		Username:         username,
+		Role:             roleName,
+		Roles:            roleNames,
+		SessionID:        sessionID,
+		AuthVersion:      authVersion,
+		AMR:              amr,
+		Scope:            scope,
+		RegisteredClaims: registeredClaims(userID, cfg, ttl),
+	}, cfg.Keys)
+}
+
+// GenerateMFAPendingToken issues the short-lived token returned by a first-factor login
+// when the user still has to present a second factor. amr lists the methods already passed
+// and rememberMe whether the user asked to be remembered.
+func GenerateMFAPendingToken(userID int32, amr []string, rememberMe bool, cfg *TokenConfig, ttl time.Duration) (string, error) {
+	return signClaims(&Claims{
+		Scope:            ScopeMFAPending,
+		AMR:              amr,
+		RememberMe:       rememberMe,
+		RegisteredClaims: registeredClaims(userID, cfg, ttl),
+	}, cfg.Keys)
+}
+
+// ValidateMFAPendingToken validates a token produced by GenerateMFAPendingToken.
+func ValidateMFAPendingToken(tokenString string, cfg *TokenConfig) (*Claims, error) {
+	claims, err := ValidateToken(tokenString, cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
+		return nil, err
 	}
-	return tokenString, nil
+	if claims.Scope != ScopeMFAPending {
+		return nil, errors.New("token is not an MFA pending token")
+	}
+	return claims, nil
 }
 
-func ValidateToken(tokenString, jwtSecret string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+// GenerateMagicLinkToken issues the token embedded in a magic login link. Its jti is
+// recorded when the link is redeemed so the link works only once.
+func GenerateMagicLinkToken(userID int32, cfg *TokenConfig, ttl time.Duration) (string, error) {
+	return signClaims(&Claims{
+		Scope:            ScopeMagicLink,
+		RegisteredClaims: registeredClaims(userID, cfg, ttl),
+	}, cfg.Keys)
+}
+
+// ValidateMagicLinkToken validates a token produced by GenerateMagicLinkToken. The
+// caller is responsible for checking that its jti has not been redeemed yet.
+func ValidateMagicLinkToken(tokenString string, cfg *TokenConfig) (*Claims, error) {
+	claims, err := ValidateToken(tokenString, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Scope != ScopeMagicLink {
+		return nil, errors.New("token is not a magic link token")
+	}
+	return claims, nil
+}
+
+func ValidateToken(tokenString string, cfg *TokenConfig) (*Claims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(cfg.Keys.Algorithms()),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := cfg.Keys.Lookup(kid)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(jwtSecret), nil
-	})
+		return key.verifyKey, nil
+	}, options...)
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -43,10 +198,41 @@ func ValidateToken(tokenString, jwtSecret string) (jwt.MapClaims, error) {
 		return nil, errors.New("invalid token")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("failed to parse claims from token")
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid subject claim")
 	}
+	claims.UserID = int32(userID)
 
 	return claims, nil
 }
+
+func registeredClaims(userID int32, cfg *TokenConfig, ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    cfg.Issuer,
+		Subject:   strconv.Itoa(int(userID)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        uuid.NewString(),
+	}
+	if cfg.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{cfg.Audience}
+	}
+	return claims
+}
+
+func signClaims(claims jwt.Claims, keys *KeyRing) (string, error) {
+	signingKey := keys.Active()
+	token := jwt.NewWithClaims(signingKey.Method, claims)
+	if signingKey.ID != hmacKeyID {
+		token.Header["kid"] = signingKey.ID
+	}
+
+	tokenString, err := token.SignedString(signingKey.signKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
+}
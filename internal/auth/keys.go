@@ -0,0 +1,332 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const hmacKeyID = "hs256"
+
+var ErrUnknownKeyID = errors.New("unknown signing key")
+
+// SigningKey is a single entry of a KeyRing. Retired keys only carry the public half
+// (or the private half is simply never used for signing).
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeyRing holds the key used to sign new tokens plus every key whose tokens are still
+// accepted. Keys are addressed by the "kid" header of the token.
+type KeyRing struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// NewHMACKeyRing returns a key ring backed by a single shared HS256 secret. It is used
+// when no asymmetric keys are configured and publishes an empty JWKS.
+func NewHMACKeyRing(secret string) *KeyRing {
+	key := &SigningKey{
+		ID:        hmacKeyID,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+	return &KeyRing{active: key, keys: map[string]*SigningKey{key.ID: key}}
+}
+
+// LoadKeyRing reads the active private key and any retired keys from PEM files.
+// Retired keys may be either private or public keys; they are only used to verify
+// tokens that were signed before the rotation and are still unexpired.
+func LoadKeyRing(activeKeyFile string, retiredKeyFiles []string) (*KeyRing, error) {
+	active, err := loadSigningKey(activeKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active signing key: %w", err)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("active signing key %s must be a private key", activeKeyFile)
+	}
+
+	ring := &KeyRing{active: active, keys: map[string]*SigningKey{active.ID: active}}
+	for _, path := range retiredKeyFiles {
+		retired, err := loadSigningKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load retired signing key: %w", err)
+		}
+		retired.signKey = nil
+		if _, exists := ring.keys[retired.ID]; !exists {
+			ring.keys[retired.ID] = retired
+		}
+	}
+	return ring, nil
+}
+
+// Active returns the key used to sign new tokens.
+func (k *KeyRing) Active() *SigningKey {
+	return k.active
+}
+
+// Lookup returns the key a token header points at. Tokens from an HMAC ring carry
+// no kid, so an empty kid resolves to the active key only in that mode.
+func (k *KeyRing) Lookup(kid string) (*SigningKey, error) {
+	if kid == "" && k.active.ID == hmacKeyID {
+		return k.active, nil
+	}
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return key, nil
+}
+
+// Algorithms lists the algorithms of every key in the ring, for use with
+// jwt.WithValidMethods.
+func (k *KeyRing) Algorithms() []string {
+	seen := map[string]bool{}
+	var algs []string
+	for _, key := range k.keys {
+		if !seen[key.Method.Alg()] {
+			seen[key.Method.Alg()] = true
+			algs = append(algs, key.Method.Alg())
+		}
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+// JWK is the public representation of a key as defined by RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKey decodes the key for verifying signatures, e.g. of tokens issued by an
+// external identity provider.
+func (j JWK) PublicKey() (interface{}, error) {
+	b64 := base64.RawURLEncoding.DecodeString
+	switch j.Kty {
+	case "RSA":
+		n, err := b64(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := b64(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported elliptic curve %q", j.Crv)
+		}
+		x, err := b64(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := b64(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return pub, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", j.Crv)
+		}
+		x, err := b64(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+}
+
+// NewJWK returns the public JWK of pub, which must be an RSA, ECDSA or Ed25519 public
+// key.
+func NewJWK(kid, alg string, pub interface{}) (JWK, error) {
+	jwk, ok := publicJWK(kid, alg, pub)
+	if !ok {
+		return JWK{}, fmt.Errorf("unsupported key type %T", pub)
+	}
+	return jwk, nil
+}
+
+// JWKS returns the public keys of the ring. Symmetric keys are never published.
+func (k *KeyRing) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range k.keys {
+		jwk, ok := publicJWK(key.ID, key.Method.Alg(), key.verifyKey)
+		if ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func loadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q in %s", block.Type, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key in %s: %w", path, err)
+	}
+
+	key := &SigningKey{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodRS256, k
+	case *ecdsa.PrivateKey:
+		key.signKey, key.verifyKey = k, &k.PublicKey
+		key.Method, err = ecdsaMethod(k.Curve)
+	case *ecdsa.PublicKey:
+		key.verifyKey = k
+		key.Method, err = ecdsaMethod(k.Curve)
+	case ed25519.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T in %s", parsed, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unsupported key in %s: %w", path, err)
+	}
+
+	key.ID, err = thumbprint(key.verifyKey)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func ecdsaMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	}
+	return nil, fmt.Errorf("unsupported elliptic curve %s", curve.Params().Name)
+}
+
+func publicJWK(kid, alg string, pub interface{}) (JWK, bool) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", Kid: kid, Use: "sig", Alg: alg, N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())}, true
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return JWK{Kty: "EC", Kid: kid, Use: "sig", Alg: alg, Crv: k.Curve.Params().Name, X: b64(k.X.FillBytes(make([]byte, size))), Y: b64(k.Y.FillBytes(make([]byte, size)))}, true
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Kid: kid, Use: "sig", Alg: alg, Crv: "Ed25519", X: b64(k)}, true
+	}
+	return JWK{}, false
+}
+
+// thumbprint derives a stable key ID from the public key as described in RFC 7638,
+// so the same PEM file always yields the same kid.
+func thumbprint(pub interface{}) (string, error) {
+	jwk, ok := publicJWK("", "", pub)
+	if !ok {
+		return "", fmt.Errorf("cannot compute thumbprint for key type %T", pub)
+	}
+
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	encoded, err := json.Marshal(members)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode key thumbprint: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+const opaqueTokenBytes = 32
+
+// GenerateOpaqueToken returns a random URL-safe token for single-use links together
+// with the hash that should be persisted in its place.
+func GenerateOpaqueToken() (string, string, error) {
+	secret := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	return token, HashToken(token), nil
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const refreshTokenSecretBytes = 32
+
+var ErrMalformedRefreshToken = errors.New("malformed refresh token")
+
+// GenerateRefreshToken returns an opaque refresh token bound to sessionID together
+// with the hash that should be persisted. The raw token is never stored.
+func GenerateRefreshToken(sessionID string) (string, string, error) {
+	secret := make([]byte, refreshTokenSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	token := sessionID + "." + base64.RawURLEncoding.EncodeToString(secret)
+	return token, HashToken(token), nil
+}
+
+// ParseRefreshToken extracts the session ID a refresh token was issued for.
+func ParseRefreshToken(token string) (string, error) {
+	sessionID, secret, found := strings.Cut(token, ".")
+	if !found || sessionID == "" || secret == "" {
+		return "", ErrMalformedRefreshToken
+	}
+	return sessionID, nil
+}
+
+// HashToken returns the hex encoded SHA-256 digest of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateRefreshToken(t *testing.T) {
+	token, hash, err := GenerateRefreshToken("session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != HashToken(token) {
+		t.Error("returned hash is not the hash of the token")
+	}
+	sessionID, err := ParseRefreshToken(token)
+	if err != nil {
+		t.Fatalf("ParseRefreshToken: %v", err)
+	}
+	if sessionID != "session-1" {
+		t.Errorf("session ID = %q, want %q", sessionID, "session-1")
+	}
+
+	other, _, err := GenerateRefreshToken("session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == token {
+		t.Error("two refresh tokens for the same session are equal")
+	}
+}
+
+func TestParseRefreshTokenRejectsMalformedTokens(t *testing.T) {
+	for _, token := range []string{"", "session-1", "session-1.", ".secret"} {
+		if _, err := ParseRefreshToken(token); !errors.Is(err, ErrMalformedRefreshToken) {
+			t.Errorf("ParseRefreshToken(%q) error = %v, want ErrMalformedRefreshToken", token, err)
+		}
+	}
+}
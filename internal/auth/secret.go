@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// SecretBox encrypts small secrets, such as TOTP seeds, that must be stored in the
+// database but read back in clear text.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox derives an AES-256-GCM key from passphrase.
+func NewSecretBox(passphrase string) (*SecretBox, error) {
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+func (b *SecretBox) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *SecretBox) Decrypt(encoded string) (string, error) {
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	if len(sealed) < b.aead.NonceSize() {
+		return "", errors.New("encrypted secret is too short")
+	}
+	nonce, ciphertext := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
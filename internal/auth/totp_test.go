@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890",
+// in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestTOTPCodeRFC6238 checks the SHA1 vectors of RFC 6238 appendix B, truncated to the
+// six digits used here.
+func TestTOTPCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		if got := totpCode([]byte("12345678901234567890"), uint64(tt.unix/totpPeriod)); got != tt.code {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+		step, ok := ValidateTOTP(rfc6238Secret, tt.code, now)
+		if !ok {
+			t.Errorf("ValidateTOTP rejected %s at %d", tt.code, tt.unix)
+			continue
+		}
+		if want := tt.unix / totpPeriod; step != want {
+			t.Errorf("ValidateTOTP at %d returned step %d, want %d", tt.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTPClockDrift(t *testing.T) {
+	// The RFC 6238 code for 1111111111, checked one and two steps either side.
+	const code = "050471"
+	generated := time.Unix(1111111111, 0)
+	tests := []struct {
+		name  string
+		now   time.Time
+		valid bool
+	}{
+		{"same step", generated, true},
+		{"one step behind", generated.Add(-totpPeriod * time.Second), true},
+		{"one step ahead", generated.Add(totpPeriod * time.Second), true},
+		{"two steps behind", generated.Add(-2 * totpPeriod * time.Second), false},
+		{"two steps ahead", generated.Add(2 * totpPeriod * time.Second), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, code, tt.now)
+			if ok != tt.valid {
+				t.Fatalf("ValidateTOTP = %v, want %v", ok, tt.valid)
+			}
+			if ok && step != generated.Unix()/totpPeriod {
+				t.Errorf("step = %d, want the step the code was generated in", step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPRejectsMalformedInput(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"wrong code", rfc6238Secret, "050472"},
+		{"eight digits", rfc6238Secret, "14050471"},
+		{"empty code", rfc6238Secret, ""},
+		{"invalid secret", "not base32!", "050471"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok {
+				t.Error("ValidateTOTP accepted the code")
+			}
+		})
+	}
+}
+
+func TestValidateTOTPNormalisesInput(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	if _, ok := ValidateTOTP("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", " 050471 ", now); !ok {
+		t.Error("ValidateTOTP rejected a lowercase secret and a code with surrounding spaces")
+	}
+}
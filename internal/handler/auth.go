@@ -4,42 +4,66 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 
+	"external-backend-go/internal/auth"
 	"external-backend-go/internal/logger"
+	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/password"
 	"external-backend-go/internal/request"
 	"external-backend-go/internal/service"
 	"external-backend-go/internal/utility"
 )
 
+// LoginResponse carries the token pair of a completed login. When the user has
+// two-factor authentication enabled, a password login only sets MFARequired and
+// MFAToken, which must be exchanged at /login/2fa. When the client asked for cookie
+// delivery, the tokens are set as cookies instead and CSRFToken is the value to send
+// in the X-CSRF-Token header.
 type LoginResponse struct {
-	Token string `json:"token"`
-	Role  string `json:"role"`
+	Token        string   `json:"token,omitempty"`
+	RefreshToken string   `json:"refreshToken,omitempty"`
+	ExpiresIn    int64    `json:"expiresIn"`
+	Role         string   `json:"role,omitempty"`
+	Roles        []string `json:"roles,omitempty"`
+	MFARequired  bool     `json:"mfaRequired,omitempty"`
+	MFAToken     string   `json:"mfaToken,omitempty"`
+	CSRFToken    string   `json:"csrfToken,omitempty"`
 }
 
 type AuthHandler struct {
 	AuthService *service.AuthService
 	Logger      *logger.Logger
 	Validator   *validator.Validate
+	Cookies     CookieSettings
 }
 
-func NewAuthHandler(authService *service.AuthService, logger *logger.Logger, validator *validator.Validate) *AuthHandler {
-	return &AuthHandler{AuthService: authService, Logger: logger, Validator: validator}
+// PasswordPolicyErrorResponse is returned when a new password breaks the password
+// policy, with one entry per broken rule.
+type PasswordPolicyErrorResponse struct {
+	Message    string               `json:"message"`
+	Violations []password.Violation `json:"violations"`
+}
+
+func NewAuthHandler(authService *service.AuthService, logger *logger.Logger, validator *validator.Validate, cookies CookieSettings) *AuthHandler {
+	return &AuthHandler{AuthService: authService, Logger: logger, Validator: validator, Cookies: cookies}
 }
 
 // @Summary Register new user
-// @Description Creates a new user account with username, password, and email. Defaults to 'user' role.
+// @Description Creates a new user account with username, password, and email. Defaults to 'user' role. The password has to satisfy the password policy; the rules it breaks are listed in violations.
 // @Tags authentication
 // @Accept json
 // @Produce json
 // @Param request body request.RegisterUserRequest true "User registration details"
-// @Success 201 {object} map[string]string "message: Registration successful!"
-// @Failure 400 {object} map[string]string "message: Invalid request data"
+// @Success 201 {object} map[string]string "message: Registration successful! Please check your email to verify your account."
+// @Failure 400 {object} PasswordPolicyErrorResponse "message: Invalid request data / Validation failed / Password does not meet the requirements"
 // @Failure 500 {object} map[string]string "message: Could not register user. Username or email might already exist."
 // @Router /register [post]
 func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
@@ -60,7 +84,10 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 
 	err := h.AuthService.RegisterUser(r.Context(), req.Username, req.Password, req.Email, "admin")
 	if err != nil {
-		if strings.Contains(err.Error(), "duplicate key value") {
+		var policyErr *password.PolicyError
+		if errors.As(err, &policyErr) {
+			h.passwordPolicyResponse(w, r, policyErr)
+		} else if strings.Contains(err.Error(), "duplicate key value") {
 			utility.InternalServerError(w, r, fmt.Errorf("Username or email already exists"), h.Logger)
 		} else {
 			utility.InternalServerError(w, r, err, h.Logger)
@@ -68,18 +95,21 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utility.JSONResponse(w, http.StatusCreated, map[string]string{"message": "Registration successful!"})
+	utility.JSONResponse(w, http.StatusCreated, map[string]string{"message": "Registration successful! Please check your email to verify your account."})
 }
 
 // @Summary Login user
-// @Description Logs in a user and returns a JWT token.
+// @Description Logs in a user and returns a short-lived JWT access token together with a refresh token. If two-factor authentication is enabled, only an mfaToken is returned and the login must be completed at /login/2fa. With remember_me, the completed login also sets an HttpOnly remember_me cookie, specific to this device, that /login/remember accepts in place of the password. When cookie auth is enabled and the request carries the header X-Auth-Mode: cookie, the tokens are set as HttpOnly cookies instead of being returned, and csrfToken has to be sent in the X-CSRF-Token header of state-changing requests.
 // @Tags authentication
 // @Accept json
 // @Produce json
 // @Param request body request.LoginUserRequest true "User login credentials"
+// @Param X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
 // @Success 200 {object} LoginResponse "Successful login"
 // @Failure 400 {object} map[string]string "message: Invalid request data"
 // @Failure 401 {object} map[string]string "message: Invalid username or password"
+// @Failure 403 {object} map[string]string "message: Email address is not verified"
+// @Failure 429 {object} map[string]string "message: Too many failed login attempts"
 // @Failure 500 {object} map[string]string "message: Internal server error"
 // @Router /login [post]
 func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
@@ -98,9 +128,14 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, role, err := h.AuthService.LoginUser(r.Context(), req.Username, req.Password, r.RemoteAddr, r.UserAgent())
+	tokens, err := h.AuthService.LoginUser(r.Context(), req.Username, req.Password, req.RememberMe, r.RemoteAddr, r.UserAgent())
 	if err != nil {
-		if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrIncorrectPassword) {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			loginThrottledResponse(w, r, throttled, h.Logger)
+		} else if errors.Is(err, service.ErrEmailNotVerified) {
+			utility.ErrorResponse(w, http.StatusForbidden, "Email address is not verified. Please check your inbox for the verification link.")
+		} else if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrIncorrectPassword) {
 			utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid username or password"), h.Logger)
 		} else {
 			utility.InternalServerError(w, r, err, h.Logger)
@@ -108,44 +143,264 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utility.JSONResponse(w, http.StatusOK, LoginResponse{Token: token, Role: role})
+	h.loginResponse(w, r, tokens, h.cookieAuthRequested(r))
+}
+
+// @Summary Refresh access token
+// @Description Exchanges a refresh token for a new access token and a new refresh token. The presented refresh token is invalidated; reusing it revokes the whole session. When cookie auth is enabled the refresh token is read from the refresh_token cookie when there is one; the body can then be omitted, the X-CSRF-Token header is required and the new tokens are set as cookies again. A refresh token sent in the body gets the new tokens in the body unless X-Auth-Mode: cookie is sent.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.RefreshTokenRequest false "Refresh token"
+// @Param X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
+// @Success 200 {object} LoginResponse "New token pair"
+// @Failure 400 {object} map[string]string "message: Invalid request data"
+// @Failure 401 {object} map[string]string "message: Invalid or expired refresh token"
+// @Failure 403 {object} map[string]string "message: Invalid or missing CSRF token"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /token/refresh [post]
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var refreshToken string
+	cookie, err := r.Cookie(refreshTokenCookieName)
+	fromCookie := h.Cookies.AuthEnabled && err == nil
+	if fromCookie {
+		if !middleware.ValidCSRFToken(r) {
+			h.Logger.Warn("Cookie refresh without a valid CSRF token rejected for %s", r.RemoteAddr)
+			utility.ErrorResponse(w, http.StatusForbidden, "Invalid or missing CSRF token.")
+			return
+		}
+		refreshToken = cookie.Value
+	} else {
+		var req request.RefreshTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+			return
+		}
+
+		if err := req.Validate(h.Validator); err != nil {
+			if ve, ok := err.(validator.ValidationErrors); ok {
+				utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+				return
+			}
+			utility.BadRequestResponse(w, r, err, h.Logger)
+			return
+		}
+		refreshToken = req.RefreshToken
+	}
+
+	tokens, err := h.AuthService.RefreshToken(r.Context(), refreshToken, r.RemoteAddr, r.UserAgent())
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			if fromCookie {
+				h.clearAuthCookies(w)
+			}
+			utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid or expired refresh token"), h.Logger)
+		} else {
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	h.loginResponse(w, r, tokens, fromCookie || h.cookieAuthRequested(r))
+}
+
+// loginThrottledResponse answers with 429 and a Retry-After header. The same response
+// is used for existing and unknown usernames, and for wrong current passwords.
+func loginThrottledResponse(w http.ResponseWriter, r *http.Request, throttled *service.LoginThrottledError, appLogger *logger.Logger) {
+	retryAfter := int64(math.Ceil(throttled.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	appLogger.Warn("Login throttled for %s, retry after %ds", r.RemoteAddr, retryAfter)
+	utility.ErrorResponse(w, http.StatusTooManyRequests, "Too many failed login attempts. Please try again later.")
+}
+
+// passwordPolicyResponse answers with 400 and the rules the password breaks.
+func (h *AuthHandler) passwordPolicyResponse(w http.ResponseWriter, r *http.Request, policyErr *password.PolicyError) {
+	h.Logger.Warn("Bad request: %v", policyErr)
+	utility.JSONResponse(w, http.StatusBadRequest, PasswordPolicyErrorResponse{
+		Message:    "Password does not meet the requirements",
+		Violations: policyErr.Violations,
+	})
+}
+
+// loginResponse answers a completed login or refresh. It sets the remember-me cookie
+// when a remember-me token was issued and, with useCookies, moves the tokens from the
+// body into cookies.
+func (h *AuthHandler) loginResponse(w http.ResponseWriter, r *http.Request, tokens *service.AuthTokens, useCookies bool) {
+	if tokens.RememberToken != "" {
+		h.setCookie(w, rememberMeCookieName, tokens.RememberToken, rememberMeCookiePath, tokens.RememberExpiresAt, true)
+	}
+
+	response := newLoginResponse(tokens)
+	if useCookies && tokens.AccessToken != "" {
+		csrfToken, _, err := auth.GenerateOpaqueToken()
+		if err != nil {
+			utility.InternalServerError(w, r, fmt.Errorf("failed to generate CSRF token: %w", err), h.Logger)
+			return
+		}
+		h.setAuthCookies(w, tokens.AccessToken, tokens.RefreshToken, csrfToken, time.Now().Add(time.Duration(tokens.ExpiresIn)*time.Second))
+		response.Token = ""
+		response.RefreshToken = ""
+		response.CSRFToken = csrfToken
+	}
+	utility.JSONResponse(w, http.StatusOK, response)
+}
+
+func newLoginResponse(tokens *service.AuthTokens) LoginResponse {
+	return LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		Role:         tokens.Role,
+		Roles:        tokens.Roles,
+		MFARequired:  tokens.MFARequired,
+		MFAToken:     tokens.MFAToken,
+	}
 }
 
 // @Summary Verify user email
-// @Description Verifies a user's email address using a provided token.
+// @Description Verifies a user's email address using the single-use token sent by email.
 // @Tags authentication
 // @Accept json
 // @Produce json
-// @Param id path string true "User ID"
 // @Param token query string true "Verification token"
 // @Success 200 {object} map[string]string "message: Email verified successfully!"
-// @Failure 400 {object} map[string]string "message: Invalid verification link or token"
+// @Failure 400 {object} map[string]string "message: Invalid verification link or token / Email already verified"
 // @Failure 500 {object} map[string]string "message: Internal server error"
 // @Router /verify-email [get]
 func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	userIDStr := vars["id"]
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid User ID format"), h.Logger)
-		return
-	}
-
 	token := r.URL.Query().Get("token")
 	if token == "" {
 		utility.BadRequestResponse(w, r, fmt.Errorf("Verification token is missing"), h.Logger)
 		return
 	}
 
-	err = h.AuthService.VerifyEmail(r.Context(), int32(userID), token)
+	err := h.AuthService.VerifyEmail(r.Context(), token)
 	if err != nil {
-		utility.InternalServerError(w, r, err, h.Logger)
+		if errors.Is(err, service.ErrInvalidToken) {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Invalid or expired verification token"), h.Logger)
+		} else if errors.Is(err, service.ErrEmailAlreadyVerified) {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Email already verified"), h.Logger)
+		} else {
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
 		return
 	}
 
 	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Email verified successfully!"})
 }
 
+// @Summary Resend verification email
+// @Description Sends a new email verification link and invalidates earlier ones. The response is the same whether or not the address belongs to an unverified account.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.ResendVerificationEmailRequest true "Email address"
+// @Success 200 {object} map[string]string "message: If an unverified account exists for this email, a verification email has been sent."
+// @Failure 400 {object} map[string]string "message: Invalid request data"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /verify-email/resend [post]
+func (h *AuthHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	var req request.ResendVerificationEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	if err := h.AuthService.ResendVerificationEmail(r.Context(), req.Email); err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "If an unverified account exists for this email, a verification email has been sent."})
+}
+
+// @Summary Logout
+// @Description Revokes the session the current access token belongs to, together with its refresh token, and the remember-me cookie of this device. Other remembered devices are not affected.
+// @Tags authentication
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} map[string]string "message: Logged out successfully."
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), h.Logger)
+		return
+	}
+
+	if err := h.AuthService.Logout(r.Context(), claims.UserID, claims.SessionID); err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+	h.clearRememberMeCookie(w)
+	h.clearAuthCookies(w)
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Logged out successfully."})
+}
+
+// @Summary Logout from all devices
+// @Description Revokes every session of the current user, including the one making the request, and forgets every remembered device.
+// @Tags authentication
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} map[string]string "message: Logged out from all sessions."
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /logout-all [post]
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.AuthService.LogoutAll(r.Context(), userID); err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+	h.clearRememberMeCookie(w)
+	h.clearAuthCookies(w)
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Logged out from all sessions."})
+}
+
+// currentClaims reads the token claims from the request context, writing a 401 when
+// they are missing.
+func (h *AuthHandler) currentClaims(w http.ResponseWriter, r *http.Request) (*auth.Claims, bool) {
+	claims, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), h.Logger)
+		return nil, false
+	}
+	return claims, true
+}
+
+// currentUserID reads the user ID from the token claims, writing a 401 when it is missing.
+func (h *AuthHandler) currentUserID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	claims, ok := h.currentClaims(w, r)
+	if !ok {
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
+// JWKS publishes the public keys used to sign access tokens so other services can
+// verify them. It is served at /.well-known/jwks.json, outside the /api/v1 base path.
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utility.JSONResponse(w, http.StatusOK, h.AuthService.Tokens.Keys.JWKS())
+}
+
 // @Summary Protected Endpoint
 // @Description This is a sample protected endpoint accessible only with a valid JWT.
 // @Tags example
@@ -160,25 +415,23 @@ func (h *AuthHandler) ProtectedEndpoint(w http.ResponseWriter, r *http.Request)
 }
 
 // @Summary Request password reset
-// @Description Sends a password reset email to the user.
+// @Description Sends a password reset email to the user. The response is the same whether or not the address belongs to an account.
 // @Tags authentication
 // @Accept json
 // @Produce json
-// @Param request body map[string]string true "email: User's email address"
-// @Success 200 {object} map[string]string "message: Password reset email sent."
-// @Failure 400 {object} map[string]string "message: Invalid email format / Email not found"
+// @Param request body request.ForgotPasswordRequest true "User's email address"
+// @Success 200 {object} map[string]string "message: If an account exists for this email, a password reset link has been sent."
+// @Failure 400 {object} map[string]string "message: Invalid email format"
 // @Failure 500 {object} map[string]string "message: Failed to send password reset email."
 // @Router /forgot-password [post]
 func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Email string `json:"email" validate:"required,email"`
-	}
+	var req request.ForgotPasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
 		return
 	}
 
-	if err := h.Validator.Struct(&req); err != nil {
+	if err := req.Validate(h.Validator); err != nil {
 		if ve, ok := err.(validator.ValidationErrors); ok {
 			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
 			return
@@ -192,17 +445,17 @@ func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Password reset email sent."})
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "If an account exists for this email, a password reset link has been sent."})
 }
 
 // @Summary Reset password
-// @Description Resets user's password using a valid token.
+// @Description Resets user's password using a valid single-use token and signs the user out on all devices. The new password has to satisfy the password policy; the rules it breaks are listed in violations and the token can be used again.
 // @Tags authentication
 // @Accept json
 // @Produce json
-// @Param request body map[string]string true "email: User's email, token: Reset token, new_password: New password"
+// @Param request body request.PasswordResetRequest true "Email, reset token and new password"
 // @Success 200 {object} map[string]string "message: Password reset successfully!"
-// @Failure 400 {object} map[string]string "message: Invalid request data / Invalid or expired token / Passwords do not match criteria"
+// @Failure 400 {object} PasswordPolicyErrorResponse "message: Invalid request data / Invalid or expired token / Password does not meet the requirements"
 // @Failure 500 {object} map[string]string "message: Internal server error"
 // @Router /reset-password [post]
 func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
@@ -223,7 +476,14 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 
 	err := h.AuthService.ResetPassword(r.Context(), req.Email, req.Token, req.NewPassword)
 	if err != nil {
-		utility.InternalServerError(w, r, err, h.Logger)
+		var policyErr *password.PolicyError
+		if errors.As(err, &policyErr) {
+			h.passwordPolicyResponse(w, r, policyErr)
+		} else if errors.Is(err, service.ErrInvalidToken) {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Invalid or expired token"), h.Logger)
+		} else {
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
 		return
 	}
 
@@ -231,7 +491,7 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Update user role (Admin only)
-// @Description Updates the role of a specific user. Requires JWT authentication and 'admin' role.
+// @Description Updates the primary role of a specific user, replacing the previous primary role in the user's role list. The user's current access tokens stop being accepted and have to be refreshed to pick up the new role. Requires JWT authentication and 'users:write' permission.
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -239,7 +499,7 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "User ID"
 // @Param request body request.UpdateUserRoleRequest true "New role for the user"
 // @Success 200 {object} model.User "Updated user details"
-// @Failure 400 {object} map[string]string "message: Invalid request data / Invalid User ID format"
+// @Failure 400 {object} map[string]string "message: Invalid request data / Invalid User ID format / Role does not exist"
 // @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
 // @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
 // @Failure 404 {object} map[string]string "message: User not found"
@@ -271,8 +531,10 @@ func (h *AuthHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
 
 	updatedUser, err := h.AuthService.UpdateUserRole(r.Context(), int32(userID), req.RoleName)
 	if err != nil {
-		if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrInvalidRoleName) {
+		if errors.Is(err, service.ErrUserNotFound) {
 			utility.NotFoundResponse(w, r, h.Logger)
+		} else if errors.Is(err, service.ErrInvalidRoleName) {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Role %q does not exist", req.RoleName), h.Logger)
 		} else {
 			utility.InternalServerError(w, r, err, h.Logger)
 		}
@@ -287,20 +549,53 @@ func (h *AuthHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := map[string]interface{}{
-		"id":                updatedUser.ID,
-		"username":          updatedUser.Username,
-		"email":             updatedUser.Email,
-		"emailVerifiedAt":   updatedUser.EmailVerifiedAt,
-		"roleId":            updatedUser.RoleID,
-		"roleName":          role.Name,
-		"rememberTokenUuid": updatedUser.RememberTokenUuid,
-		"createdAt":         updatedUser.CreatedAt,
-		"updatedAt":         updatedUser.UpdatedAt,
-		"deletedAt":         updatedUser.DeletedAt,
+		"id":              updatedUser.ID,
+		"username":        updatedUser.Username,
+		"email":           updatedUser.Email,
+		"emailVerifiedAt": updatedUser.EmailVerifiedAt,
+		"roleId":          updatedUser.RoleID,
+		"roleName":        role.Name,
+		"createdAt":       updatedUser.CreatedAt,
+		"updatedAt":       updatedUser.UpdatedAt,
+		"deletedAt":       updatedUser.DeletedAt,
 	}
 	utility.JSONResponse(w, http.StatusOK, response)
 }
 
+// @Summary Unlock user account
+// @Description Clears the failed login attempts of a user, lifting any login backoff or lockout. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string "message: Account unlocked."
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/unlock [post]
+func (h *AuthHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userIDStr := vars["id"]
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid User ID format"), h.Logger)
+		return
+	}
+
+	if err := h.AuthService.UnlockAccount(r.Context(), int32(userID)); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			utility.NotFoundResponse(w, r, h.Logger)
+		} else {
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Account unlocked."})
+}
+
 // @Summary Protected with Basic Auth Endpoint
 // @Description This is a sample protected endpoint accessible only with Basic Authentication.
 // @Tags example
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"external-backend-go/internal/middleware"
+)
+
+const (
+	apiBasePath = "/api/v1"
+
+	rememberMeCookieName = "remember_me"
+	// rememberMeCookiePath keeps browsers from sending the cookie anywhere but the
+	// endpoint that redeems it.
+	rememberMeCookiePath = apiBasePath + "/login/remember"
+
+	refreshTokenCookieName = "refresh_token"
+	// refreshTokenCookiePath limits the refresh token to the refresh endpoint, the
+	// same way the remember-me cookie is limited.
+	refreshTokenCookiePath = apiBasePath + "/token/refresh"
+
+	// authModeHeaderName is the header a client sends with the value authModeCookie to
+	// have the tokens of that login or refresh delivered as cookies.
+	authModeHeaderName = "X-Auth-Mode"
+	authModeCookie     = "cookie"
+)
+
+// CookieSettings are the attributes of cookies set by the API.
+type CookieSettings struct {
+	// Secure restricts cookies to HTTPS. It should only be off for local development.
+	Secure bool
+	// Domain is left empty to scope cookies to the API host.
+	Domain   string
+	SameSite http.SameSite
+	// AuthEnabled lets browser clients that should not keep tokens in script-accessible
+	// storage receive the access and refresh tokens as HttpOnly cookies, together with a
+	// CSRF cookie. A client asks for it on each login or refresh with the X-Auth-Mode
+	// header; other clients keep receiving the tokens in the body.
+	AuthEnabled bool
+}
+
+// cookieAuthRequested reports whether r asks for its tokens to be delivered as cookies
+// and cookie auth is enabled.
+func (h *AuthHandler) cookieAuthRequested(r *http.Request) bool {
+	return h.Cookies.AuthEnabled && strings.EqualFold(r.Header.Get(authModeHeaderName), authModeCookie)
+}
+
+// setCookie sets a cookie with the configured attributes that expires at expiresAt.
+func (h *AuthHandler) setCookie(w http.ResponseWriter, name, value, path string, expiresAt time.Time, httpOnly bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   h.Cookies.Domain,
+		Expires:  expiresAt,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		Secure:   h.Cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: h.Cookies.SameSite,
+	})
+}
+
+// expireCookie tells the browser to drop a cookie set by setCookie.
+func (h *AuthHandler) expireCookie(w http.ResponseWriter, name, path string, httpOnly bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     path,
+		Domain:   h.Cookies.Domain,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		Secure:   h.Cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: h.Cookies.SameSite,
+	})
+}
+
+func (h *AuthHandler) clearRememberMeCookie(w http.ResponseWriter) {
+	h.expireCookie(w, rememberMeCookieName, rememberMeCookiePath, true)
+}
+
+// setAuthCookies sets the access token, refresh token and CSRF cookies of cookie
+// auth mode. The CSRF cookie lives as long as the refresh token, so it is still there
+// when the access token is refreshed.
+func (h *AuthHandler) setAuthCookies(w http.ResponseWriter, accessToken, refreshToken, csrfToken string, accessExpiresAt time.Time) {
+	refreshExpiresAt := time.Now().Add(h.AuthService.Settings.RefreshTokenTTL)
+	h.setCookie(w, middleware.AccessTokenCookieName, accessToken, apiBasePath, accessExpiresAt, true)
+	h.setCookie(w, refreshTokenCookieName, refreshToken, refreshTokenCookiePath, refreshExpiresAt, true)
+	h.setCookie(w, middleware.CSRFCookieName, csrfToken, "/", refreshExpiresAt, false)
+}
+
+func (h *AuthHandler) clearAuthCookies(w http.ResponseWriter) {
+	h.expireCookie(w, middleware.AccessTokenCookieName, apiBasePath, true)
+	h.expireCookie(w, refreshTokenCookieName, refreshTokenCookiePath, true)
+	h.expireCookie(w, middleware.CSRFCookieName, "/", false)
+}
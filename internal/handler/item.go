@@ -10,6 +10,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"external-backend-go/internal/logger"
+	"external-backend-go/internal/middleware"
 	"external-backend-go/internal/request"
 	"external-backend-go/internal/service"
 	"external-backend-go/internal/utility"
@@ -17,16 +18,17 @@ import (
 
 type ItemHandler struct {
 	ItemService *service.ItemService
+	Policy      *middleware.Policy
 	Logger      *logger.Logger
 	Validator   *validator.Validate
 }
 
-func NewItemHandler(itemService *service.ItemService, logger *logger.Logger, validator *validator.Validate) *ItemHandler {
-	return &ItemHandler{ItemService: itemService, Logger: logger, Validator: validator}
+func NewItemHandler(itemService *service.ItemService, policy *middleware.Policy, logger *logger.Logger, validator *validator.Validate) *ItemHandler {
+	return &ItemHandler{ItemService: itemService, Policy: policy, Logger: logger, Validator: validator}
 }
 
 // @Summary Create a new item
-// @Description Creates a new item with a name and description. Requires JWT authentication and 'admin' role.
+// @Description Creates a new item with a name and description, owned by the authenticated user. Requires JWT authentication and 'items:write' permission on /admin/items or 'items:write_own' permission on /items.
 // @Tags items
 // @Accept json
 // @Produce json
@@ -38,7 +40,14 @@ func NewItemHandler(itemService *service.ItemService, logger *logger.Logger, val
 // @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
 // @Failure 500 {object} map[string]string "message: Internal server error"
 // @Router /admin/items [post]
+// @Router /items [post]
 func (h *ItemHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), h.Logger)
+		return
+	}
+
 	var req request.CreateItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
@@ -54,7 +63,7 @@ func (h *ItemHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdItem, err := h.ItemService.CreateItem(r.Context(), req.Name, req.Description)
+	createdItem, err := h.ItemService.CreateItem(r.Context(), req.Name, req.Description, claims.UserID)
 	if err != nil {
 		utility.InternalServerError(w, r, err, h.Logger)
 		return
@@ -99,7 +108,7 @@ func (h *ItemHandler) GetItem(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Update an existing item
-// @Description Updates an existing item's name and description. Requires JWT authentication and 'admin' role.
+// @Description Updates an existing item's name and description. Requires JWT authentication and 'items:write' permission.
 // @Tags items
 // @Accept json
 // @Produce json
@@ -151,7 +160,7 @@ func (h *ItemHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Delete an item
-// @Description Deletes an item by its ID. Requires JWT authentication and 'admin' role.
+// @Description Deletes an item by its ID. Requires JWT authentication and 'items:write' permission.
 // @Tags items
 // @Accept json
 // @Produce json
@@ -262,3 +271,76 @@ func (h *ItemHandler) SearchItems(w http.ResponseWriter, r *http.Request) {
 
 	utility.JSONResponse(w, http.StatusOK, results)
 }
+
+// @Summary Update an own item
+// @Description Updates an item owned by the authenticated user. Admins can update any item. Requires JWT authentication and 'items:write_own' permission.
+// @Tags items
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Item ID"
+// @Param request body request.UpdateItemRequest true "Item update details"
+// @Success 200 {object} model.Item "Updated item"
+// @Failure 400 {object} map[string]string "message: Invalid request data / Invalid item ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: Item not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /items/{id} [put]
+func (h *ItemHandler) UpdateOwnItem(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeItemOwner(w, r) {
+		return
+	}
+	h.UpdateItem(w, r)
+}
+
+// @Summary Delete an own item
+// @Description Deletes an item owned by the authenticated user. Admins can delete any item. Requires JWT authentication and 'items:write_own' permission.
+// @Tags items
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Item ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "message: Invalid item ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: Item not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /items/{id} [delete]
+func (h *ItemHandler) DeleteOwnItem(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeItemOwner(w, r) {
+		return
+	}
+	h.DeleteItem(w, r)
+}
+
+// authorizeItemOwner lets the request through when the authenticated user owns the
+// item in the path or is an admin, and writes the error response otherwise.
+func (h *ItemHandler) authorizeItemOwner(w http.ResponseWriter, r *http.Request) bool {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid item ID format"), h.Logger)
+		return false
+	}
+
+	item, err := h.ItemService.GetItemByID(r.Context(), int32(id))
+	if err != nil {
+		if err.Error() == "item not found" {
+			utility.NotFoundResponse(w, r, h.Logger)
+		} else {
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return false
+	}
+
+	allowed, err := h.Policy.OwnerOrAdmin(r.Context(), item.OwnerID)
+	if err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return false
+	}
+	if !allowed {
+		utility.ForbiddenResponse(w, r, h.Logger)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+
+	"external-backend-go/internal/request"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+// @Summary Request a magic login link
+// @Description Emails a one-time sign-in link to the account registered with the given address. The response is the same whether or not the address belongs to an account.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.MagicLinkRequest true "Email address"
+// @Success 200 {object} map[string]string "message: If an account exists for this email, a sign-in link has been sent."
+// @Failure 400 {object} map[string]string "message: Invalid request data"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /login/magic-link [post]
+func (h *AuthHandler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req request.MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	if err := h.AuthService.RequestMagicLink(r.Context(), req.Email); err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "If an account exists for this email, a sign-in link has been sent."})
+}
+
+// @Summary Log in with a magic link
+// @Description Exchanges the token from a magic link email for a JWT access token and refresh token, exactly like /login. Each link works once. If 2FA is enabled, mfaRequired is true and the returned mfaToken must be completed at /login/2fa.
+// @Tags authentication
+// @Produce json
+// @Param token query string true "Magic link token"
+// @Param X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
+// @Success 200 {object} LoginResponse "Successful login"
+// @Failure 400 {object} map[string]string "message: Magic link token is missing"
+// @Failure 401 {object} map[string]string "message: Invalid or expired magic link"
+// @Failure 403 {object} map[string]string "message: Email address is not verified"
+// @Failure 429 {object} map[string]string "message: Too many failed login attempts"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /login/magic-link/verify [get]
+func (h *AuthHandler) VerifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Magic link token is missing"), h.Logger)
+		return
+	}
+
+	tokens, err := h.AuthService.LoginWithMagicLink(r.Context(), token, r.RemoteAddr, r.UserAgent())
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			loginThrottledResponse(w, r, throttled, h.Logger)
+		case errors.Is(err, service.ErrInvalidToken):
+			utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid or expired magic link"), h.Logger)
+		case errors.Is(err, service.ErrEmailNotVerified):
+			utility.ErrorResponse(w, http.StatusForbidden, "Email address is not verified. Please check your inbox for the verification link.")
+		default:
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	h.loginResponse(w, r, tokens, h.cookieAuthRequested(r))
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+
+	"external-backend-go/internal/request"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// @Summary Complete two-factor login
+// @Description Exchanges the mfaToken returned by /login plus a TOTP code or an unused recovery code for a JWT access token and refresh token. If the login was started with remember_me, the remember_me cookie is set here.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body request.MFALoginRequest true "MFA token and second factor"
+// @Param X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
+// @Success 200 {object} LoginResponse "Successful login"
+// @Failure 400 {object} map[string]string "message: Invalid request data"
+// @Failure 401 {object} map[string]string "message: Invalid or expired MFA token / Invalid two-factor authentication code"
+// @Failure 429 {object} map[string]string "message: Too many failed login attempts"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /login/2fa [post]
+func (h *AuthHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req request.MFALoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	tokens, err := h.AuthService.CompleteMFALogin(r.Context(), req.MFAToken, req.Code, req.RecoveryCode, r.RemoteAddr, r.UserAgent())
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			loginThrottledResponse(w, r, throttled, h.Logger)
+		case errors.Is(err, service.ErrInvalidToken), errors.Is(err, service.ErrMFANotEnrolled):
+			utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid or expired MFA token"), h.Logger)
+		case errors.Is(err, service.ErrInvalidMFACode):
+			utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid two-factor authentication code"), h.Logger)
+		default:
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	h.loginResponse(w, r, tokens, h.cookieAuthRequested(r))
+}
+
+// @Summary Start two-factor enrollment
+// @Description Generates a new TOTP secret for the current user and returns it with an otpauth:// URI for authenticator apps. 2FA stays disabled until confirmed at /me/2fa/confirm.
+// @Tags authentication
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} MFAEnrollResponse "TOTP secret and provisioning URI"
+// @Failure 400 {object} map[string]string "message: Two-factor authentication is already enabled"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/2fa/enroll [post]
+func (h *AuthHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.AuthService.EnrollMFA(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrMFAAlreadyEnabled) {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Two-factor authentication is already enabled"), h.Logger)
+		} else if errors.Is(err, service.ErrUserNotFound) {
+			utility.NotFoundResponse(w, r, h.Logger)
+		} else {
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, MFAEnrollResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+// @Summary Confirm two-factor enrollment
+// @Description Enables two-factor authentication after verifying a code from the authenticator app. Returns one-time recovery codes that are shown only once.
+// @Tags authentication
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body request.MFAConfirmRequest true "TOTP code"
+// @Success 200 {object} MFARecoveryCodesResponse "Recovery codes"
+// @Failure 400 {object} map[string]string "message: Invalid request data / Invalid two-factor authentication code / Two-factor authentication is not enrolled"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/2fa/confirm [post]
+func (h *AuthHandler) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.MFAConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	codes, err := h.AuthService.ConfirmMFA(r.Context(), userID, req.Code)
+	if err != nil {
+		h.mfaErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Turns off two-factor authentication for the current user. Requires a current TOTP code or an unused recovery code. The user is signed out of every other session and other devices are no longer remembered; the access token of the session making the request has to be refreshed.
+// @Tags authentication
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body request.MFADisableRequest true "TOTP code or recovery code"
+// @Success 200 {object} map[string]string "message: Two-factor authentication disabled."
+// @Failure 400 {object} map[string]string "message: Invalid request data / Invalid two-factor authentication code / Two-factor authentication is not enrolled"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/2fa/disable [post]
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.currentClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.MFADisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	if err := h.AuthService.DisableMFA(r.Context(), claims.UserID, claims.SessionID, req.Code, req.RecoveryCode); err != nil {
+		h.mfaErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Two-factor authentication disabled."})
+}
+
+func (h *AuthHandler) mfaErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid two-factor authentication code"), h.Logger)
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		utility.BadRequestResponse(w, r, fmt.Errorf("Two-factor authentication is not enrolled"), h.Logger)
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		utility.BadRequestResponse(w, r, fmt.Errorf("Two-factor authentication is already enabled"), h.Logger)
+	default:
+		utility.InternalServerError(w, r, err, h.Logger)
+	}
+}
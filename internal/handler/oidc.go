@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+const (
+	// oidcStateCookieName binds a login started at a provider to the browser that
+	// started it, so an attacker cannot complete their own login in a victim's browser.
+	oidcStateCookieName = "oidc_state"
+	oidcStateCookiePath = apiBasePath + "/login/oidc"
+	// oidcAuthModeCookieName carries an X-Auth-Mode: cookie sent when the login was
+	// started to the callback, which the browser reaches through a redirect that cannot
+	// carry the header.
+	oidcAuthModeCookieName = "oidc_auth_mode"
+)
+
+// OIDCLoginResponse points the client at the identity provider.
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+}
+
+// @Summary Start a login with an OpenID Connect provider
+// @Description Returns the URL of the provider's login page to send the user to, and sets an oidc_state cookie that has to be present when the provider redirects back to the callback. With X-Auth-Mode: cookie, the callback delivers the tokens as cookies.
+// @Tags authentication
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param X-Auth-Mode header string false "Set to cookie to receive the tokens of the callback as cookies"
+// @Success 200 {object} OIDCLoginResponse "Provider login URL"
+// @Failure 404 {object} map[string]string "message: Resource not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /login/oidc/{provider} [get]
+func (h *AuthHandler) StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.AuthService.StartOIDCLogin(r.Context(), mux.Vars(r)["provider"])
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCProviderNotFound) {
+			utility.NotFoundResponse(w, r, h.Logger)
+			return
+		}
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+
+	expiresAt := time.Now().Add(h.AuthService.Settings.OIDCAuthRequestTTL)
+	h.setOIDCCookie(w, oidcStateCookieName, state, expiresAt)
+	if h.cookieAuthRequested(r) {
+		h.setOIDCCookie(w, oidcAuthModeCookieName, authModeCookie, expiresAt)
+	} else {
+		h.clearOIDCCookie(w, oidcAuthModeCookieName)
+	}
+	utility.JSONResponse(w, http.StatusOK, OIDCLoginResponse{AuthorizationURL: authURL})
+}
+
+// @Summary Complete a login with an OpenID Connect provider
+// @Description Redeems the code the provider redirected back with for a JWT access token and refresh token, exactly like /login. The identity is linked to the account with the same verified email on first use, or a new account is created if the provider is configured to provision them. If 2FA is enabled, mfaRequired is true and the returned mfaToken must be completed at /login/2fa.
+// @Tags authentication
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State from the authorization request"
+// @Success 200 {object} LoginResponse "Successful login"
+// @Failure 400 {object} map[string]string "message: Authorization code or state is missing"
+// @Failure 401 {object} map[string]string "message: Invalid or expired login state / Login with the identity provider failed"
+// @Failure 403 {object} map[string]string "message: No account is linked to this identity / Email address is not verified"
+// @Failure 404 {object} map[string]string "message: Resource not found"
+// @Failure 409 {object} map[string]string "message: An account with this email already exists"
+// @Failure 429 {object} map[string]string "message: Too many failed login attempts"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /login/oidc/{provider}/callback [get]
+func (h *AuthHandler) CompleteOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	providerName := mux.Vars(r)["provider"]
+	query := r.URL.Query()
+
+	// Every callback ends the login attempt, whatever its outcome.
+	h.clearOIDCCookie(w, oidcStateCookieName)
+	h.clearOIDCCookie(w, oidcAuthModeCookieName)
+
+	if providerError := query.Get("error"); providerError != "" {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Login was rejected by the identity provider"), h.Logger)
+		return
+	}
+	code, state := query.Get("code"), query.Get("state")
+	if code == "" || state == "" {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Authorization code or state is missing"), h.Logger)
+		return
+	}
+	cookie, err := r.Cookie(oidcStateCookieName)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid or expired login state"), h.Logger)
+		return
+	}
+
+	tokens, err := h.AuthService.CompleteOIDCLogin(r.Context(), providerName, code, state, r.RemoteAddr, r.UserAgent())
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			loginThrottledResponse(w, r, throttled, h.Logger)
+		case errors.Is(err, service.ErrOIDCProviderNotFound):
+			utility.NotFoundResponse(w, r, h.Logger)
+		case errors.Is(err, service.ErrInvalidToken):
+			utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid or expired login state"), h.Logger)
+		case errors.Is(err, service.ErrOIDCLoginFailed):
+			h.Logger.Warn("OIDC login with %s failed: %v", providerName, err)
+			utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Login with the identity provider failed"), h.Logger)
+		case errors.Is(err, service.ErrOIDCNoAccount):
+			utility.ErrorResponse(w, http.StatusForbidden, "No account is linked to this identity.")
+		case errors.Is(err, service.ErrOIDCEmailConflict), errors.Is(err, service.ErrUserAlreadyExists):
+			utility.ConflictResponse(w, r, fmt.Errorf("An account with this email already exists and cannot be linked automatically. Please log in with your password."), h.Logger)
+		case errors.Is(err, service.ErrEmailNotVerified):
+			utility.ErrorResponse(w, http.StatusForbidden, "Email address is not verified. Please check your inbox for the verification link.")
+		default:
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	authMode, err := r.Cookie(oidcAuthModeCookieName)
+	h.loginResponse(w, r, tokens, h.Cookies.AuthEnabled && err == nil && authMode.Value == authModeCookie)
+}
+
+// setOIDCCookie sets a cookie of a login in progress. Unlike the other cookies it is
+// always SameSite=Lax: the provider redirects back with a cross-site navigation, on
+// which a Strict cookie would not be sent.
+func (h *AuthHandler) setOIDCCookie(w http.ResponseWriter, name, value string, expiresAt time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     oidcStateCookiePath,
+		Domain:   h.Cookies.Domain,
+		Expires:  expiresAt,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		Secure:   h.Cookies.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *AuthHandler) clearOIDCCookie(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     oidcStateCookiePath,
+		Domain:   h.Cookies.Domain,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		Secure:   h.Cookies.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
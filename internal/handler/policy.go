@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/policy"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+type PolicyHandler struct {
+	Engine      *policy.Engine
+	RoleService *service.RoleService
+	Logger      *logger.Logger
+}
+
+func NewPolicyHandler(engine *policy.Engine, roleService *service.RoleService, logger *logger.Logger) *PolicyHandler {
+	return &PolicyHandler{Engine: engine, RoleService: roleService, Logger: logger}
+}
+
+// PolicyExplanation is the response of the explain endpoint.
+type PolicyExplanation struct {
+	Policy   policy.Info      `json:"policy"`
+	Request  ExplainedRequest `json:"request"`
+	Decision *policy.Decision `json:"decision"`
+}
+
+type ExplainedRequest struct {
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	IP     string    `json:"ip"`
+	Time   time.Time `json:"time"`
+	UserID int32     `json:"userId,omitempty"`
+}
+
+// @Summary Explain a policy decision
+// @Description Evaluates the access policy for a hypothetical request and shows every rule that matched it, with the outcome of each condition. By default the request is evaluated as the caller, from the caller's IP, now. With user_id it is evaluated as that user with their current roles; authentication methods are then unknown. Requires JWT authentication and 'policies:read' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param path query string true "Request path, e.g. /api/v1/admin/roles"
+// @Param method query string false "HTTP method (default GET)"
+// @Param ip query string false "Client IP address (default the caller's)"
+// @Param time query string false "Request time in RFC 3339 format (default now)"
+// @Param user_id query int false "Evaluate as this user instead of the caller"
+// @Success 200 {object} PolicyExplanation "Policy decision with its trace"
+// @Failure 400 {object} map[string]string "message: path is required / Invalid time / Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Failure 503 {object} map[string]string "message: Access policies are disabled"
+// @Router /admin/policies/explain [get]
+func (h *PolicyHandler) Explain(w http.ResponseWriter, r *http.Request) {
+	if h.Engine == nil {
+		utility.ErrorResponse(w, http.StatusServiceUnavailable, "Access policies are disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	in := middleware.PolicyInput(r)
+
+	in.Path = query.Get("path")
+	if in.Path == "" {
+		utility.BadRequestResponse(w, r, fmt.Errorf("path is required"), h.Logger)
+		return
+	}
+	in.Method = strings.ToUpper(query.Get("method"))
+	if in.Method == "" {
+		in.Method = http.MethodGet
+	}
+	if ip := query.Get("ip"); ip != "" {
+		in.IP = ip
+	}
+	if timeStr := query.Get("time"); timeStr != "" {
+		t, err := time.Parse(time.RFC3339, timeStr)
+		if err != nil {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Invalid time, expected RFC 3339 such as 2025-01-02T15:04:05Z"), h.Logger)
+			return
+		}
+		in.Time = t
+	}
+
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		userID, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Invalid User ID format"), h.Logger)
+			return
+		}
+		claims, err := h.claimsForUser(r, int32(userID))
+		if err != nil {
+			if errors.Is(err, service.ErrUserNotFound) {
+				utility.NotFoundResponse(w, r, h.Logger)
+			} else {
+				utility.InternalServerError(w, r, err, h.Logger)
+			}
+			return
+		}
+		in.Claims = claims
+	}
+
+	decision, err := h.Engine.Evaluate(r.Context(), in)
+	if err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+
+	explained := ExplainedRequest{Method: in.Method, Path: in.Path, IP: in.IP, Time: in.Time}
+	if in.Claims != nil {
+		explained.UserID = in.Claims.UserID
+	}
+	utility.JSONResponse(w, http.StatusOK, PolicyExplanation{
+		Policy:   h.Engine.Info(),
+		Request:  explained,
+		Decision: decision,
+	})
+}
+
+// claimsForUser builds the claims an access token of userID would carry right now.
+func (h *PolicyHandler) claimsForUser(r *http.Request, userID int32) (*auth.Claims, error) {
+	roles, err := h.RoleService.GetUserRoles(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	dbUser, err := h.RoleService.UserStore.GetUserByID(r.Context(), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	claims := &auth.Claims{
+		Username: dbUser.Username,
+		Role:     roles.PrimaryRole,
+		Roles:    roles.Roles,
+		UserID:   userID,
+	}
+	if !dbUser.EmailVerifiedAt.Valid {
+		claims.Scope = auth.ScopeUnverified
+	}
+	return claims, nil
+}
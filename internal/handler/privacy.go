@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/request"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+type PrivacyHandler struct {
+	PrivacyService *service.PrivacyService
+	Logger         *logger.Logger
+	Validator      *validator.Validate
+}
+
+func NewPrivacyHandler(privacyService *service.PrivacyService, logger *logger.Logger, validator *validator.Validate) *PrivacyHandler {
+	return &PrivacyHandler{PrivacyService: privacyService, Logger: logger, Validator: validator}
+}
+
+// DataExportResponse is the archive handed out by the export endpoints. Secrets such
+// as the password hash, TOTP seed and refresh tokens are left out.
+type DataExportResponse struct {
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Profile     *model.User       `json:"profile"`
+	Roles       []string          `json:"roles"`
+	MFA         *model.UserMFA    `json:"mfa"`
+	Sessions    []SessionResponse `json:"sessions"`
+	Items       []*model.Item     `json:"items"`
+}
+
+// ErasureResponse reports when a user's account is going to be erased.
+type ErasureResponse struct {
+	Message            string         `json:"message"`
+	ErasureScheduledAt model.NullTime `json:"erasureScheduledAt"`
+}
+
+// @Summary Export my data
+// @Description Downloads a JSON archive of everything stored about the current user: profile, roles, two-factor settings, sessions and owned items.
+// @Tags profile
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} DataExportResponse "Data export"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/export [post]
+func (h *PrivacyHandler) ExportMyData(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), h.Logger)
+		return
+	}
+	h.exportUserData(w, r, claims.UserID)
+}
+
+// @Summary Delete my account
+// @Description Schedules erasure of the current user's account after checking their password. Once the grace period is over, their items are deleted, their sessions and tokens removed and their personal data anonymized. Until then the erasure can be cancelled through DELETE /me/erasure.
+// @Tags profile
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body request.DeleteAccountRequest true "Current password"
+// @Success 202 {object} ErasureResponse "Erasure scheduled"
+// @Failure 400 {object} map[string]string "message: Invalid request data / Validation failed / Current password is incorrect"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 429 {object} map[string]string "message: Too many failed login attempts"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me [delete]
+func (h *PrivacyHandler) RequestMyErasure(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), h.Logger)
+		return
+	}
+
+	var req request.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	user, err := h.PrivacyService.RequestErasure(r.Context(), claims.UserID, req.CurrentPassword, r.RemoteAddr)
+	if err != nil {
+		h.privacyErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusAccepted, ErasureResponse{
+		Message:            "Your account is scheduled for erasure.",
+		ErasureScheduledAt: user.ErasureScheduledAt,
+	})
+}
+
+// @Summary Cancel my account deletion
+// @Description Cancels a pending erasure of the current user's account.
+// @Tags profile
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} map[string]string "message: Account erasure cancelled."
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 409 {object} map[string]string "message: No erasure is scheduled for this user"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/erasure [delete]
+func (h *PrivacyHandler) CancelMyErasure(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), h.Logger)
+		return
+	}
+
+	if _, err := h.PrivacyService.CancelErasure(r.Context(), claims.UserID); err != nil {
+		h.privacyErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Account erasure cancelled."})
+}
+
+// @Summary Export a user's data
+// @Description Downloads a JSON archive of everything stored about a user, for data subject access requests. Soft-deleted users can be exported as well. Requires JWT authentication and 'users:read' permission.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} DataExportResponse "Data export"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/export [post]
+func (h *PrivacyHandler) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+	h.exportUserData(w, r, id)
+}
+
+// @Summary Schedule erasure of a user
+// @Description Schedules erasure of a user's account and personal data after the grace period, for data subject erasure requests. The user is told by email and can still cancel it. Admins cannot erase their own account this way. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 202 {object} ErasureResponse "Erasure scheduled"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format / You cannot do this to your own account"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 409 {object} map[string]string "message: User has already been erased"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/erasure [post]
+func (h *PrivacyHandler) RequestUserErasure(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), h.Logger)
+		return
+	}
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.PrivacyService.RequestUserErasure(r.Context(), claims.UserID, id)
+	if err != nil {
+		h.privacyErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusAccepted, ErasureResponse{
+		Message:            "User is scheduled for erasure.",
+		ErasureScheduledAt: user.ErasureScheduledAt,
+	})
+}
+
+// @Summary Cancel erasure of a user
+// @Description Cancels a pending erasure of a user's account. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} model.User "User"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 409 {object} map[string]string "message: No erasure is scheduled for this user"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/erasure [delete]
+func (h *PrivacyHandler) CancelUserErasure(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.PrivacyService.CancelErasure(r.Context(), id)
+	if err != nil {
+		h.privacyErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, user)
+}
+
+// exportUserData sends the user's data as a file download.
+func (h *PrivacyHandler) exportUserData(w http.ResponseWriter, r *http.Request, userID int32) {
+	export, err := h.PrivacyService.ExportUserData(r.Context(), userID)
+	if err != nil {
+		h.privacyErrorResponse(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("user-%d-export-%s.json", userID, export.GeneratedAt.Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+	utility.JSONResponse(w, http.StatusOK, DataExportResponse{
+		GeneratedAt: export.GeneratedAt,
+		Profile:     export.Profile,
+		Roles:       export.Roles,
+		MFA:         export.MFA,
+		Sessions:    newSessionResponses(export.Sessions, ""),
+		Items:       export.Items,
+	})
+}
+
+func (h *PrivacyHandler) userID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid User ID format"), h.Logger)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func (h *PrivacyHandler) privacyErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		loginThrottledResponse(w, r, throttled, h.Logger)
+	case errors.Is(err, service.ErrUserNotFound):
+		utility.NotFoundResponse(w, r, h.Logger)
+	case errors.Is(err, service.ErrIncorrectCurrentPassword):
+		utility.BadRequestResponse(w, r, fmt.Errorf("Current password is incorrect"), h.Logger)
+	case errors.Is(err, service.ErrCannotManageSelf):
+		utility.BadRequestResponse(w, r, fmt.Errorf("You cannot do this to your own account"), h.Logger)
+	case errors.Is(err, service.ErrUserErased):
+		utility.ConflictResponse(w, r, fmt.Errorf("User has already been erased"), h.Logger)
+	case errors.Is(err, service.ErrErasureNotScheduled):
+		utility.ConflictResponse(w, r, fmt.Errorf("No erasure is scheduled for this user"), h.Logger)
+	default:
+		utility.InternalServerError(w, r, err, h.Logger)
+	}
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/password"
+	"external-backend-go/internal/request"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+// ProfileResponse is the current user's own account as returned under /me.
+type ProfileResponse struct {
+	ID              int32            `json:"id"`
+	Username        string           `json:"username"`
+	Email           string           `json:"email"`
+	EmailVerifiedAt model.NullTime   `json:"emailVerifiedAt"`
+	DisplayName     model.NullString `json:"displayName"`
+	AvatarURL       model.NullString `json:"avatarUrl"`
+	// ErasureScheduledAt is set while erasure of the account is pending.
+	ErasureScheduledAt model.NullTime `json:"erasureScheduledAt"`
+	Role               string         `json:"role"`
+	Roles              []string       `json:"roles"`
+	CreatedAt          time.Time      `json:"createdAt"`
+	UpdatedAt          time.Time      `json:"updatedAt"`
+}
+
+func newProfileResponse(user *model.User, claims *auth.Claims) ProfileResponse {
+	return ProfileResponse{
+		ID:                 user.ID,
+		Username:           user.Username,
+		Email:              user.Email,
+		EmailVerifiedAt:    user.EmailVerifiedAt,
+		DisplayName:        user.DisplayName,
+		AvatarURL:          user.AvatarURL,
+		ErasureScheduledAt: user.ErasureScheduledAt,
+		Role:               claims.Role,
+		Roles:              claims.Roles,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
+	}
+}
+
+// @Summary Get my profile
+// @Description Returns the account of the current user.
+// @Tags profile
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} ProfileResponse "Current user"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me [get]
+func (h *AuthHandler) GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.currentClaims(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.AuthService.GetProfile(r.Context(), claims.UserID)
+	if err != nil {
+		h.profileErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, newProfileResponse(user, claims))
+}
+
+// @Summary Update my profile
+// @Description Changes the username, display name or avatar URL of the current user. Fields left out are not changed; an empty display name or avatar URL clears it. After a username change the current access token has to be refreshed.
+// @Tags profile
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body request.UpdateProfileRequest true "Profile fields to change"
+// @Success 200 {object} ProfileResponse "Updated profile"
+// @Failure 400 {object} map[string]string "message: Invalid request data / Validation failed"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 409 {object} map[string]string "message: Username is already taken"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me [patch]
+func (h *AuthHandler) UpdateMyProfile(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.currentClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	user, err := h.AuthService.UpdateProfile(r.Context(), claims.UserID, service.ProfileUpdate{
+		Username:    req.Username,
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrUserAlreadyExists) {
+			utility.ConflictResponse(w, r, fmt.Errorf("Username is already taken"), h.Logger)
+			return
+		}
+		h.profileErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, newProfileResponse(user, claims))
+}
+
+// @Summary Change my password
+// @Description Sets a new password for the current user after checking the current one. The new password has to satisfy the password policy; the rules it breaks are listed in violations. The user is signed out of every other session and other devices are no longer remembered; the session making the request stays signed in.
+// @Tags profile
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body request.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} map[string]string "message: Password changed successfully."
+// @Failure 400 {object} PasswordPolicyErrorResponse "message: Invalid request data / Validation failed / Current password is incorrect / Password does not meet the requirements"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 429 {object} map[string]string "message: Too many failed login attempts"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/password [post]
+func (h *AuthHandler) ChangeMyPassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.currentClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	if err := h.AuthService.ChangePassword(r.Context(), claims.UserID, claims.SessionID, req.CurrentPassword, req.NewPassword, r.RemoteAddr); err != nil {
+		h.profileErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Password changed successfully."})
+}
+
+// @Summary Change my email address
+// @Description Starts changing the email address of the current user. A confirmation link is sent to the new address, and the account keeps its current address until the link is followed.
+// @Tags profile
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body request.ChangeEmailRequest true "Current password and new email address"
+// @Success 202 {object} map[string]string "message: A confirmation link has been sent to the new email address."
+// @Failure 400 {object} map[string]string "message: Invalid request data / Validation failed / Current password is incorrect / New email is the same as the current one"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 409 {object} map[string]string "message: Email is already taken"
+// @Failure 429 {object} map[string]string "message: Too many failed login attempts"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/email [post]
+func (h *AuthHandler) ChangeMyEmail(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.currentClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	if err := h.AuthService.RequestEmailChange(r.Context(), claims.UserID, req.CurrentPassword, req.NewEmail, r.RemoteAddr); err != nil {
+		h.profileErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusAccepted, map[string]string{"message": "A confirmation link has been sent to the new email address."})
+}
+
+// @Summary Confirm email change
+// @Description Completes an email change using the single-use token sent to the new address. The new address counts as verified.
+// @Tags profile
+// @Produce json
+// @Param token query string true "Email change token"
+// @Success 200 {object} map[string]string "message: Email address changed successfully!"
+// @Failure 400 {object} map[string]string "message: Email change token is missing / Invalid or expired email change token"
+// @Failure 409 {object} map[string]string "message: Email is already taken"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /confirm-email-change [get]
+func (h *AuthHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Email change token is missing"), h.Logger)
+		return
+	}
+
+	if err := h.AuthService.ConfirmEmailChange(r.Context(), token); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Invalid or expired email change token"), h.Logger)
+			return
+		}
+		h.profileErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Email address changed successfully!"})
+}
+
+func (h *AuthHandler) profileErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var policyErr *password.PolicyError
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &policyErr):
+		h.passwordPolicyResponse(w, r, policyErr)
+	case errors.As(err, &throttled):
+		loginThrottledResponse(w, r, throttled, h.Logger)
+	case errors.Is(err, service.ErrUserNotFound):
+		utility.NotFoundResponse(w, r, h.Logger)
+	case errors.Is(err, service.ErrIncorrectCurrentPassword):
+		utility.BadRequestResponse(w, r, fmt.Errorf("Current password is incorrect"), h.Logger)
+	case errors.Is(err, service.ErrEmailUnchanged):
+		utility.BadRequestResponse(w, r, fmt.Errorf("New email is the same as the current one"), h.Logger)
+	case errors.Is(err, service.ErrUserAlreadyExists):
+		utility.ConflictResponse(w, r, fmt.Errorf("Email is already taken"), h.Logger)
+	default:
+		utility.InternalServerError(w, r, err, h.Logger)
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+// @Summary Log in with the remember-me cookie
+// @Description Starts a new session using the remember_me cookie set by a login with remember_me. The cookie is rotated on every use; a cookie that is expired, revoked or already used is cleared and rejected. Logins made this way do not count as two-factor logins.
+// @Tags authentication
+// @Produce json
+// @Param X-Auth-Mode header string false "Set to cookie to receive the tokens as cookies"
+// @Success 200 {object} LoginResponse "Successful login"
+// @Failure 401 {object} map[string]string "message: Remember-me cookie is missing / Invalid or expired remember-me cookie"
+// @Failure 403 {object} map[string]string "message: Email address is not verified"
+// @Failure 429 {object} map[string]string "message: Too many failed login attempts"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /login/remember [post]
+func (h *AuthHandler) LoginWithRememberMe(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(rememberMeCookieName)
+	if err != nil {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Remember-me cookie is missing"), h.Logger)
+		return
+	}
+
+	tokens, err := h.AuthService.LoginWithRememberToken(r.Context(), cookie.Value, r.RemoteAddr, r.UserAgent())
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			loginThrottledResponse(w, r, throttled, h.Logger)
+		case errors.Is(err, service.ErrInvalidToken):
+			h.clearRememberMeCookie(w)
+			utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid or expired remember-me cookie"), h.Logger)
+		case errors.Is(err, service.ErrEmailNotVerified):
+			utility.ErrorResponse(w, http.StatusForbidden, "Email address is not verified. Please check your inbox for the verification link.")
+		default:
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	h.loginResponse(w, r, tokens, h.cookieAuthRequested(r))
+}
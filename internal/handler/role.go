@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/request"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+type RoleHandler struct {
+	RoleService *service.RoleService
+	Logger      *logger.Logger
+	Validator   *validator.Validate
+}
+
+func NewRoleHandler(roleService *service.RoleService, logger *logger.Logger, validator *validator.Validate) *RoleHandler {
+	return &RoleHandler{RoleService: roleService, Logger: logger, Validator: validator}
+}
+
+// @Summary Create a role
+// @Description Creates a new role, optionally inheriting from a parent role. Permissions are granted separately. Requires JWT authentication and 'roles:write' permission.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.CreateRoleRequest true "Role details"
+// @Success 201 {object} model.Role "Created role"
+// @Failure 400 {object} map[string]string "message: Invalid request data / Parent role does not exist"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 409 {object} map[string]string "message: Role with this name already exists"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var req request.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	createdRole, err := h.RoleService.CreateRole(r.Context(), req.Name, req.Description, req.Parent)
+	if err != nil {
+		h.roleErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusCreated, createdRole)
+}
+
+// @Summary Get role by ID
+// @Description Retrieves a single role. Requires JWT authentication and 'roles:read' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} model.Role "Role details"
+// @Failure 400 {object} map[string]string "message: Invalid role ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: Role not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/roles/{id} [get]
+func (h *RoleHandler) GetRole(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.roleID(w, r)
+	if !ok {
+		return
+	}
+
+	role, err := h.RoleService.GetRoleByID(r.Context(), id)
+	if err != nil {
+		h.roleErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, role)
+}
+
+// @Summary Update a role
+// @Description Renames a role, changes its description or sets the role it inherits from. An empty parent removes the parent. Requires JWT authentication and 'roles:write' permission.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Role ID"
+// @Param request body request.UpdateRoleRequest true "Role details"
+// @Success 200 {object} model.Role "Updated role"
+// @Failure 400 {object} map[string]string "message: Invalid request data / Invalid role ID format / Parent role does not exist"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: Role not found"
+// @Failure 409 {object} map[string]string "message: Role with this name already exists / Role hierarchy would contain a cycle / Built-in roles cannot be renamed or deleted"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.roleID(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	updatedRole, err := h.RoleService.UpdateRole(r.Context(), id, req.Name, req.Description, req.Parent)
+	if err != nil {
+		h.roleErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, updatedRole)
+}
+
+// @Summary Delete a role
+// @Description Deletes a role together with its permission grants. Roles that are still assigned to users cannot be deleted. Requires JWT authentication and 'roles:write' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Role ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "message: Invalid role ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: Role not found"
+// @Failure 409 {object} map[string]string "message: Role is still assigned to users / Built-in roles cannot be renamed or deleted"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.roleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.RoleService.DeleteRole(r.Context(), id); err != nil {
+		h.roleErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get list of roles
+// @Description Retrieves a paginated list of roles. Requires JWT authentication and 'roles:read' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "Page number (default 1)"
+// @Param pageSize query int false "Number of roles per page (default 10)"
+// @Success 200 {object} service.PaginatedRoles "Paginated list of roles"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/roles [get]
+func (h *RoleHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	pageStr := r.URL.Query().Get("page")
+	pageSizeStr := r.URL.Query().Get("pageSize")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	roles, err := h.RoleService.GetRoles(r.Context(), page, pageSize)
+	if err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, roles)
+}
+
+func (h *RoleHandler) roleID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid role ID format"), h.Logger)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func (h *RoleHandler) roleErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		utility.NotFoundResponse(w, r, h.Logger)
+	case errors.Is(err, service.ErrRoleAlreadyExists):
+		utility.ConflictResponse(w, r, fmt.Errorf("Role with this name already exists"), h.Logger)
+	case errors.Is(err, service.ErrParentRoleNotFound):
+		utility.BadRequestResponse(w, r, fmt.Errorf("Parent role does not exist"), h.Logger)
+	case errors.Is(err, service.ErrRoleCycle):
+		utility.ConflictResponse(w, r, fmt.Errorf("Role hierarchy would contain a cycle: a role cannot inherit from itself or one of its descendants"), h.Logger)
+	case errors.Is(err, service.ErrRoleInUse):
+		utility.ConflictResponse(w, r, fmt.Errorf("Role is still assigned to users. Reassign them before deleting the role."), h.Logger)
+	case errors.Is(err, service.ErrBuiltInRole):
+		utility.ConflictResponse(w, r, fmt.Errorf("Built-in roles cannot be renamed or deleted"), h.Logger)
+	default:
+		utility.InternalServerError(w, r, err, h.Logger)
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/useragent"
+	"external-backend-go/internal/utility"
+)
+
+type SessionResponse struct {
+	ID           string    `json:"id"`
+	IPAddress    string    `json:"ipAddress,omitempty"`
+	UserAgent    string    `json:"userAgent,omitempty"`
+	Browser      string    `json:"browser"`
+	OS           string    `json:"os"`
+	Device       string    `json:"device"`
+	LastActivity time.Time `json:"lastActivity"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// Current is true for the session the request was made with.
+	Current bool `json:"current"`
+}
+
+func newSessionResponses(sessions []*model.Session, currentSessionID string) []SessionResponse {
+	responses := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		client := useragent.Parse(session.UserAgent.String)
+		responses[i] = SessionResponse{
+			ID:           session.ID,
+			IPAddress:    session.IpAddress.String,
+			UserAgent:    session.UserAgent.String,
+			Browser:      client.Browser,
+			OS:           client.OS,
+			Device:       client.Device,
+			LastActivity: time.Unix(int64(session.LastActivity), 0).UTC(),
+			CreatedAt:    session.CreatedAt,
+			Current:      session.ID == currentSessionID,
+		}
+	}
+	return responses
+}
+
+// @Summary List my sessions
+// @Description Lists the active sessions of the current user with the device and browser they were created from.
+// @Tags authentication
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} SessionResponse "Active sessions, most recently used first"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/sessions [get]
+func (h *AuthHandler) ListMySessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.currentClaims(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.AuthService.ListSessions(r.Context(), claims.UserID)
+	if err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, newSessionResponses(sessions, claims.SessionID))
+}
+
+// @Summary Revoke one of my sessions
+// @Description Signs the current user out of one session. Revoking the current session is the same as logging out.
+// @Tags authentication
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]string "message: Session revoked."
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 404 {object} map[string]string "message: Resource not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /me/sessions/{id} [delete]
+func (h *AuthHandler) RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	h.revokeSession(w, r, userID, mux.Vars(r)["id"])
+}
+
+// @Summary List user sessions
+// @Description Lists the active sessions of any user. Requires JWT authentication and 'users:read' permission.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} SessionResponse "Active sessions, most recently used first"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/sessions [get]
+func (h *AuthHandler) ListUserSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid User ID format"), h.Logger)
+		return
+	}
+
+	sessions, err := h.AuthService.ListSessions(r.Context(), int32(userID))
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			utility.NotFoundResponse(w, r, h.Logger)
+		} else {
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, newSessionResponses(sessions, ""))
+}
+
+// @Summary Revoke a user session
+// @Description Signs a user out of one session. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} map[string]string "message: Session revoked."
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: Resource not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/sessions/{sessionId} [delete]
+func (h *AuthHandler) RevokeUserSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid User ID format"), h.Logger)
+		return
+	}
+
+	h.revokeSession(w, r, int32(userID), vars["sessionId"])
+}
+
+func (h *AuthHandler) revokeSession(w http.ResponseWriter, r *http.Request, userID int32, sessionID string) {
+	if err := h.AuthService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			utility.NotFoundResponse(w, r, h.Logger)
+		} else {
+			utility.InternalServerError(w, r, err, h.Logger)
+		}
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "Session revoked."})
+}
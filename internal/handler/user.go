@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+type UserHandler struct {
+	UserService *service.UserService
+	Logger      *logger.Logger
+}
+
+func NewUserHandler(userService *service.UserService, logger *logger.Logger) *UserHandler {
+	return &UserHandler{UserService: userService, Logger: logger}
+}
+
+// @Summary List users
+// @Description Retrieves a paginated list of users. Soft-deleted users are left out unless deleted or include_deleted is set. Requires JWT authentication and 'users:read' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "Page number (default 1)"
+// @Param pageSize query int false "Number of users per page (default 10)"
+// @Param role query string false "Only users holding this role, as primary or additional role"
+// @Param verified query bool false "Only users whose email is (true) or is not (false) verified"
+// @Param deleted query bool false "Only users who are (true) or are not (false) soft-deleted"
+// @Param include_deleted query bool false "List soft-deleted users along with active ones"
+// @Param created_from query string false "Only users created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Only users created before this time (RFC 3339), or on or before this day (YYYY-MM-DD)"
+// @Param search query string false "Part of the username or email, case-insensitive"
+// @Param sort query string false "Sort field: id, username, email or created_at (default id)"
+// @Param order query string false "Sort order: asc or desc (default asc)"
+// @Success 200 {object} service.PaginatedUsers "Paginated list of users"
+// @Failure 400 {object} map[string]string "message: Invalid filter value"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users [get]
+func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	filter := service.UserFilter{
+		Role:   query.Get("role"),
+		Search: query.Get("search"),
+	}
+	if filter.Verified, err = optionalBool(query.Get("verified")); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid verified value, expected true or false"), h.Logger)
+		return
+	}
+	if filter.Deleted, err = optionalBool(query.Get("deleted")); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid deleted value, expected true or false"), h.Logger)
+		return
+	}
+	includeDeleted, err := optionalBool(query.Get("include_deleted"))
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid include_deleted value, expected true or false"), h.Logger)
+		return
+	}
+	filter.IncludeDeleted = includeDeleted != nil && *includeDeleted
+	if filter.CreatedFrom, err = filterTime(query.Get("created_from"), false); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid created_from value, expected RFC 3339 or YYYY-MM-DD"), h.Logger)
+		return
+	}
+	if filter.CreatedTo, err = filterTime(query.Get("created_to"), true); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid created_to value, expected RFC 3339 or YYYY-MM-DD"), h.Logger)
+		return
+	}
+
+	switch sortBy := query.Get("sort"); sortBy {
+	case "", service.UserSortID, service.UserSortUsername, service.UserSortEmail, service.UserSortCreatedAt:
+		filter.SortBy = sortBy
+	default:
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid sort value, expected id, username, email or created_at"), h.Logger)
+		return
+	}
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		filter.SortDesc = true
+	default:
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid order value, expected asc or desc"), h.Logger)
+		return
+	}
+
+	users, err := h.UserService.ListUsers(r.Context(), filter, page, pageSize)
+	if err != nil {
+		utility.InternalServerError(w, r, err, h.Logger)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, users)
+}
+
+// @Summary Get user by ID
+// @Description Retrieves a single user, including soft-deleted ones. Requires JWT authentication and 'users:read' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} model.User "User details"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id} [get]
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.UserService.GetUser(r.Context(), id)
+	if err != nil {
+		h.userErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, user)
+}
+
+// @Summary Soft delete a user
+// @Description Marks a user as deleted and revokes their sessions, access tokens and pending password reset. A deleted user cannot log in and their username and email become available again. The user can be restored until they are purged after the retention period. Admins cannot delete their own account. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} model.User "Deleted user"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format / You cannot do this to your own account"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 409 {object} map[string]string "message: User is already deleted"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id} [delete]
+func (h *UserHandler) SoftDeleteUser(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := h.actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.UserService.SoftDeleteUser(r.Context(), actorID, id)
+	if err != nil {
+		h.userErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, user)
+}
+
+// @Summary Restore a user
+// @Description Restores a soft-deleted user. Their old sessions stay revoked, so they have to log in again. Fails when their username or email has been taken in the meantime. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} model.User "Restored user"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 409 {object} map[string]string "message: User is not deleted / Username or email already taken / User has been erased"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.UserService.RestoreUser(r.Context(), id)
+	if err != nil {
+		h.userErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, user)
+}
+
+// @Summary Verify a user's email
+// @Description Marks a user's email address as verified without a verification link. Pending verification links stop working. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} model.User "Verified user"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 409 {object} map[string]string "message: Email already verified"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/verify-email [post]
+func (h *UserHandler) VerifyUserEmail(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.UserService.VerifyUserEmail(r.Context(), id)
+	if err != nil {
+		h.userErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, user)
+}
+
+// @Summary Log a user out everywhere
+// @Description Revokes every session of a user. Their access tokens stop being accepted immediately. Admins cannot log out their own account this way; they can use /logout-all instead. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string "message: User logged out from all sessions"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format / You cannot do this to your own account"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/logout [post]
+func (h *UserHandler) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := h.actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.UserService.LogoutUser(r.Context(), actorID, id); err != nil {
+		h.userErrorResponse(w, r, err)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, map[string]string{"message": "User logged out from all sessions"})
+}
+
+// @Summary Permanently delete a user
+// @Description Deletes a user and everything tied to the account. Items they own are kept without an owner. This cannot be undone. Admins cannot delete their own account. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format / You cannot do this to your own account"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/permanent [delete]
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := h.actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.UserService.DeleteUser(r.Context(), actorID, id); err != nil {
+		h.userErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) userID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid User ID format"), h.Logger)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// actorAndUserID returns the ID of the admin making the request and of the user it
+// targets.
+func (h *UserHandler) actorAndUserID(w http.ResponseWriter, r *http.Request) (int32, int32, bool) {
+	claims, ok := middleware.CurrentUser(r.Context())
+	if !ok {
+		utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), h.Logger)
+		return 0, 0, false
+	}
+	id, ok := h.userID(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	return claims.UserID, id, true
+}
+
+func (h *UserHandler) userErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utility.NotFoundResponse(w, r, h.Logger)
+	case errors.Is(err, service.ErrCannotManageSelf):
+		utility.BadRequestResponse(w, r, fmt.Errorf("You cannot do this to your own account"), h.Logger)
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		utility.ConflictResponse(w, r, fmt.Errorf("Email already verified"), h.Logger)
+	case errors.Is(err, service.ErrUserAlreadyDeleted):
+		utility.ConflictResponse(w, r, fmt.Errorf("User is already deleted"), h.Logger)
+	case errors.Is(err, service.ErrUserNotDeleted):
+		utility.ConflictResponse(w, r, fmt.Errorf("User is not deleted"), h.Logger)
+	case errors.Is(err, service.ErrUserAlreadyExists):
+		utility.ConflictResponse(w, r, fmt.Errorf("Username or email already taken by another user. Rename that user before restoring this one."), h.Logger)
+	case errors.Is(err, service.ErrUserErased):
+		utility.ConflictResponse(w, r, fmt.Errorf("User has been erased and cannot be restored"), h.Logger)
+	default:
+		utility.InternalServerError(w, r, err, h.Logger)
+	}
+}
+
+// optionalBool parses a boolean query parameter, returning nil when it is empty.
+func optionalBool(value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// filterTime parses an RFC 3339 time or a YYYY-MM-DD date. As an upper bound a date
+// stands for the end of that day, so the day itself is included.
+func filterTime(value string, upperBound bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if upperBound {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"external-backend-go/internal/request"
+	"external-backend-go/internal/service"
+	"external-backend-go/internal/utility"
+)
+
+// @Summary List roles of a user
+// @Description Lists the roles assigned to a user, their primary role and the effective roles including every role inherited through the hierarchy. Requires JWT authentication and 'users:read' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} service.UserRoles "Roles of the user"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	roles, err := h.RoleService.GetUserRoles(r.Context(), userID)
+	if err != nil {
+		h.userRoleErrorResponse(w, r, err, "")
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, roles)
+}
+
+// @Summary Assign a role to a user
+// @Description Gives a user an additional role. The user's current access tokens stop being accepted and have to be refreshed to pick up the role. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Param request body request.AssignUserRoleRequest true "Role to assign"
+// @Success 200 {object} service.UserRoles "Roles of the user"
+// @Failure 400 {object} map[string]string "message: Invalid request data / Invalid User ID format / Role does not exist"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/roles [post]
+func (h *RoleHandler) AssignUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.AssignUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid request data"), h.Logger)
+		return
+	}
+
+	if err := req.Validate(h.Validator); err != nil {
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			utility.BadRequestResponse(w, r, fmt.Errorf("Validation failed: %s", ve.Error()), h.Logger)
+			return
+		}
+		utility.BadRequestResponse(w, r, err, h.Logger)
+		return
+	}
+
+	roles, err := h.RoleService.AssignUserRole(r.Context(), userID, req.RoleName)
+	if err != nil {
+		h.userRoleErrorResponse(w, r, err, req.RoleName)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, roles)
+}
+
+// @Summary Remove a role from a user
+// @Description Takes a role away from a user. The primary role cannot be removed; change it through /admin/users/{id}/role instead. Requires JWT authentication and 'users:write' permission.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 200 {object} service.UserRoles "Roles of the user"
+// @Failure 400 {object} map[string]string "message: Invalid User ID format / Role does not exist"
+// @Failure 401 {object} map[string]string "message: Authentication token required / Invalid token"
+// @Failure 403 {object} map[string]string "message: You do not have permission to access this resource."
+// @Failure 404 {object} map[string]string "message: User not found / Role is not assigned to the user"
+// @Failure 409 {object} map[string]string "message: The primary role cannot be removed"
+// @Failure 500 {object} map[string]string "message: Internal server error"
+// @Router /admin/users/{id}/roles/{role} [delete]
+func (h *RoleHandler) RemoveUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+	roleName := mux.Vars(r)["role"]
+
+	roles, err := h.RoleService.RemoveUserRole(r.Context(), userID, roleName)
+	if err != nil {
+		h.userRoleErrorResponse(w, r, err, roleName)
+		return
+	}
+
+	utility.JSONResponse(w, http.StatusOK, roles)
+}
+
+func (h *RoleHandler) userID(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utility.BadRequestResponse(w, r, fmt.Errorf("Invalid User ID format"), h.Logger)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func (h *RoleHandler) userRoleErrorResponse(w http.ResponseWriter, r *http.Request, err error, roleName string) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utility.NotFoundResponse(w, r, h.Logger)
+	case errors.Is(err, service.ErrInvalidRoleName):
+		utility.BadRequestResponse(w, r, fmt.Errorf("Role %q does not exist", roleName), h.Logger)
+	case errors.Is(err, service.ErrUserRoleNotAssigned):
+		utility.ErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Role %q is not assigned to the user", roleName))
+	case errors.Is(err, service.ErrPrimaryRoleRemoval):
+		utility.ConflictResponse(w, r, fmt.Errorf("The primary role cannot be removed. Change the user's primary role first."), h.Logger)
+	default:
+		utility.InternalServerError(w, r, err, h.Logger)
+	}
+}
@@ -2,14 +2,15 @@ package middleware
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
-
 	"external-backend-go/internal/auth"
 	"external-backend-go/internal/logger"
+	"external-backend-go/internal/store"
 	"external-backend-go/internal/utility"
 )
 
@@ -17,24 +18,73 @@ type contextKey string
 
 const userClaimsContextKey contextKey = "userClaims"
 
-func AuthMiddleware(jwtSecret string, appLogger *logger.Logger) func(http.Handler) http.Handler {
+// AuthMiddleware authenticates requests with an access token from the Authorization
+// header. With cookieAuth, a token in the AccessTokenCookieName cookie is accepted as
+// well when there is no header; such requests need a valid CSRF token unless their
+// method is safe.
+func AuthMiddleware(tokens *auth.TokenConfig, sessionStore store.SessionStore, authVersionStore store.AuthVersionStore, cookieAuth bool, appLogger *logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			tokenString := r.Header.Get("Authorization")
-			if tokenString == "" {
+			if tokenString != "" {
+				if !strings.HasPrefix(tokenString, "Bearer ") {
+					utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid token format"), appLogger)
+					return
+				}
+				tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+			} else if cookie, err := r.Cookie(AccessTokenCookieName); cookieAuth && err == nil {
+				// Browsers attach cookies to cross-site requests too, so only a request
+				// that could read the CSRF cookie may use this one to change state.
+				if !IsSafeMethod(r.Method) && !ValidCSRFToken(r) {
+					appLogger.Warn("Cookie-authenticated request without a valid CSRF token rejected for %s %s", r.Method, r.URL.Path)
+					utility.ErrorResponse(w, http.StatusForbidden, "Invalid or missing CSRF token.")
+					return
+				}
+				tokenString = cookie.Value
+			} else {
 				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Authentication token required"), appLogger)
 				return
 			}
 
-			if !strings.HasPrefix(tokenString, "Bearer ") {
-				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid token format"), appLogger)
+			claims, err := auth.ValidateToken(tokenString, tokens)
+			if err != nil {
+				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid token: %w", err), appLogger)
+				return
+			}
+
+			if claims.Scope == auth.ScopeMFAPending {
+				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Two-factor authentication required"), appLogger)
+				return
+			}
+
+			if claims.SessionID == "" {
+				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Token is not bound to a session"), appLogger)
 				return
 			}
-			tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
-			claims, err := auth.ValidateToken(tokenString, jwtSecret)
+			active, err := sessionStore.IsSessionActive(r.Context(), claims.SessionID)
 			if err != nil {
-				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Invalid token: %w", err), appLogger)
+				utility.InternalServerError(w, r, fmt.Errorf("failed to check session %s: %w", claims.SessionID, err), appLogger)
+				return
+			}
+			if !active {
+				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Session has been revoked"), appLogger)
+				return
+			}
+
+			// A bumped auth version means the user's role or credentials changed after
+			// this token was issued; the client has to refresh it to pick up the change.
+			authVersion, err := authVersionStore.GetAuthVersion(r.Context(), claims.UserID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User no longer exists"), appLogger)
+					return
+				}
+				utility.InternalServerError(w, r, fmt.Errorf("failed to check auth version of user %d: %w", claims.UserID, err), appLogger)
+				return
+			}
+			if claims.AuthVersion != authVersion {
+				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("Token is out of date, please refresh it"), appLogger)
 				return
 			}
 
@@ -46,7 +96,9 @@ func AuthMiddleware(jwtSecret string, appLogger *logger.Logger) func(http.Handle
 	}
 }
 
-func GetUserClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
-	claims, ok := ctx.Value(userClaimsContextKey).(jwt.MapClaims)
+// CurrentUser returns the validated claims of the authenticated user. It reports
+// false when the request did not pass through AuthMiddleware.
+func CurrentUser(ctx context.Context) (*auth.Claims, bool) {
+	claims, ok := ctx.Value(userClaimsContextKey).(*auth.Claims)
 	return claims, ok
 }
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// Cookies and header used by browser clients in cookie auth mode. The CSRF cookie is
+// readable by scripts; the client copies it into CSRFHeaderName on every unsafe
+// request, which a cross-site form or fetch cannot do.
+const (
+	AccessTokenCookieName = "access_token"
+	CSRFCookieName        = "csrf_token"
+	CSRFHeaderName        = "X-CSRF-Token"
+)
+
+// IsSafeMethod reports whether method is one that must not change state, so it needs
+// no CSRF token.
+func IsSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// ValidCSRFToken reports whether the request carries the double-submitted CSRF
+// token, i.e. a CSRFHeaderName header equal to the CSRFCookieName cookie.
+func ValidCSRFToken(r *http.Request) bool {
+	cookie, err := r.Cookie(CSRFCookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	header := r.Header.Get(CSRFHeaderName)
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/store"
+)
+
+func TestValidCSRFToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		cookie string
+		header string
+		valid  bool
+	}{
+		{"matching", "token", "token", true},
+		{"different", "token", "other", false},
+		{"missing header", "token", "", false},
+		{"missing cookie", "", "token", false},
+		{"both missing", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				r.Header.Set(CSRFHeaderName, tt.header)
+			}
+			if got := ValidCSRFToken(r); got != tt.valid {
+				t.Errorf("ValidCSRFToken = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+type activeSessionStore struct{ store.SessionStore }
+
+func (activeSessionStore) IsSessionActive(ctx context.Context, id string) (bool, error) {
+	return true, nil
+}
+
+type fixedAuthVersionStore struct{ store.AuthVersionStore }
+
+func (fixedAuthVersionStore) GetAuthVersion(ctx context.Context, userID int32) (int32, error) {
+	return 1, nil
+}
+
+func TestAuthMiddlewareCookieCSRF(t *testing.T) {
+	tokens := &auth.TokenConfig{Keys: auth.NewHMACKeyRing("test-secret"), Issuer: "test", Audience: "test"}
+	accessToken, err := auth.GenerateToken(1, "alice", "user", []string{"user"}, "session-1", 1, "", []string{auth.AMRPassword}, tokens, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := AuthMiddleware(tokens, activeSessionStore{}, fixedAuthVersionStore{}, true, logger.NewLogger())(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }))
+
+	tests := []struct {
+		name   string
+		method string
+		csrf   string
+		header string
+		status int
+	}{
+		{"safe method without token", http.MethodGet, "", "", http.StatusNoContent},
+		{"unsafe method with matching token", http.MethodPost, "csrf", "csrf", http.StatusNoContent},
+		{"unsafe method without header", http.MethodPost, "csrf", "", http.StatusForbidden},
+		{"unsafe method with other header", http.MethodDelete, "csrf", "forged", http.StatusForbidden},
+		{"unsafe method without cookie", http.MethodPut, "", "csrf", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/items", nil)
+			r.AddCookie(&http.Cookie{Name: AccessTokenCookieName, Value: accessToken})
+			if tt.csrf != "" {
+				r.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: tt.csrf})
+			}
+			if tt.header != "" {
+				r.Header.Set(CSRFHeaderName, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+
+	// A bearer token is not sent by browsers on their own, so it needs no CSRF token.
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/items", nil)
+	r.Header.Set("Authorization", "Bearer "+accessToken)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("bearer request status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
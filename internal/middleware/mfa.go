@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/utility"
+)
+
+// RequireMFA only lets a request through when its token was issued after a second
+// factor was verified, i.e. the "amr" claim contains "otp". It must run after
+// AuthMiddleware.
+func RequireMFA(appLogger *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := CurrentUser(r.Context())
+			if !ok {
+				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), appLogger)
+				return
+			}
+
+			if claims.HasAMR(auth.AMROTP) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			appLogger.Warn("Request without second factor rejected for %s %s", r.Method, r.URL.Path)
+			utility.ErrorResponse(w, http.StatusForbidden, "Two-factor authentication is required to access this resource.")
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/store"
+	"external-backend-go/internal/utility"
+)
+
+// RequirePermission only lets the request through when one of the roles in the access
+// token, or a role they inherit from, has been granted permission. It must run after
+// AuthMiddleware.
+func RequirePermission(permission string, permissionStore store.PermissionStore, roleHierarchyStore store.RoleHierarchyStore, appLogger *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := CurrentUser(r.Context())
+			if !ok {
+				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), appLogger)
+				return
+			}
+
+			hierarchy, err := roleHierarchyStore.GetHierarchy(r.Context())
+			if err != nil {
+				appLogger.Error("Failed to get role hierarchy for permission check: %v", err)
+				utility.ForbiddenResponse(w, r, appLogger)
+				return
+			}
+
+			for _, role := range hierarchy.Expand(claims.RoleNames()) {
+				permissions, err := permissionStore.ListByRoleName(r.Context(), role)
+				if err != nil {
+					appLogger.Error("Failed to get permissions for role %s: %v", role, err)
+					utility.ForbiddenResponse(w, r, appLogger)
+					return
+				}
+				for _, p := range permissions {
+					if p == permission {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+			utility.ForbiddenResponse(w, r, appLogger)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/store"
+)
+
+// Policy answers authorization questions that depend on the resource being accessed,
+// which route middleware cannot see. Handlers call it after loading the resource, for
+// example to let authors change only their own items.
+type Policy struct {
+	roleHierarchyStore store.RoleHierarchyStore
+}
+
+func NewPolicy(roleHierarchyStore store.RoleHierarchyStore) *Policy {
+	return &Policy{roleHierarchyStore: roleHierarchyStore}
+}
+
+// HasRole reports whether the authenticated user holds role, either directly or
+// through the role hierarchy. It reports false for unauthenticated requests.
+func (p *Policy) HasRole(ctx context.Context, role string) (bool, error) {
+	claims, ok := CurrentUser(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	hierarchy, err := p.roleHierarchyStore.GetHierarchy(ctx)
+	if err != nil {
+		return false, err
+	}
+	return hierarchy.Satisfies(claims.RoleNames(), role), nil
+}
+
+// OwnerOrRole reports whether the authenticated user owns a resource owned by ownerID,
+// or holds overrideRole. Resources without an owner can only be accessed through the role.
+func (p *Policy) OwnerOrRole(ctx context.Context, ownerID model.NullInt32, overrideRole string) (bool, error) {
+	claims, ok := CurrentUser(ctx)
+	if !ok {
+		return false, nil
+	}
+	if ownerID.Valid && ownerID.Int32 == claims.UserID {
+		return true, nil
+	}
+	return p.HasRole(ctx, overrideRole)
+}
+
+// OwnerOrAdmin is OwnerOrRole with admin as the override role.
+func (p *Policy) OwnerOrAdmin(ctx context.Context, ownerID model.NullInt32) (bool, error) {
+	return p.OwnerOrRole(ctx, ownerID, model.RoleAdmin)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/policy"
+	"external-backend-go/internal/utility"
+)
+
+// PolicyMiddleware evaluates the request against the policy file and refuses it with
+// 403 when the policy denies it. It must run after AuthMiddleware on authenticated
+// routes so user attributes are available. A nil engine disables policy checks.
+func PolicyMiddleware(engine *policy.Engine, appLogger *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if engine == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decision, err := engine.Evaluate(r.Context(), PolicyInput(r))
+			if err != nil {
+				appLogger.Error("Failed to evaluate policy for %s %s: %v", r.Method, r.URL.Path, err)
+				utility.ForbiddenResponse(w, r, appLogger)
+				return
+			}
+			if !decision.Allowed {
+				appLogger.Warn("Policy denied %s %s: %s", r.Method, r.URL.Path, decision.Reason)
+				utility.ForbiddenResponse(w, r, appLogger)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PolicyInput describes r for policy evaluation.
+func PolicyInput(r *http.Request) policy.Input {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	in := policy.Input{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		IP:     ip,
+		Time:   time.Now(),
+	}
+	if claims, ok := CurrentUser(r.Context()); ok {
+		in.Claims = claims
+	}
+	return in
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/utility"
+)
+
+// RequireVerifiedEmail rejects tokens issued in restricted mode to users who have not
+// verified their email address yet. It must run after AuthMiddleware.
+func RequireVerifiedEmail(appLogger *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := CurrentUser(r.Context())
+			if !ok {
+				utility.UnauthorizedErrorResponse(w, r, fmt.Errorf("User claims not found in context"), appLogger)
+				return
+			}
+
+			if claims.Scope == auth.ScopeUnverified {
+				appLogger.Warn("Request from unverified user %d rejected for %s %s", claims.UserID, r.Method, r.URL.Path)
+				utility.ErrorResponse(w, http.StatusForbidden, "Email address must be verified to access this resource.")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
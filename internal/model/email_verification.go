@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+type EmailVerificationToken struct {
+	ID        int32     `json:"id"`
+	UserID    int32     `json:"userId"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"-"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	UsedAt    NullTime  `json:"usedAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type EmailChangeToken struct {
+	ID        int32     `json:"id"`
+	UserID    int32     `json:"userId"`
+	NewEmail  string    `json:"newEmail"`
+	TokenHash string    `json:"-"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	UsedAt    NullTime  `json:"usedAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
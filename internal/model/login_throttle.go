@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	LoginThrottleScopeAccount = "account"
+	LoginThrottleScopeIP      = "ip"
+)
+
+type LoginThrottle struct {
+	Scope        string    `json:"scope"`
+	Subject      string    `json:"subject"`
+	FailedCount  int32     `json:"failedCount"`
+	LastFailedAt time.Time `json:"lastFailedAt"`
+	LockedUntil  NullTime  `json:"lockedUntil"`
+}
+
+// IsLocked reports whether login attempts are currently refused.
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil.Valid && t.LockedUntil.Time.After(now)
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+type UserMFA struct {
+	UserID       int32     `json:"userId"`
+	TOTPSecret   string    `json:"-"`
+	EnabledAt    NullTime  `json:"enabledAt"`
+	LastUsedStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (m *UserMFA) Enabled() bool {
+	return m.EnabledAt.Valid
+}
@@ -0,0 +1,13 @@
+package model
+
+// Permissions granted to roles through the role_permissions table.
+const (
+	PermissionItemsRead     = "items:read"
+	PermissionItemsWrite    = "items:write"
+	PermissionItemsWriteOwn = "items:write_own"
+	PermissionUsersRead     = "users:read"
+	PermissionUsersWrite    = "users:write"
+	PermissionRolesRead     = "roles:read"
+	PermissionRolesWrite    = "roles:write"
+	PermissionPoliciesRead  = "policies:read"
+)
@@ -0,0 +1,40 @@
+package model
+
+// Roles seeded by the migrations, from the most to the least privileged. Each one
+// inherits from the next.
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleAuthor = "author"
+	RoleUser   = "user"
+)
+
+// RoleHierarchy maps each role name to the name of the role it inherits from. Roles
+// without a parent map to an empty string.
+type RoleHierarchy map[string]string
+
+// Expand returns roles followed by every role they inherit from, without duplicates.
+// A cycle in the hierarchy ends the walk instead of looping forever.
+func (h RoleHierarchy) Expand(roles []string) []string {
+	seen := make(map[string]bool, len(roles))
+	expanded := make([]string, 0, len(roles))
+	for _, role := range roles {
+		for role != "" && !seen[role] {
+			seen[role] = true
+			expanded = append(expanded, role)
+			role = h[role]
+		}
+	}
+	return expanded
+}
+
+// Satisfies reports whether holding roles grants requiredRole, either directly or
+// through inheritance.
+func (h RoleHierarchy) Satisfies(roles []string, requiredRole string) bool {
+	for _, role := range h.Expand(roles) {
+		if role == requiredRole {
+			return true
+		}
+	}
+	return false
+}
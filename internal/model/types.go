@@ -50,6 +50,28 @@ func (ns *NullString) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+type NullInt32 struct {
+	Int32 int32 `json:"int32"`
+	Valid bool  `json:"valid"`
+}
+
+func (ni NullInt32) MarshalJSON() ([]byte, error) {
+	if !ni.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ni.Int32)
+}
+
+func (ni *NullInt32) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		ni.Valid = false
+		return nil
+	}
+	err := json.Unmarshal(b, &ni.Int32)
+	ni.Valid = err == nil
+	return err
+}
+
 func FromSQLNullTime(t sql.NullTime) NullTime {
 	return NullTime{Time: t.Time, Valid: t.Valid}
 }
@@ -65,3 +87,11 @@ func FromSQLNullString(s sql.NullString) NullString {
 func (ns NullString) ToSQLNullString() sql.NullString {
 	return sql.NullString{String: ns.String, Valid: ns.Valid}
 }
+
+func FromSQLNullInt32(i sql.NullInt32) NullInt32 {
+	return NullInt32{Int32: i.Int32, Valid: i.Valid}
+}
+
+func (ni NullInt32) ToSQLNullInt32() sql.NullInt32 {
+	return sql.NullInt32{Int32: ni.Int32, Valid: ni.Valid}
+}
@@ -6,16 +6,19 @@ import (
 )
 
 type User struct {
-	ID                int32      `json:"id"`
-	Username          string     `json:"username"`
-	Email             string     `json:"email"`
-	HashedPassword    string     `json:"-"`
-	EmailVerifiedAt   NullTime   `json:"emailVerifiedAt"`
-	RoleID            int32      `json:"roleId"`
-	RememberTokenUUID NullString `json:"rememberTokenUuid"`
-	CreatedAt         time.Time  `json:"createdAt"`
-	UpdatedAt         time.Time  `json:"updatedAt"`
-	DeletedAt         NullTime   `json:"deletedAt"`
+	ID                 int32      `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	HashedPassword     string     `json:"-"`
+	EmailVerifiedAt    NullTime   `json:"emailVerifiedAt"`
+	RoleID             int32      `json:"roleId"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+	DeletedAt          NullTime   `json:"deletedAt"`
+	DisplayName        NullString `json:"displayName"`
+	AvatarURL          NullString `json:"avatarUrl"`
+	ErasureScheduledAt NullTime   `json:"erasureScheduledAt"`
+	ErasedAt           NullTime   `json:"erasedAt"`
 }
 
 func (u *User) GetID() int32 {
@@ -42,10 +45,13 @@ func (u *User) SetUpdatedAt(t time.Time) {
 	u.UpdatedAt = t
 }
 
+// Role is a named set of permissions. A role with a ParentID inherits everything
+// granted to its parent, see RoleHierarchy.
 type Role struct {
 	ID          int32      `json:"id"`
 	Name        string     `json:"name"`
 	Description NullString `json:"description"`
+	ParentID    NullInt32  `json:"parentId"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
 }
@@ -75,16 +81,23 @@ func (r *Role) SetUpdatedAt(t time.Time) {
 }
 
 type PasswordResetToken struct {
-	Email     string   `json:"email"`
-	Token     string   `json:"token"`
-	CreatedAt NullTime `json:"createdAt"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 type Session struct {
-	ID           string        `json:"id"`
-	UserID       sql.NullInt32 `json:"userId"`
-	IpAddress    NullString    `json:"ipAddress"`
-	UserAgent    NullString    `json:"userAgent"`
-	Payload      string        `json:"payload"`
-	LastActivity int32         `json:"lastActivity"`
+	ID               string        `json:"id"`
+	UserID           sql.NullInt32 `json:"userId"`
+	IpAddress        NullString    `json:"ipAddress"`
+	UserAgent        NullString    `json:"userAgent"`
+	Payload          string        `json:"payload"`
+	LastActivity     int32         `json:"lastActivity"`
+	RefreshTokenHash NullString    `json:"-"`
+	RefreshExpiresAt NullTime      `json:"refreshExpiresAt"`
+	CreatedAt        time.Time     `json:"createdAt"`
+	// PreviousRefreshTokenHash is the hash of the refresh token replaced by the last
+	// rotation, kept to recognize reuse of a stolen token.
+	PreviousRefreshTokenHash NullString `json:"-"`
 }
@@ -0,0 +1,318 @@
+// Package oidc logs users in with external OpenID Connect providers. It implements
+// the relying party side of the authorization code flow with PKCE: provider
+// discovery, the authorization URL, the code exchange and ID token validation.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"external-backend-go/internal/auth"
+)
+
+var (
+	// ErrInvalidIDToken is returned when the provider's ID token fails validation.
+	ErrInvalidIDToken = errors.New("invalid ID token")
+	// ErrExchangeFailed is returned when the provider rejects the authorization code.
+	ErrExchangeFailed = errors.New("authorization code exchange failed")
+)
+
+// idTokenAlgorithms are the signing algorithms accepted for ID tokens. Symmetric
+// algorithms are left out: they would make the client secret a signing key.
+var idTokenAlgorithms = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// jwksRefreshInterval is how long the keys of a provider are used before they are
+// fetched again. Tokens signed with an unknown key trigger an earlier refresh, but at
+// most once per jwksMinRefreshInterval.
+const (
+	jwksRefreshInterval    = time.Hour
+	jwksMinRefreshInterval = time.Minute
+	// maxResponseBytes caps what is read from a provider response.
+	maxResponseBytes = 1 << 20
+)
+
+// Config identifies the client registered at a provider.
+type Config struct {
+	// IssuerURL is the provider's issuer identifier; discovery is fetched from
+	// IssuerURL + "/.well-known/openid-configuration".
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is where the provider sends the user back to, and has to be
+	// registered at the provider.
+	RedirectURL string
+	// Scopes are requested from the provider; "openid" is added when missing.
+	Scopes []string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat.
+	Leeway time.Duration
+}
+
+// Metadata is the part of the provider's discovery document that is used.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// IDToken holds the validated claims of an ID token.
+type IDToken struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// idTokenClaims are the claims read from an ID token.
+type idTokenClaims struct {
+	Nonce             string `json:"nonce"`
+	AuthorizedParty   string `json:"azp,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     bool   `json:"email_verified,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Provider is an OpenID Connect provider. Its discovery document and keys are fetched
+// on first use and cached.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *Metadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+// NewProvider returns a provider for config that makes its requests with client.
+func NewProvider(config Config, client *http.Client) *Provider {
+	config.IssuerURL = strings.TrimRight(config.IssuerURL, "/")
+	if !slices.Contains(config.Scopes, "openid") {
+		config.Scopes = append([]string{"openid"}, config.Scopes...)
+	}
+	return &Provider{config: config, client: client}
+}
+
+// AuthCodeURL returns the provider URL the user is sent to for logging in. state and
+// nonce are echoed back in the callback and the ID token; codeChallenge is the S256
+// challenge of the PKCE code verifier.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+// Exchange redeems an authorization code at the token endpoint and returns the
+// validated ID token. nonce is the value passed to AuthCodeURL for this login.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*IDToken, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var tokenErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &tokenErr)
+		return nil, fmt.Errorf("%w: status %d: %s %s", ErrExchangeFailed, resp.StatusCode, tokenErr.Error, tokenErr.ErrorDescription)
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrExchangeFailed)
+	}
+	return p.verifyIDToken(ctx, metadata, tokenResponse.IDToken, nonce)
+}
+
+// verifyIDToken checks the signature, issuer, audience, lifetime and nonce of an ID
+// token as required by OpenID Connect Core 3.1.3.7.
+func (p *Provider) verifyIDToken(ctx context.Context, metadata *Metadata, rawIDToken, nonce string) (*IDToken, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, metadata, kid)
+	},
+		jwt.WithValidMethods(idTokenAlgorithms),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(p.config.Leeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub claim", ErrInvalidIDToken)
+	}
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: issued to another client", ErrInvalidIDToken)
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return &IDToken{
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// discover returns the provider's metadata, fetching it on first use.
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata Metadata
+	if err := p.getJSON(ctx, p.config.IssuerURL+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	// The issuer in the document has to be the one it was fetched for, or another
+	// provider's tokens could be accepted.
+	if strings.TrimRight(metadata.Issuer, "/") != p.config.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery document is for issuer %q, expected %q", metadata.Issuer, p.config.IssuerURL)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document lacks a required endpoint")
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// key returns the provider's public key with the given ID, refetching the key set
+// when the ID is unknown, e.g. after the provider rotated its keys.
+func (p *Provider) key(ctx context.Context, metadata *Metadata, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok && time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) >= jwksMinRefreshInterval {
+		var set auth.JWKSet
+		if err := p.getJSON(ctx, metadata.JWKSURI, &set); err != nil {
+			return nil, fmt.Errorf("failed to fetch provider keys: %w", err)
+		}
+		keys := make(map[string]interface{}, len(set.Keys))
+		for _, jwk := range set.Keys {
+			if jwk.Use != "" && jwk.Use != "sig" {
+				continue
+			}
+			key, err := jwk.PublicKey()
+			if err != nil {
+				// Keys of types we do not support cannot have signed a token we accept.
+				continue
+			}
+			keys[jwk.Kid] = key
+		}
+		p.keys = keys
+		p.keysFetchedAt = time.Now()
+	}
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// lookupKey finds a cached key. A token without a kid is accepted when the provider
+// publishes a single key. p.mu must be held.
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(v)
+}
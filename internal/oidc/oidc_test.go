@@ -0,0 +1,127 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"external-backend-go/internal/oidc/oidctest"
+)
+
+const testRedirectURL = "http://localhost/auth/oidc/test/callback"
+
+// newTestProvider starts an in-process identity provider and returns it together with
+// a relying party registered at it.
+func newTestProvider(t *testing.T) (*oidctest.Provider, *Provider) {
+	t.Helper()
+	idp, err := oidctest.NewProvider("client", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(idp.Close)
+	idp.SetIdentity(oidctest.Identity{
+		Subject:           "subject-1",
+		Email:             "alice@example.com",
+		EmailVerified:     true,
+		Name:              "Alice",
+		PreferredUsername: "alice",
+	})
+
+	provider := NewProvider(Config{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"email", "profile"},
+	}, idp.Server.Client())
+	return idp, provider
+}
+
+// authorize runs the browser part of a login and returns the code, the PKCE verifier
+// and the nonce to exchange it with.
+func authorize(t *testing.T, idp *oidctest.Provider, provider *Provider) (code, codeVerifier, nonce string) {
+	t.Helper()
+	ctx := context.Background()
+	codeVerifier, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err = GenerateNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", nonce, CodeChallengeS256(codeVerifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("state = %q, want state-1", state)
+	}
+	return code, codeVerifier, nonce
+}
+
+func TestProviderExchange(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	code, codeVerifier, nonce := authorize(t, idp, provider)
+
+	idToken, err := provider.Exchange(context.Background(), code, codeVerifier, nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := IDToken{
+		Subject:           "subject-1",
+		Email:             "alice@example.com",
+		EmailVerified:     true,
+		Name:              "Alice",
+		PreferredUsername: "alice",
+	}
+	if *idToken != want {
+		t.Errorf("ID token = %+v, want %+v", *idToken, want)
+	}
+
+	// Codes are single-use.
+	if _, err := provider.Exchange(context.Background(), code, codeVerifier, nonce); !errors.Is(err, ErrExchangeFailed) {
+		t.Errorf("second Exchange error = %v, want ErrExchangeFailed", err)
+	}
+}
+
+func TestProviderExchangeRejectsNonceMismatch(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	code, codeVerifier, _ := authorize(t, idp, provider)
+
+	other, err := GenerateNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Exchange(context.Background(), code, codeVerifier, other); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Exchange error = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestProviderExchangeRejectsWrongAudience(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	idp.Audience = "another-client"
+	code, codeVerifier, nonce := authorize(t, idp, provider)
+
+	if _, err := provider.Exchange(context.Background(), code, codeVerifier, nonce); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Exchange error = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestProviderExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	code, _, nonce := authorize(t, idp, provider)
+
+	other, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Exchange(context.Background(), code, other, nonce); !errors.Is(err, ErrExchangeFailed) {
+		t.Errorf("Exchange error = %v, want ErrExchangeFailed", err)
+	}
+}
@@ -0,0 +1,314 @@
+// Package oidctest runs a minimal OpenID Connect provider in process, for exercising
+// the OIDC login flow without a real identity provider. It serves discovery, JWKS, an
+// authorization endpoint that approves every request, and a token endpoint that
+// enforces PKCE and issues RS256-signed ID tokens.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"external-backend-go/internal/auth"
+)
+
+const (
+	keyID   = "oidctest"
+	codeTTL = time.Minute
+)
+
+// Identity is the user the provider logs in on the next authorization request.
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// Provider is a fake identity provider listening on a local address.
+type Provider struct {
+	Server       *httptest.Server
+	ClientID     string
+	ClientSecret string
+	// Audience is the aud claim of issued ID tokens. Empty means ClientID; tests set
+	// it to check that tokens meant for another client are rejected.
+	Audience string
+
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	next  Identity
+	codes map[string]authorization
+}
+
+// authorization is an issued authorization code waiting to be redeemed.
+type authorization struct {
+	identity      Identity
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// NewProvider starts a provider that accepts the given client credentials. An empty
+// clientSecret registers a public client. Call Close when done.
+func NewProvider(clientID, clientSecret string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	p := &Provider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        map[string]authorization{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	p.Server = httptest.NewServer(mux)
+	return p, nil
+}
+
+// Issuer returns the provider's issuer URL.
+func (p *Provider) Issuer() string {
+	return p.Server.URL
+}
+
+// Close shuts the provider down.
+func (p *Provider) Close() {
+	p.Server.Close()
+}
+
+// SetIdentity sets the user that following authorization requests log in as.
+func (p *Provider) SetIdentity(identity Identity) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.next = identity
+}
+
+// Authorize follows an authorization URL as the browser would and returns the code
+// and state the provider redirects back with.
+func (p *Provider) Authorize(authURL string) (code, state string, err error) {
+	client := p.Server.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("authorization request failed with status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid redirect: %w", err)
+	}
+	query := location.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		return "", "", fmt.Errorf("authorization request rejected: %s", errCode)
+	}
+	return query.Get("code"), query.Get("state"), nil
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer(),
+		"authorization_endpoint":                p.Issuer() + "/authorize",
+		"token_endpoint":                        p.Issuer() + "/token",
+		"jwks_uri":                              p.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	jwk, err := auth.NewJWK(keyID, "RS256", &p.key.PublicKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, auth.JWKSet{Keys: []auth.JWK{jwk}})
+}
+
+// authorize logs the configured identity in and redirects back with a code.
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if query.Get("client_id") != p.ClientID || redirectURI == "" {
+		http.Error(w, "unknown client or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	params := redirect.Query()
+	params.Set("state", query.Get("state"))
+	switch {
+	case query.Get("response_type") != "code":
+		params.Set("error", "unsupported_response_type")
+	case query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "":
+		params.Set("error", "invalid_request")
+	default:
+		code, err := randomString()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		p.mu.Lock()
+		p.codes[code] = authorization{
+			identity:      p.next,
+			redirectURI:   redirectURI,
+			nonce:         query.Get("nonce"),
+			codeChallenge: query.Get("code_challenge"),
+			expiresAt:     time.Now().Add(codeTTL),
+		}
+		p.mu.Unlock()
+		params.Set("code", code)
+	}
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// token redeems an authorization code for an ID token.
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if !p.authenticateClient(r) {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	// Codes are single-use, whether or not the exchange succeeds.
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	authz, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+	if !ok || time.Now().After(authz.expiresAt) || r.PostForm.Get("redirect_uri") != authz.redirectURI {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(authz.codeChallenge)) != 1 {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	idToken, err := p.signIDToken(authz)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	accessToken, err := randomString()
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// authenticateClient accepts client_secret_basic, client_secret_post and, for a
+// public client, a bare client_id.
+func (p *Provider) authenticateClient(r *http.Request) bool {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		var err error
+		if clientID, err = url.QueryUnescape(clientID); err != nil {
+			return false
+		}
+		if clientSecret, err = url.QueryUnescape(clientSecret); err != nil {
+			return false
+		}
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	return clientID == p.ClientID && subtle.ConstantTimeCompare([]byte(clientSecret), []byte(p.ClientSecret)) == 1
+}
+
+func (p *Provider) signIDToken(authz authorization) (string, error) {
+	if authz.identity.Subject == "" {
+		return "", errors.New("no identity set")
+	}
+	audience := p.Audience
+	if audience == "" {
+		audience = p.ClientID
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.Issuer(),
+		"sub":   authz.identity.Subject,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": authz.nonce,
+	}
+	if authz.identity.Email != "" {
+		claims["email"] = authz.identity.Email
+		claims["email_verified"] = authz.identity.EmailVerified
+	}
+	if authz.identity.Name != "" {
+		claims["name"] = authz.identity.Name
+	}
+	if authz.identity.PreferredUsername != "" {
+		claims["preferred_username"] = authz.identity.PreferredUsername
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(p.key)
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
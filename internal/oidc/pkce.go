@@ -0,0 +1,32 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// GenerateCodeVerifier returns a random PKCE code verifier (RFC 7636 section 4.1).
+func GenerateCodeVerifier() (string, error) {
+	return randomString(32)
+}
+
+// GenerateNonce returns a random value for the state and nonce parameters.
+func GenerateNonce() (string, error) {
+	return randomString(32)
+}
+
+// CodeChallengeS256 returns the S256 code challenge of a code verifier.
+func CodeChallengeS256(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,127 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const AlgorithmArgon2id = "argon2id"
+
+// maxArgon2idMemory caps the memory a stored hash may ask for, in KiB, so a tampered
+// hash cannot make verification allocate without bound.
+const maxArgon2idMemory = 1024 * 1024
+
+// Argon2id hashes passwords with argon2id. Memory is in KiB.
+type Argon2id struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2id follows the second recommended option of RFC 9106.
+var DefaultArgon2id = Argon2id{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func (a Argon2id) Name() string {
+	return AlgorithmArgon2id
+}
+
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		a.Memory,
+		a.Iterations,
+		a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a Argon2id) Verify(encoded, password string) error {
+	hash, err := parseArgon2id(encoded)
+	if err != nil {
+		return err
+	}
+	key := argon2.IDKey([]byte(password), hash.salt, hash.params.Iterations, hash.params.Memory, hash.params.Parallelism, hash.params.KeyLength)
+	if subtle.ConstantTimeCompare(key, hash.key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func (a Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a Argon2id) NeedsRehash(encoded string) bool {
+	hash, err := parseArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return hash.version != argon2.Version ||
+		hash.params.Memory != a.Memory ||
+		hash.params.Iterations != a.Iterations ||
+		hash.params.Parallelism != a.Parallelism ||
+		hash.params.KeyLength != a.KeyLength ||
+		uint32(len(hash.salt)) != a.SaltLength
+}
+
+type argon2idHash struct {
+	version int
+	params  Argon2id
+	salt    []byte
+	key     []byte
+}
+
+// parseArgon2id decodes "$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>".
+func parseArgon2id(encoded string) (*argon2idHash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != AlgorithmArgon2id {
+		return nil, ErrUnknownFormat
+	}
+
+	var hash argon2idHash
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &hash.version); err != nil {
+		return nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.params.Memory, &hash.params.Iterations, &hash.params.Parallelism); err != nil {
+		return nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	var err error
+	if hash.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	if hash.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id key: %w", err)
+	}
+	hash.params.SaltLength = uint32(len(hash.salt))
+	hash.params.KeyLength = uint32(len(hash.key))
+
+	switch {
+	case hash.version != argon2.Version:
+		return nil, fmt.Errorf("unsupported argon2id version %d", hash.version)
+	case hash.params.Iterations < 1, hash.params.Parallelism < 1, hash.params.Memory > maxArgon2idMemory:
+		return nil, fmt.Errorf("invalid argon2id parameters: m=%d,t=%d,p=%d", hash.params.Memory, hash.params.Iterations, hash.params.Parallelism)
+	case len(hash.key) == 0:
+		return nil, fmt.Errorf("invalid argon2id key: empty")
+	}
+	return &hash, nil
+}
@@ -0,0 +1,60 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgon2idRejectsInvalidHashes(t *testing.T) {
+	const (
+		salt = "c29tZXNhbHRzb21lc2FsdA"
+		key  = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	)
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"not argon2id", "$argon2i$v=19$m=64,t=1,p=1$" + salt + "$" + key},
+		{"missing part", "$argon2id$v=19$m=64,t=1,p=1$" + salt},
+		{"old version", "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key},
+		{"zero iterations", "$argon2id$v=19$m=64,t=0,p=1$" + salt + "$" + key},
+		{"zero parallelism", "$argon2id$v=19$m=64,t=1,p=0$" + salt + "$" + key},
+		{"memory above cap", "$argon2id$v=19$m=4194304,t=1,p=1$" + salt + "$" + key},
+		{"empty key", "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$"},
+		{"bad salt", "$argon2id$v=19$m=64,t=1,p=1$!!$" + key},
+		{"bad parameters", "$argon2id$v=19$m=x,t=1,p=1$" + salt + "$" + key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseArgon2id(tt.encoded); err == nil {
+				t.Fatal("parseArgon2id accepted the hash")
+			}
+			if err := testArgon2id.Verify(tt.encoded, "correct horse"); err == nil {
+				t.Error("Verify accepted the hash")
+			}
+			if !testArgon2id.NeedsRehash(tt.encoded) {
+				t.Error("NeedsRehash = false for an invalid hash")
+			}
+		})
+	}
+}
+
+func TestArgon2idHashFormat(t *testing.T) {
+	encoded, err := testArgon2id.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("unexpected hash %q", encoded)
+	}
+	if !testArgon2id.Recognizes(encoded) {
+		t.Error("Recognizes = false for its own hash")
+	}
+	hash, err := parseArgon2id(encoded)
+	if err != nil {
+		t.Fatalf("parseArgon2id: %v", err)
+	}
+	if hash.params != testArgon2id {
+		t.Errorf("parsed parameters %+v, want %+v", hash.params, testArgon2id)
+	}
+}
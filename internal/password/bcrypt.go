@@ -0,0 +1,44 @@
+package password
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const AlgorithmBcrypt = "bcrypt"
+
+// Bcrypt hashes passwords with bcrypt at the given cost.
+type Bcrypt struct {
+	Cost int
+}
+
+func (b Bcrypt) Name() string {
+	return AlgorithmBcrypt
+}
+
+func (b Bcrypt) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (b Bcrypt) Verify(encoded, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
+
+func (b Bcrypt) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.Cost
+}
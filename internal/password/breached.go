@@ -0,0 +1,78 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// prefixLength is the length of the hash prefix entries are bucketed by, as in the
+// Pwned Passwords range API.
+const prefixLength = 5
+
+// BreachedList is a set of SHA-1 hashes of passwords known from data breaches, kept
+// in memory and bucketed by hash prefix.
+type BreachedList struct {
+	buckets map[string][]string
+	size    int
+}
+
+// LoadBreachedList reads a breach corpus with one upper- or lowercase hex SHA-1 hash
+// per line, optionally followed by ":<count>" as in the Pwned Passwords downloads.
+// Empty lines and lines starting with # are skipped.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password file: %w", err)
+	}
+	defer file.Close()
+
+	list := &BreachedList{buckets: make(map[string][]string)}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(strings.TrimSpace(hash))
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("invalid SHA-1 hash on line %d of breached password file", lineNumber)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("invalid SHA-1 hash on line %d of breached password file: %w", lineNumber, err)
+		}
+		prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+		list.buckets[prefix] = append(list.buckets[prefix], suffix)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password file: %w", err)
+	}
+
+	for prefix, suffixes := range list.buckets {
+		sort.Strings(suffixes)
+		list.buckets[prefix] = suffixes
+		list.size += len(suffixes)
+	}
+	return list, nil
+}
+
+// Size returns the number of hashes in the list.
+func (l *BreachedList) Size() int {
+	return l.size
+}
+
+// Contains reports whether password is in the list.
+func (l *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	suffixes := l.buckets[hash[:prefixLength]]
+	i := sort.SearchStrings(suffixes, hash[prefixLength:])
+	return i < len(suffixes) && suffixes[i] == hash[prefixLength:]
+}
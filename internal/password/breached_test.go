@@ -0,0 +1,86 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeBreachedFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newBreachedList(t *testing.T, content string) *BreachedList {
+	t.Helper()
+	list, err := LoadBreachedList(writeBreachedFile(t, content))
+	if err != nil {
+		t.Fatalf("LoadBreachedList: %v", err)
+	}
+	return list
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	content := strings.Join([]string{
+		"# Pwned Passwords sample",
+		"",
+		strings.ToUpper(sha1Hex("password")) + ":3861493",
+		sha1Hex("123456"),
+		"  " + sha1Hex("qwerty") + " : 42  ",
+	}, "\n")
+	list := newBreachedList(t, content)
+
+	if list.Size() != 3 {
+		t.Errorf("Size = %d, want 3", list.Size())
+	}
+	for _, password := range []string{"password", "123456", "qwerty"} {
+		if !list.Contains(password) {
+			t.Errorf("Contains(%q) = false", password)
+		}
+	}
+	if list.Contains("correct horse") {
+		t.Error(`Contains("correct horse") = true`)
+	}
+}
+
+func TestLoadBreachedListRejectsMalformedLines(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"too short", sha1Hex("password")[:39]},
+		{"too long", sha1Hex("password") + "0"},
+		{"not hex", "Z" + sha1Hex("password")[1:]},
+		{"count only", ":12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeBreachedFile(t, sha1Hex("123456")+"\n"+tt.line+"\n")
+			_, err := LoadBreachedList(path)
+			if err == nil {
+				t.Fatal("LoadBreachedList accepted a malformed line")
+			}
+			if !strings.Contains(err.Error(), "line 2") {
+				t.Errorf("error %q does not name line 2", err)
+			}
+		})
+	}
+}
+
+func TestLoadBreachedListMissingFile(t *testing.T) {
+	if _, err := LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadBreachedList accepted a missing file")
+	}
+}
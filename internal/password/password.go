@@ -0,0 +1,98 @@
+// Package password hashes and verifies user passwords.
+//
+// A Hasher hashes new passwords with one scheme and still verifies hashes made by the
+// other schemes it knows, reporting when a stored hash should be replaced. Hashes are
+// PHC strings, e.g. "$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>"; bcrypt keeps its
+// usual "$2a$<cost>$..." form, which is what existing accounts were stored with.
+//
+// A Policy decides which new passwords are acceptable.
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMismatch is returned when the password does not match the hash.
+	ErrMismatch = errors.New("password does not match")
+	// ErrUnknownFormat is returned for hashes that no registered scheme produced.
+	ErrUnknownFormat = errors.New("unrecognized password hash format")
+)
+
+// Scheme is one password hashing algorithm with its parameters.
+type Scheme interface {
+	// Name identifies the scheme in configuration, e.g. "argon2id".
+	Name() string
+	Hash(password string) (string, error)
+	// Verify returns ErrMismatch when password does not match encoded.
+	Verify(encoded, password string) error
+	// Recognizes reports whether encoded was produced by this scheme.
+	Recognizes(encoded string) bool
+	// NeedsRehash reports whether encoded was made with other parameters than the
+	// scheme's current ones.
+	NeedsRehash(encoded string) bool
+}
+
+// Hasher hashes passwords with its current scheme and verifies hashes of any of its
+// schemes.
+type Hasher struct {
+	current Scheme
+	schemes []Scheme
+	// dummyHash is verified against by VerifyDummy.
+	dummyHash string
+}
+
+// NewHasher returns a Hasher that hashes with the scheme named current. The other
+// schemes are only used to verify existing hashes.
+func NewHasher(current string, schemes ...Scheme) (*Hasher, error) {
+	h := &Hasher{schemes: schemes}
+	for _, scheme := range schemes {
+		if scheme.Name() == current {
+			h.current = scheme
+			break
+		}
+	}
+	if h.current == nil {
+		return nil, fmt.Errorf("unknown password hash algorithm %q", current)
+	}
+
+	dummyHash, err := h.current.Hash("dummy-password")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dummy password hash: %w", err)
+	}
+	h.dummyHash = dummyHash
+	return h, nil
+}
+
+// Algorithm returns the name of the scheme new passwords are hashed with.
+func (h *Hasher) Algorithm() string {
+	return h.current.Name()
+}
+
+// Hash hashes password with the current scheme.
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify checks password against encoded. When it matches, rehash reports whether
+// encoded uses an older scheme or parameters and should be replaced with Hash(password).
+func (h *Hasher) Verify(encoded, password string) (rehash bool, err error) {
+	for _, scheme := range h.schemes {
+		if !scheme.Recognizes(encoded) {
+			continue
+		}
+		if err := scheme.Verify(encoded, password); err != nil {
+			return false, err
+		}
+		return scheme != h.current || scheme.NeedsRehash(encoded), nil
+	}
+	return false, ErrUnknownFormat
+}
+
+// VerifyDummy takes about as long as verifying a password against a real hash. It is
+// used when there is no hash to check, so that response times do not reveal whether
+// an account exists.
+func (h *Hasher) VerifyDummy(password string) {
+	_ = h.current.Verify(h.dummyHash, password)
+}
@@ -0,0 +1,103 @@
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap parameters keep the tests fast; the checks do not depend on the cost.
+var (
+	testBcrypt   = Bcrypt{Cost: bcrypt.MinCost}
+	testArgon2id = Argon2id{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+)
+
+func newTestHasher(t *testing.T, current string) *Hasher {
+	t.Helper()
+	h, err := NewHasher(current, testArgon2id, testBcrypt)
+	if err != nil {
+		t.Fatalf("NewHasher(%q): %v", current, err)
+	}
+	return h
+}
+
+func TestHasherHashAndVerify(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmBcrypt, AlgorithmArgon2id} {
+		t.Run(algorithm, func(t *testing.T) {
+			h := newTestHasher(t, algorithm)
+			encoded, err := h.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+
+			rehash, err := h.Verify(encoded, "correct horse")
+			if err != nil {
+				t.Fatalf("Verify with the right password: %v", err)
+			}
+			if rehash {
+				t.Error("Verify asked to rehash a hash made with the current parameters")
+			}
+			if _, err := h.Verify(encoded, "wrong horse"); !errors.Is(err, ErrMismatch) {
+				t.Errorf("Verify with a wrong password = %v, want ErrMismatch", err)
+			}
+		})
+	}
+}
+
+func TestHasherRehash(t *testing.T) {
+	oldBcrypt, err := Bcrypt{Cost: bcrypt.MinCost + 1}.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := testBcrypt.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldArgon2id, err := Argon2id{Memory: 32, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2idHash, err := testArgon2id.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		current string
+		encoded string
+		rehash  bool
+	}{
+		{"bcrypt current", AlgorithmBcrypt, bcryptHash, false},
+		{"bcrypt other cost", AlgorithmBcrypt, oldBcrypt, true},
+		{"argon2id under bcrypt", AlgorithmBcrypt, argon2idHash, true},
+		{"argon2id current", AlgorithmArgon2id, argon2idHash, false},
+		{"argon2id other memory", AlgorithmArgon2id, oldArgon2id, true},
+		{"bcrypt under argon2id", AlgorithmArgon2id, bcryptHash, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rehash, err := newTestHasher(t, tt.current).Verify(tt.encoded, "correct horse")
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if rehash != tt.rehash {
+				t.Errorf("rehash = %v, want %v", rehash, tt.rehash)
+			}
+		})
+	}
+}
+
+func TestHasherUnknownFormat(t *testing.T) {
+	h := newTestHasher(t, AlgorithmArgon2id)
+	if _, err := h.Verify("$pbkdf2$whatever", "correct horse"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Verify = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestNewHasherUnknownAlgorithm(t *testing.T) {
+	if _, err := NewHasher("scrypt", testArgon2id, testBcrypt); err == nil {
+		t.Error("NewHasher accepted an unknown algorithm")
+	}
+}
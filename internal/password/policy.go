@@ -0,0 +1,138 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrPolicyViolation = errors.New("password does not meet the password policy")
+
+// Rules reported in a Violation.
+const (
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RuleUppercase = "uppercase"
+	RuleLowercase = "lowercase"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleUsername  = "contains_username"
+	RuleEmail     = "contains_email"
+	RuleBreached  = "breached"
+)
+
+// minUserInfoChars is the shortest username or email part DisallowUserInfo looks for.
+const minUserInfoChars = 3
+
+// Violation is one rule of the policy that a password breaks.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PolicyError lists every rule a password breaks. It matches ErrPolicyViolation with
+// errors.Is.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	rules := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		rules[i] = v.Rule
+	}
+	return fmt.Sprintf("%v: %s", ErrPolicyViolation, strings.Join(rules, ", "))
+}
+
+func (e *PolicyError) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+// Policy is what a new password has to satisfy. Lengths count characters, not bytes.
+type Policy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DisallowUserInfo rejects passwords containing the username or the email
+	// address, or its part before the @, ignoring case.
+	DisallowUserInfo bool
+	// Breached rejects passwords found in a breach corpus. Nil skips the check.
+	Breached *BreachedList
+}
+
+// Check returns a *PolicyError listing every rule password breaks, or nil if it
+// satisfies the policy. username and email belong to the account the password is for.
+func (p *Policy) Check(password, username, email string) error {
+	var violations []Violation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		add(RuleMinLength, "Password must be at least %d characters long", p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(RuleMaxLength, "Password must be at most %d characters long", p.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		add(RuleUppercase, "Password must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		add(RuleLowercase, "Password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		add(RuleDigit, "Password must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		add(RuleSymbol, "Password must contain a symbol")
+	}
+
+	if p.DisallowUserInfo {
+		lowered := strings.ToLower(password)
+		if containsFold(lowered, username) {
+			add(RuleUsername, "Password must not contain the username")
+		}
+		localPart, _, _ := strings.Cut(email, "@")
+		if containsFold(lowered, email) || containsFold(lowered, localPart) {
+			add(RuleEmail, "Password must not contain the email address")
+		}
+	}
+
+	if p.Breached != nil && p.Breached.Contains(password) {
+		add(RuleBreached, "Password has appeared in a data breach and cannot be used")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// containsFold reports whether lowered contains s, ignoring case. Values shorter than
+// minUserInfoChars never match, as they would rule out too many passwords.
+func containsFold(lowered, s string) bool {
+	if utf8.RuneCountInString(s) < minUserInfoChars {
+		return false
+	}
+	return strings.Contains(lowered, strings.ToLower(s))
+}
@@ -0,0 +1,99 @@
+package password
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func violatedRules(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Check returned %T, want *PolicyError", err)
+	}
+	if !errors.Is(err, ErrPolicyViolation) {
+		t.Error("PolicyError does not match ErrPolicyViolation")
+	}
+	rules := make([]string, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
+
+func TestPolicyCheck(t *testing.T) {
+	breached := newBreachedList(t, sha1Hex("Password123!")+"\n")
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		password string
+		want     []string
+	}{
+		{"min length", Policy{MinLength: 8}, "short", []string{RuleMinLength}},
+		{"min length counts characters", Policy{MinLength: 4}, "äöüß", nil},
+		{"max length", Policy{MaxLength: 4}, "toolong", []string{RuleMaxLength}},
+		{"uppercase", Policy{RequireUpper: true}, "lowercase", []string{RuleUppercase}},
+		{"lowercase", Policy{RequireLower: true}, "UPPERCASE", []string{RuleLowercase}},
+		{"digit", Policy{RequireDigit: true}, "nodigits", []string{RuleDigit}},
+		{"symbol", Policy{RequireSymbol: true}, "nosymbols1", []string{RuleSymbol}},
+		{"space counts as symbol", Policy{RequireSymbol: true}, "two words", nil},
+		{"breached", Policy{Breached: breached}, "Password123!", []string{RuleBreached}},
+		{"not breached", Policy{Breached: breached}, "Password123?", nil},
+		{
+			"every rule at once",
+			Policy{MinLength: 10, RequireUpper: true, RequireDigit: true, RequireSymbol: true},
+			"abc",
+			[]string{RuleMinLength, RuleUppercase, RuleDigit, RuleSymbol},
+		},
+		{
+			"satisfied",
+			Policy{MinLength: 8, MaxLength: 64, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true},
+			"Tr0ub4dor&3",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violatedRules(t, tt.policy.Check(tt.password, "someone", "someone@example.com"))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckUserInfo(t *testing.T) {
+	policy := Policy{DisallowUserInfo: true}
+	tests := []struct {
+		name     string
+		password string
+		username string
+		email    string
+		want     []string
+	}{
+		{"username ignoring case", "xxALICExx", "alice", "a@example.com", []string{RuleUsername}},
+		{"email local part", "xxjdoexx", "alice", "jdoe@example.com", []string{RuleEmail}},
+		{"whole email", "jdoe@example.com!", "alice", "jdoe@example.com", []string{RuleEmail}},
+		{"username and email", "alice-jdoe", "alice", "jdoe@example.com", []string{RuleUsername, RuleEmail}},
+		{"unrelated", "correct horse", "alice", "jdoe@example.com", nil},
+		// Shorter values than minUserInfoChars would rule out too many passwords.
+		{"username below cut-off", "xxboxx", "bo", "a@example.com", nil},
+		{"username at cut-off", "xxbobxx", "bob", "a@example.com", []string{RuleUsername}},
+		{"local part below cut-off", "xxjdxx", "alice", "jd@example.com", nil},
+		{"local part at cut-off", "xxjoexx", "alice", "joe@example.com", []string{RuleEmail}},
+		{"cut-off counts characters", "xxäöxx", "äö", "a@example.com", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violatedRules(t, policy.Check(tt.password, tt.username, tt.email))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"net"
+)
+
+// evaluate checks the condition against the attributes returned by lookup. A missing
+// attribute fails every operator except not_exists, negated ones included, so a typo
+// in an attribute name never grants access.
+func (c compiledCondition) evaluate(lookup func(string) (interface{}, bool)) ConditionTrace {
+	trace := ConditionTrace{Attr: c.Attr, Op: c.Op, Expected: c.Value}
+
+	actual, present := lookup(c.Attr)
+	trace.Actual = actual
+	if c.op == OpExists {
+		trace.Passed = present != c.negate
+		return trace
+	}
+
+	expected := c.values
+	if c.Ref != "" {
+		refValue, ok := lookup(c.Ref)
+		trace.Expected = refValue
+		if !ok {
+			return trace
+		}
+		expected = toStrings(refValue)
+	}
+	if !present {
+		return trace
+	}
+
+	actualValues := toStrings(actual)
+	var passed bool
+	switch c.op {
+	case OpEq:
+		passed = len(actualValues) == 1 && len(expected) == 1 && actualValues[0] == expected[0]
+	case OpIn:
+		passed = len(actualValues) == 1 && containsString(expected, actualValues[0])
+	case OpContains:
+		passed = len(expected) > 0
+		for _, value := range expected {
+			if !containsString(actualValues, value) {
+				passed = false
+			}
+		}
+	case OpContainsAny:
+		for _, value := range expected {
+			if containsString(actualValues, value) {
+				passed = true
+			}
+		}
+	case OpInCIDR:
+		if len(actualValues) == 1 {
+			if ip := net.ParseIP(actualValues[0]); ip != nil {
+				for _, network := range c.cidrs {
+					if network.Contains(ip) {
+						passed = true
+					}
+				}
+			}
+		}
+	case OpBetween:
+		if len(actualValues) == 1 {
+			if minutes, err := parseClock(actualValues[0]); err == nil {
+				if c.from <= c.to {
+					passed = minutes >= c.from && minutes < c.to
+				} else {
+					// The window wraps around midnight, e.g. 22:00 to 06:00.
+					passed = minutes >= c.from || minutes < c.to
+				}
+			}
+		}
+	}
+	trace.Passed = passed != c.negate
+	return trace
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
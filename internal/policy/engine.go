@@ -0,0 +1,318 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/store"
+)
+
+// ResourceLoader returns the attributes of the resource addressed by a request, given
+// the parameters captured from the rule's route pattern. It returns nil attributes
+// when the resource does not exist; rules about it are then skipped so the handler
+// can answer with 404.
+type ResourceLoader func(ctx context.Context, params map[string]string) (map[string]interface{}, error)
+
+// Input is the request being authorized.
+type Input struct {
+	Method string
+	Path   string
+	IP     string
+	Time   time.Time
+	// Claims are nil for unauthenticated requests.
+	Claims *auth.Claims
+}
+
+// Decision is the outcome of evaluating a request, with the trace of every rule that
+// matched its route so it can be explained.
+type Decision struct {
+	Allowed    bool                   `json:"allowed"`
+	Effect     Effect                 `json:"effect"`
+	Rule       string                 `json:"rule,omitempty"`
+	Reason     string                 `json:"reason"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Trace      []RuleTrace            `json:"trace"`
+}
+
+type RuleTrace struct {
+	Rule        string           `json:"rule"`
+	Description string           `json:"description,omitempty"`
+	Effect      Effect           `json:"effect"`
+	Applied     bool             `json:"applied"`
+	Skipped     string           `json:"skipped,omitempty"`
+	Conditions  []ConditionTrace `json:"conditions"`
+}
+
+type ConditionTrace struct {
+	Attr     string      `json:"attr"`
+	Op       string      `json:"op"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual"`
+	Passed   bool        `json:"passed"`
+}
+
+// Info describes the policy currently in effect.
+type Info struct {
+	File          string    `json:"file"`
+	LoadedAt      time.Time `json:"loadedAt"`
+	DefaultEffect Effect    `json:"defaultEffect"`
+	Rules         int       `json:"rules"`
+}
+
+// Engine evaluates requests against the policy file at path. The file is parsed once
+// per (re)load; evaluation only reads the compiled policy, so a reload never blocks
+// requests.
+type Engine struct {
+	path               string
+	roleHierarchyStore store.RoleHierarchyStore
+	permissionStore    store.PermissionStore
+	loaders            map[string]ResourceLoader
+	current            atomic.Pointer[compiledPolicy]
+	appLogger          *logger.Logger
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// NewEngine creates an engine for the policy file at path. Register resource loaders
+// before calling Load, since rules naming an unknown resource are rejected.
+func NewEngine(path string, roleHierarchyStore store.RoleHierarchyStore, permissionStore store.PermissionStore, appLogger *logger.Logger) *Engine {
+	return &Engine{
+		path:               path,
+		roleHierarchyStore: roleHierarchyStore,
+		permissionStore:    permissionStore,
+		loaders:            make(map[string]ResourceLoader),
+		appLogger:          appLogger,
+	}
+}
+
+// RegisterResource makes the attributes returned by loader available to rules that
+// set resource: name.
+func (e *Engine) RegisterResource(name string, loader ResourceLoader) {
+	e.loaders[name] = loader
+}
+
+// Load reads and compiles the policy file. On error the policy in effect is kept.
+func (e *Engine) Load() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stat, err := os.Stat(e.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat policy file: %w", err)
+	}
+	// Remember the version even if it fails to compile, so Watch does not log the
+	// same error on every tick until the file is fixed.
+	e.modTime, e.size = stat.ModTime(), stat.Size()
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return fmt.Errorf("failed to read policy file: %w", err)
+	}
+	compiled, err := parse(data, func(name string) bool {
+		_, ok := e.loaders[name]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+	e.current.Store(compiled)
+	return nil
+}
+
+// Watch reloads the policy file whenever its modification time or size changes,
+// checking every interval. A file that fails to compile is logged and ignored.
+func (e *Engine) Watch(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			stat, err := os.Stat(e.path)
+			if err != nil {
+				e.appLogger.Error("Failed to check policy file %s: %v", e.path, err)
+				continue
+			}
+			e.mu.Lock()
+			changed := !stat.ModTime().Equal(e.modTime) || stat.Size() != e.size
+			e.mu.Unlock()
+			if !changed {
+				continue
+			}
+
+			if err := e.Load(); err != nil {
+				e.appLogger.Error("Failed to reload policy file %s, keeping the previous policy: %v", e.path, err)
+				continue
+			}
+			e.appLogger.Info("Reloaded policy file %s (%d rules)", e.path, e.Info().Rules)
+		}
+	}()
+}
+
+func (e *Engine) Info() Info {
+	p := e.current.Load()
+	if p == nil {
+		return Info{File: e.path}
+	}
+	return Info{File: e.path, LoadedAt: p.loadedAt, DefaultEffect: p.defaultEffect, Rules: len(p.rules)}
+}
+
+// Evaluate decides whether the request is allowed. Rules whose route and method match
+// are checked in file order and the first one whose conditions all hold decides. When
+// allow rules matched but none of them held, the request is denied; otherwise the
+// default effect applies.
+func (e *Engine) Evaluate(ctx context.Context, in Input) (*Decision, error) {
+	p := e.current.Load()
+	if p == nil {
+		return nil, fmt.Errorf("no policy loaded")
+	}
+
+	attributes, err := e.attributes(ctx, in, p.location)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &Decision{Attributes: attributes, Trace: []RuleTrace{}}
+	resources := make(map[string]map[string]interface{})
+	allowRuleMatched := false
+	for _, rule := range p.rules {
+		params, ok := rule.match(in.Method, in.Path)
+		if !ok {
+			continue
+		}
+		trace := RuleTrace{Rule: rule.Name, Description: rule.Description, Effect: rule.Effect, Conditions: []ConditionTrace{}}
+
+		var resource map[string]interface{}
+		if rule.Resource != "" {
+			resource, ok = resources[rule.Resource]
+			if !ok {
+				resource, err = e.loaders[rule.Resource](ctx, params)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load %s for rule %q: %w", rule.Resource, rule.Name, err)
+				}
+				resources[rule.Resource] = resource
+			}
+			if resource == nil {
+				trace.Skipped = fmt.Sprintf("%s not found", rule.Resource)
+				decision.Trace = append(decision.Trace, trace)
+				continue
+			}
+		}
+		if rule.Effect == EffectAllow {
+			allowRuleMatched = true
+		}
+
+		lookup := func(name string) (interface{}, bool) {
+			switch {
+			case strings.HasPrefix(name, "request.params."):
+				value, ok := params[strings.TrimPrefix(name, "request.params.")]
+				return value, ok
+			case strings.HasPrefix(name, "resource."):
+				value, ok := resource[strings.TrimPrefix(name, "resource.")]
+				return value, ok && value != nil
+			}
+			value, ok := attributes[name]
+			return value, ok && value != nil
+		}
+
+		trace.Applied = true
+		for _, condition := range rule.conditions {
+			conditionTrace := condition.evaluate(lookup)
+			trace.Conditions = append(trace.Conditions, conditionTrace)
+			if !conditionTrace.Passed {
+				trace.Applied = false
+			}
+		}
+		decision.Trace = append(decision.Trace, trace)
+
+		if trace.Applied {
+			decision.Effect = rule.Effect
+			decision.Rule = rule.Name
+			decision.Reason = fmt.Sprintf("rule %q applies: all of its conditions hold", rule.Name)
+			decision.Allowed = rule.Effect == EffectAllow
+			return decision, nil
+		}
+	}
+
+	if allowRuleMatched {
+		decision.Effect = EffectDeny
+		decision.Reason = "allow rules match this request but none of them has all of its conditions met"
+	} else {
+		decision.Effect = p.defaultEffect
+		decision.Reason = "no rule decides this request, the default effect applies"
+	}
+	decision.Allowed = decision.Effect == EffectAllow
+	return decision, nil
+}
+
+// attributes builds the user.* and request.* attributes of a request.
+func (e *Engine) attributes(ctx context.Context, in Input, location *time.Location) (map[string]interface{}, error) {
+	now := in.Time.In(location)
+	attributes := map[string]interface{}{
+		"request.method":     in.Method,
+		"request.path":       in.Path,
+		"request.ip":         in.IP,
+		"request.time":       now.Format("15:04"),
+		"request.weekday":    strings.ToLower(now.Weekday().String()),
+		"user.authenticated": in.Claims != nil,
+	}
+	if in.Claims == nil {
+		return attributes, nil
+	}
+
+	hierarchy, err := e.roleHierarchyStore.GetHierarchy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role hierarchy: %w", err)
+	}
+	roles := hierarchy.Expand(in.Claims.RoleNames())
+	permissions, err := e.permissions(ctx, roles)
+	if err != nil {
+		return nil, err
+	}
+	attributes["user.id"] = in.Claims.UserID
+	attributes["user.username"] = in.Claims.Username
+	attributes["user.role"] = in.Claims.Role
+	attributes["user.roles"] = roles
+	attributes["user.permissions"] = permissions
+	attributes["user.amr"] = in.Claims.AMR
+	attributes["user.mfa"] = in.Claims.HasAMR(auth.AMROTP)
+	attributes["user.scope"] = in.Claims.Scope
+	attributes["user.email_verified"] = in.Claims.Scope != auth.ScopeUnverified
+	return attributes, nil
+}
+
+// permissions returns the permissions granted to any of roles, without duplicates.
+func (e *Engine) permissions(ctx context.Context, roles []string) ([]string, error) {
+	seen := make(map[string]bool)
+	permissions := []string{}
+	for _, role := range roles {
+		granted, err := e.permissionStore.ListByRoleName(ctx, role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get permissions for role %s: %w", role, err)
+		}
+		for _, permission := range granted {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+func (r compiledRule) match(method, path string) (map[string]string, bool) {
+	if r.methods != nil && !r.methods[strings.ToUpper(method)] {
+		return nil, false
+	}
+	for _, route := range r.routes {
+		if params, ok := route.match(path); ok {
+			return params, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,294 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/model"
+)
+
+type fakeRoleHierarchyStore struct {
+	hierarchy model.RoleHierarchy
+}
+
+func (s fakeRoleHierarchyStore) GetHierarchy(ctx context.Context) (model.RoleHierarchy, error) {
+	return s.hierarchy, nil
+}
+
+type fakePermissionStore struct {
+	permissions map[string][]string
+}
+
+func (s fakePermissionStore) ListByRoleName(ctx context.Context, roleName string) ([]string, error) {
+	return s.permissions[roleName], nil
+}
+
+// newTestEngine loads policyYAML into an engine set up by newEngineForFile.
+func newTestEngine(t *testing.T, policyYAML string) (*Engine, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := os.WriteFile(path, []byte(policyYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine := newEngineForFile(path)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return engine, path
+}
+
+// newEngineForFile returns an engine with admin inheriting from editor and editor from
+// user, and an "item" resource whose items are all owned by user 1.
+func newEngineForFile(path string) *Engine {
+	engine := NewEngine(path,
+		fakeRoleHierarchyStore{hierarchy: model.RoleHierarchy{"admin": "editor", "editor": "user", "user": ""}},
+		fakePermissionStore{permissions: map[string][]string{
+			"user":    {model.PermissionItemsRead, model.PermissionItemsWriteOwn},
+			"editor":  {model.PermissionItemsWrite},
+			"admin":   {model.PermissionUsersRead, model.PermissionUsersWrite},
+			"support": {model.PermissionUsersRead},
+		}},
+		logger.NewLogger(),
+	)
+	engine.RegisterResource("item", func(ctx context.Context, params map[string]string) (map[string]interface{}, error) {
+		if params["id"] == "404" {
+			return nil, nil
+		}
+		return map[string]interface{}{"id": params["id"], "owner_id": 1}, nil
+	})
+	return engine
+}
+
+func claims(userID int32, roles ...string) *auth.Claims {
+	return &auth.Claims{UserID: userID, Username: "someone", Role: roles[0], Roles: roles}
+}
+
+func evaluate(t *testing.T, engine *Engine, in Input) *Decision {
+	t.Helper()
+	if in.Time.IsZero() {
+		in.Time = time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	}
+	decision, err := engine.Evaluate(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	return decision
+}
+
+const testPolicy = `
+default_effect: allow
+rules:
+  - name: office-network-only
+    routes: ["/api/v1/admin/**"]
+    methods: [POST, PUT, DELETE]
+    effect: deny
+    conditions:
+      - {attr: request.ip, op: not_in_cidr, value: ["10.0.0.0/8"]}
+  - name: admin-needs-permission
+    routes: ["/api/v1/admin/**"]
+    effect: allow
+    conditions:
+      - {attr: user.permissions, op: contains_any, value: ["users:read", "items:write"]}
+  - name: item-owner
+    routes: ["/api/v1/items/{id}"]
+    methods: [PUT]
+    resource: item
+    effect: allow
+    conditions:
+      - {attr: resource.owner_id, op: eq, ref: user.id}
+  - name: night-freeze
+    routes: ["/api/v1/reports"]
+    effect: deny
+    conditions:
+      - {attr: request.time, op: between, value: ["22:00", "06:00"]}
+`
+
+func TestEngineEvaluate(t *testing.T) {
+	engine, _ := newTestEngine(t, testPolicy)
+	night := time.Date(2025, 1, 6, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		in      Input
+		allowed bool
+		rule    string
+	}{
+		{"no rule matches", Input{Method: "GET", Path: "/api/v1/profile", Claims: claims(2, "user")}, true, ""},
+		{"permission inherited from editor", Input{Method: "GET", Path: "/api/v1/admin/items", Claims: claims(2, "admin")}, true, "admin-needs-permission"},
+		{"editor permission", Input{Method: "GET", Path: "/api/v1/admin/items", Claims: claims(2, "editor")}, true, "admin-needs-permission"},
+		{"allow rule does not hold", Input{Method: "GET", Path: "/api/v1/admin/users", Claims: claims(2, "user")}, false, ""},
+		{"unauthenticated", Input{Method: "GET", Path: "/api/v1/admin/users"}, false, ""},
+		{"first deny rule decides", Input{Method: "POST", Path: "/api/v1/admin/items", IP: "192.0.2.1", Claims: claims(2, "editor")}, false, "office-network-only"},
+		{"deny rule skipped", Input{Method: "POST", Path: "/api/v1/admin/items", IP: "10.1.2.3", Claims: claims(2, "editor")}, true, "admin-needs-permission"},
+		{"owner", Input{Method: "PUT", Path: "/api/v1/items/5", Claims: claims(1, "user")}, true, "item-owner"},
+		{"not the owner", Input{Method: "PUT", Path: "/api/v1/items/5", Claims: claims(2, "user")}, false, ""},
+		{"method not covered", Input{Method: "DELETE", Path: "/api/v1/items/5", Claims: claims(2, "user")}, true, ""},
+		{"resource not found", Input{Method: "PUT", Path: "/api/v1/items/404", Claims: claims(2, "user")}, true, ""},
+		{"window wraps midnight", Input{Method: "GET", Path: "/api/v1/reports", Time: night}, false, "night-freeze"},
+		{"outside window", Input{Method: "GET", Path: "/api/v1/reports"}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := evaluate(t, engine, tt.in)
+			if decision.Allowed != tt.allowed || decision.Rule != tt.rule {
+				t.Errorf("allowed = %v by rule %q, want %v by rule %q (%s)", decision.Allowed, decision.Rule, tt.allowed, tt.rule, decision.Reason)
+			}
+		})
+	}
+}
+
+func TestEngineUserAttributes(t *testing.T) {
+	engine, _ := newTestEngine(t, testPolicy)
+	decision := evaluate(t, engine, Input{Method: "GET", Path: "/", Claims: claims(2, "editor")})
+
+	if got, want := decision.Attributes["user.roles"], []string{"editor", "user"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("user.roles = %v, want %v", got, want)
+	}
+	want := []string{model.PermissionItemsWrite, model.PermissionItemsRead, model.PermissionItemsWriteOwn}
+	if got := decision.Attributes["user.permissions"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("user.permissions = %v, want %v", got, want)
+	}
+	if got := decision.Attributes["request.weekday"]; got != "monday" {
+		t.Errorf("request.weekday = %v, want monday", got)
+	}
+}
+
+func TestEngineExplainTrace(t *testing.T) {
+	engine, _ := newTestEngine(t, testPolicy)
+	decision := evaluate(t, engine, Input{Method: "PUT", Path: "/api/v1/items/5", Claims: claims(2, "user")})
+
+	if len(decision.Trace) != 1 {
+		t.Fatalf("trace has %d rules, want 1", len(decision.Trace))
+	}
+	trace := decision.Trace[0]
+	if trace.Rule != "item-owner" || trace.Applied || len(trace.Conditions) != 1 {
+		t.Fatalf("unexpected trace %+v", trace)
+	}
+	condition := trace.Conditions[0]
+	if condition.Expected != int32(2) || condition.Actual != 1 || condition.Passed {
+		t.Errorf("condition trace = %+v, want owner 1 compared with user 2", condition)
+	}
+	if decision.Reason != "allow rules match this request but none of them has all of its conditions met" {
+		t.Errorf("reason = %q", decision.Reason)
+	}
+
+	data, err := json.Marshal(decision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var explained struct {
+		Allowed bool   `json:"allowed"`
+		Effect  string `json:"effect"`
+		Trace   []struct {
+			Rule       string `json:"rule"`
+			Applied    bool   `json:"applied"`
+			Conditions []struct {
+				Attr     string      `json:"attr"`
+				Op       string      `json:"op"`
+				Expected interface{} `json:"expected"`
+				Actual   interface{} `json:"actual"`
+				Passed   bool        `json:"passed"`
+			} `json:"conditions"`
+		} `json:"trace"`
+	}
+	if err := json.Unmarshal(data, &explained); err != nil {
+		t.Fatal(err)
+	}
+	if explained.Allowed || explained.Effect != "deny" || explained.Trace[0].Conditions[0].Attr != "resource.owner_id" || explained.Trace[0].Conditions[0].Op != "eq" {
+		t.Errorf("unexpected explain output %s", data)
+	}
+
+	skipped := evaluate(t, engine, Input{Method: "PUT", Path: "/api/v1/items/404", Claims: claims(2, "user")})
+	if skipped.Trace[0].Skipped != "item not found" {
+		t.Errorf("skipped = %q, want \"item not found\"", skipped.Trace[0].Skipped)
+	}
+}
+
+func TestEngineEvaluateWithoutPolicy(t *testing.T) {
+	engine := newEngineForFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err := engine.Load(); err == nil {
+		t.Fatal("Load accepted a missing file")
+	}
+	if _, err := engine.Evaluate(context.Background(), Input{Method: "GET", Path: "/"}); err == nil {
+		t.Error("Evaluate succeeded without a policy")
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	engine, path := newTestEngine(t, "default_effect: allow\n")
+	in := Input{Method: "GET", Path: "/api/v1/items"}
+	if !evaluate(t, engine, in).Allowed {
+		t.Fatal("request denied by the initial policy")
+	}
+
+	if err := os.WriteFile(path, []byte("default_effect: deny\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if evaluate(t, engine, in).Allowed {
+		t.Error("request allowed after reloading a deny policy")
+	}
+
+	// A broken file keeps the previous policy in effect.
+	if err := os.WriteFile(path, []byte("default_effect: [\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Load(); err == nil {
+		t.Fatal("Load accepted a broken file")
+	}
+	if evaluate(t, engine, in).Allowed {
+		t.Error("broken file replaced the policy in effect")
+	}
+}
+
+func TestEngineWatch(t *testing.T) {
+	engine, path := newTestEngine(t, "rules: []\n")
+	engine.Watch(10 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for engine.Info().Rules != 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("policy not reloaded, %d rules in effect", engine.Info().Rules)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestDefaultPolicyFile checks the policy shipped in configs.
+func TestDefaultPolicyFile(t *testing.T) {
+	engine := newEngineForFile(filepath.Join("..", "..", "configs", "policies.yaml"))
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		in      Input
+		allowed bool
+	}{
+		{"user in admin area", Input{Method: "GET", Path: "/api/v1/admin/users", Claims: claims(2, "user")}, false},
+		{"editor in admin area", Input{Method: "POST", Path: "/api/v1/admin/items", Claims: claims(2, "editor")}, true},
+		{"admin in admin area", Input{Method: "GET", Path: "/api/v1/admin/users", Claims: claims(2, "admin")}, true},
+		{"custom role with a permission", Input{Method: "GET", Path: "/api/v1/admin/users", Claims: claims(2, "support")}, true},
+		{"item ownership is left to the handler", Input{Method: "PUT", Path: "/api/v1/items/5", Claims: claims(2, "user")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if decision := evaluate(t, engine, tt.in); decision.Allowed != tt.allowed {
+				t.Errorf("allowed = %v, want %v (%s)", decision.Allowed, tt.allowed, decision.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Effect is the outcome of a rule.
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// Document is the policy file as written in YAML.
+type Document struct {
+	// DefaultEffect applies to requests that no rule decides. It defaults to allow, so
+	// routes without rules keep relying on their route middleware alone.
+	DefaultEffect Effect `yaml:"default_effect"`
+	// Timezone is used for request.time and request.weekday. It defaults to UTC.
+	Timezone string `yaml:"timezone"`
+	Rules    []Rule `yaml:"rules"`
+}
+
+// Rule applies to requests whose path matches one of Routes and whose method is in
+// Methods (any method when empty). It takes effect when all of its conditions hold.
+type Rule struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Routes      []string `yaml:"routes"`
+	Methods     []string `yaml:"methods"`
+	// Resource names a registered ResourceLoader whose attributes are exposed as
+	// resource.* to the conditions.
+	Resource   string      `yaml:"resource"`
+	Effect     Effect      `yaml:"effect"`
+	Conditions []Condition `yaml:"conditions"`
+}
+
+// Condition compares the attribute Attr with Value, or with the attribute named by Ref.
+type Condition struct {
+	Attr  string      `yaml:"attr"`
+	Op    string      `yaml:"op"`
+	Value interface{} `yaml:"value"`
+	Ref   string      `yaml:"ref"`
+}
+
+// Operators supported in conditions. Each not_ operator is the negation of the
+// operator without the prefix.
+const (
+	OpEq          = "eq"
+	OpNe          = "ne"
+	OpIn          = "in"
+	OpNotIn       = "not_in"
+	OpContains    = "contains"
+	OpNotContains = "not_contains"
+	OpContainsAny = "contains_any"
+	OpInCIDR      = "in_cidr"
+	OpNotInCIDR   = "not_in_cidr"
+	OpBetween     = "between"
+	OpNotBetween  = "not_between"
+	OpExists      = "exists"
+	OpNotExists   = "not_exists"
+)
+
+// compiledPolicy is a validated Document ready for evaluation.
+type compiledPolicy struct {
+	defaultEffect Effect
+	location      *time.Location
+	rules         []compiledRule
+	loadedAt      time.Time
+}
+
+type compiledRule struct {
+	Rule
+	routes     []routePattern
+	methods    map[string]bool
+	conditions []compiledCondition
+}
+
+type compiledCondition struct {
+	Condition
+	op     string
+	negate bool
+	values []string
+	cidrs  []*net.IPNet
+	from   int
+	to     int
+}
+
+// parse decodes and validates a policy file. hasResource reports whether a resource
+// loader is registered under a name.
+func parse(data []byte, hasResource func(string) bool) (*compiledPolicy, error) {
+	var doc Document
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid policy file: %w", err)
+	}
+
+	p := &compiledPolicy{defaultEffect: doc.DefaultEffect, location: time.UTC, loadedAt: time.Now()}
+	switch p.defaultEffect {
+	case "":
+		p.defaultEffect = EffectAllow
+	case EffectAllow, EffectDeny:
+	default:
+		return nil, fmt.Errorf("invalid default_effect %q", doc.DefaultEffect)
+	}
+	if doc.Timezone != "" {
+		location, err := time.LoadLocation(doc.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", doc.Timezone, err)
+		}
+		p.location = location
+	}
+
+	names := make(map[string]bool, len(doc.Rules))
+	for i, rule := range doc.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("rule name %q is used more than once", rule.Name)
+		}
+		names[rule.Name] = true
+
+		compiled, err := compileRule(rule, hasResource)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		p.rules = append(p.rules, compiled)
+	}
+	return p, nil
+}
+
+func compileRule(rule Rule, hasResource func(string) bool) (compiledRule, error) {
+	compiled := compiledRule{Rule: rule}
+	if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+		return compiled, fmt.Errorf("effect must be %q or %q", EffectAllow, EffectDeny)
+	}
+	if len(rule.Routes) == 0 {
+		return compiled, fmt.Errorf("at least one route is required")
+	}
+	for _, route := range rule.Routes {
+		pattern, err := parseRoutePattern(route)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.routes = append(compiled.routes, pattern)
+	}
+	if len(rule.Methods) > 0 {
+		compiled.methods = make(map[string]bool, len(rule.Methods))
+		for _, method := range rule.Methods {
+			compiled.methods[strings.ToUpper(method)] = true
+		}
+	}
+	if rule.Resource != "" && !hasResource(rule.Resource) {
+		return compiled, fmt.Errorf("unknown resource %q", rule.Resource)
+	}
+
+	for i, condition := range rule.Conditions {
+		c, err := compileCondition(condition)
+		if err != nil {
+			return compiled, fmt.Errorf("condition %d: %w", i+1, err)
+		}
+		compiled.conditions = append(compiled.conditions, c)
+	}
+	return compiled, nil
+}
+
+func compileCondition(condition Condition) (compiledCondition, error) {
+	c := compiledCondition{Condition: condition, op: condition.Op}
+	if condition.Attr == "" {
+		return c, fmt.Errorf("attr is required")
+	}
+	if condition.Ref != "" && condition.Value != nil {
+		return c, fmt.Errorf("value and ref cannot both be set")
+	}
+
+	switch condition.Op {
+	case OpNe:
+		c.op, c.negate = OpEq, true
+	case OpNotIn, OpNotContains, OpNotInCIDR, OpNotBetween, OpNotExists:
+		c.op, c.negate = strings.TrimPrefix(condition.Op, "not_"), true
+	case OpEq, OpIn, OpContains, OpContainsAny, OpInCIDR, OpBetween, OpExists:
+	default:
+		return c, fmt.Errorf("unknown operator %q", condition.Op)
+	}
+
+	if c.op == OpExists {
+		return c, nil
+	}
+	if condition.Ref != "" {
+		if c.op == OpInCIDR || c.op == OpBetween {
+			return c, fmt.Errorf("operator %q does not support ref", condition.Op)
+		}
+		return c, nil
+	}
+	if condition.Value == nil {
+		return c, fmt.Errorf("value or ref is required")
+	}
+	c.values = toStrings(condition.Value)
+
+	switch c.op {
+	case OpInCIDR:
+		for _, value := range c.values {
+			network, err := parseNetwork(value)
+			if err != nil {
+				return c, err
+			}
+			c.cidrs = append(c.cidrs, network)
+		}
+	case OpBetween:
+		if len(c.values) != 2 {
+			return c, fmt.Errorf("between expects two times, e.g. [\"08:00\", \"18:00\"]")
+		}
+		var err error
+		if c.from, err = parseClock(c.values[0]); err != nil {
+			return c, err
+		}
+		if c.to, err = parseClock(c.values[1]); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+// parseNetwork accepts a CIDR range or a single IP address.
+func parseNetwork(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", value)
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP range %q: %w", value, err)
+	}
+	return network, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// routePattern matches request paths segment by segment. "{name}" matches one segment
+// and captures it as request.params.name, "*" matches one segment and a trailing "**"
+// matches any number of remaining segments.
+type routePattern struct {
+	segments []string
+}
+
+func parseRoutePattern(route string) (routePattern, error) {
+	if !strings.HasPrefix(route, "/") {
+		return routePattern{}, fmt.Errorf("route %q must start with /", route)
+	}
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	for i, segment := range segments {
+		if segment == "**" && i != len(segments)-1 {
+			return routePattern{}, fmt.Errorf("route %q: ** is only allowed at the end", route)
+		}
+	}
+	return routePattern{segments: segments}, nil
+}
+
+func (p routePattern) match(path string) (map[string]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	params := make(map[string]string)
+	for i, pattern := range p.segments {
+		if pattern == "**" {
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		switch {
+		case pattern == "*":
+		case strings.HasPrefix(pattern, "{") && strings.HasSuffix(pattern, "}"):
+			params[pattern[1:len(pattern)-1]] = segments[i]
+		case pattern != segments[i]:
+			return nil, false
+		}
+	}
+	if len(segments) != len(p.segments) {
+		return nil, false
+	}
+	return params, true
+}
+
+// toStrings turns a scalar or a list from YAML into strings, so that values are
+// compared the same way whether they were written as numbers or strings.
+func toStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case []string:
+		return v
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoutePatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		params  map[string]string
+		match   bool
+	}{
+		{"/api/v1/items", "/api/v1/items", map[string]string{}, true},
+		{"/api/v1/items", "/api/v1/items/", map[string]string{}, true},
+		{"/api/v1/items", "/api/v1/items/1", nil, false},
+		{"/api/v1/items/{id}", "/api/v1/items/42", map[string]string{"id": "42"}, true},
+		{"/api/v1/items/{id}", "/api/v1/items", nil, false},
+		{"/api/v1/users/{id}/roles/{role}", "/api/v1/users/7/roles/editor", map[string]string{"id": "7", "role": "editor"}, true},
+		{"/api/v1/*/{id}", "/api/v1/items/3", map[string]string{"id": "3"}, true},
+		{"/api/v1/*", "/api/v1/items/3", nil, false},
+		{"/api/v1/admin/**", "/api/v1/admin/users/3/roles", map[string]string{}, true},
+		{"/api/v1/admin/**", "/api/v1/admin", map[string]string{}, true},
+		{"/api/v1/admin/**", "/api/v1/items", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			pattern, err := parseRoutePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("parseRoutePattern: %v", err)
+			}
+			params, ok := pattern.match(tt.path)
+			if ok != tt.match {
+				t.Fatalf("match = %v, want %v", ok, tt.match)
+			}
+			if ok && !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("params = %v, want %v", params, tt.params)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown field", "rules:\n  - name: a\n    route: [/x]\n    effect: allow\n", "invalid policy file"},
+		{"default effect", "default_effect: maybe\n", "invalid default_effect"},
+		{"timezone", "timezone: Mars/Olympus\n", "invalid timezone"},
+		{"missing name", "rules:\n  - routes: [/x]\n    effect: allow\n", "has no name"},
+		{"duplicate name", "rules:\n  - {name: a, routes: [/x], effect: allow}\n  - {name: a, routes: [/y], effect: deny}\n", "more than once"},
+		{"effect", "rules:\n  - {name: a, routes: [/x], effect: permit}\n", "effect must be"},
+		{"no routes", "rules:\n  - {name: a, effect: allow}\n", "at least one route"},
+		{"relative route", "rules:\n  - {name: a, routes: [x], effect: allow}\n", "must start with /"},
+		{"inner **", "rules:\n  - {name: a, routes: [/x/**/y], effect: allow}\n", "only allowed at the end"},
+		{"unknown resource", "rules:\n  - {name: a, routes: [/x], effect: allow, resource: order}\n", "unknown resource"},
+		{"unknown operator", "rules:\n  - name: a\n    routes: [/x]\n    effect: allow\n    conditions: [{attr: user.id, op: gt, value: 1}]\n", "unknown operator"},
+		{"value and ref", "rules:\n  - name: a\n    routes: [/x]\n    effect: allow\n    conditions: [{attr: user.id, op: eq, value: 1, ref: resource.owner_id}]\n", "cannot both be set"},
+		{"missing value", "rules:\n  - name: a\n    routes: [/x]\n    effect: allow\n    conditions: [{attr: user.id, op: eq}]\n", "value or ref is required"},
+		{"ref with cidr", "rules:\n  - name: a\n    routes: [/x]\n    effect: allow\n    conditions: [{attr: request.ip, op: in_cidr, ref: user.id}]\n", "does not support ref"},
+		{"bad cidr", "rules:\n  - name: a\n    routes: [/x]\n    effect: allow\n    conditions: [{attr: request.ip, op: in_cidr, value: [10.0.0.0/33]}]\n", "invalid IP range"},
+		{"between arity", "rules:\n  - name: a\n    routes: [/x]\n    effect: allow\n    conditions: [{attr: request.time, op: between, value: [\"08:00\"]}]\n", "expects two times"},
+		{"between clock", "rules:\n  - name: a\n    routes: [/x]\n    effect: allow\n    conditions: [{attr: request.time, op: between, value: [\"08:00\", \"25:00\"]}]\n", "invalid time of day"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse([]byte(tt.yaml), func(name string) bool { return name == "item" })
+			if err == nil {
+				t.Fatal("parse accepted the policy")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	p, err := parse([]byte("rules: []\n"), func(string) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.defaultEffect != EffectAllow {
+		t.Errorf("default effect = %q, want allow", p.defaultEffect)
+	}
+	if p.location.String() != "UTC" {
+		t.Errorf("location = %s, want UTC", p.location)
+	}
+}
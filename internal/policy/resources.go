@@ -0,0 +1,34 @@
+package policy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"external-backend-go/internal/store"
+)
+
+// ItemResource exposes the item addressed by the {id} route parameter as resource.id
+// and resource.owner_id. Items without an owner have no owner_id attribute.
+func ItemResource(itemStore store.ItemStore) ResourceLoader {
+	return func(ctx context.Context, params map[string]string) (map[string]interface{}, error) {
+		id, err := strconv.Atoi(params["id"])
+		if err != nil {
+			return nil, nil
+		}
+		item, err := itemStore.GetByID(ctx, int32(id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		attributes := map[string]interface{}{"id": item.ID}
+		if item.OwnerID.Valid {
+			attributes["owner_id"] = item.OwnerID.Int32
+		}
+		return attributes, nil
+	}
+}
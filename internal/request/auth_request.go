@@ -13,7 +13,7 @@ func isValidEmail(email string) bool {
 
 type RegisterUserRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=255"`
-	Password string `json:"password" validate:"required,min=6,max=255"`
+	Password string `json:"password" validate:"required,max=255"`
 	Email    string `json:"email" validate:"required,email"`
 }
 
@@ -25,8 +25,11 @@ func (r *RegisterUserRequest) Validate(v *validator.Validate) error {
 }
 
 type LoginUserRequest struct {
-	Username string `json:"username" validate:"required"`
+	Username string `json:"username" validate:"required,max=255"`
 	Password string `json:"password" validate:"required"`
+	// RememberMe also sets a long-lived cookie that /login/remember accepts in place of
+	// the password once the session has ended.
+	RememberMe bool `json:"remember_me"`
 }
 
 func (r *LoginUserRequest) Validate(v *validator.Validate) error {
@@ -36,8 +39,19 @@ func (r *LoginUserRequest) Validate(v *validator.Validate) error {
 	return nil
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (r *RefreshTokenRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
 type UpdateUserRoleRequest struct {
-	RoleName string `json:"role" validate:"required,oneof=admin user author"`
+	RoleName string `json:"role" validate:"required,max=50"`
 }
 
 func (r *UpdateUserRoleRequest) Validate(v *validator.Validate) error {
@@ -47,10 +61,21 @@ func (r *UpdateUserRoleRequest) Validate(v *validator.Validate) error {
 	return nil
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r *ForgotPasswordRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
 type PasswordResetRequest struct {
 	Email       string `json:"email" validate:"required,email"`
 	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=6"`
+	NewPassword string `json:"new_password" validate:"required,max=255"`
 }
 
 func (r *PasswordResetRequest) Validate(v *validator.Validate) error {
@@ -59,3 +84,14 @@ func (r *PasswordResetRequest) Validate(v *validator.Validate) error {
 	}
 	return nil
 }
+
+type ResendVerificationEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r *ResendVerificationEmailRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r *MagicLinkRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+func (r *MFALoginRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type MFAConfirmRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+func (r *MFAConfirmRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type MFADisableRequest struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+func (r *MFADisableRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+// UpdateProfileRequest is a partial update: fields that are left out keep their
+// value, while an empty display name or avatar URL clears it.
+type UpdateProfileRequest struct {
+	Username    *string `json:"username" validate:"omitempty,min=3,max=255"`
+	DisplayName *string `json:"display_name" validate:"omitempty,max=100"`
+	AvatarURL   *string `json:"avatar_url" validate:"omitempty,max=2048,url|len=0"`
+}
+
+func (r *UpdateProfileRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,max=255"`
+}
+
+func (r *ChangePasswordRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type ChangeEmailRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewEmail        string `json:"new_email" validate:"required,email,max=255"`
+}
+
+func (r *ChangeEmailRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+func (r *DeleteAccountRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type CreateRoleRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=50"`
+	Description string `json:"description" validate:"max=255"`
+	// Parent is the name of the role this role inherits from.
+	Parent string `json:"parent" validate:"omitempty,max=50"`
+}
+
+func (r *CreateRoleRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type UpdateRoleRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=50"`
+	Description string `json:"description" validate:"max=255"`
+	// Parent is the name of the role this role inherits from.
+	Parent string `json:"parent" validate:"omitempty,max=50"`
+}
+
+func (r *UpdateRoleRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type AssignUserRoleRequest struct {
+	RoleName string `json:"role" validate:"required,max=50"`
+}
+
+func (r *AssignUserRoleRequest) Validate(v *validator.Validate) error {
+	if err := v.Struct(r); err != nil {
+		return err
+	}
+	return nil
+}
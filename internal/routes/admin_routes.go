@@ -1,24 +1,61 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gorilla/mux"
 
+	"external-backend-go/internal/auth"
 	"external-backend-go/internal/handler"
 	"external-backend-go/internal/logger"
 	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/policy"
 	"external-backend-go/internal/store"
 )
 
-func setupAdminRoutes(router *mux.Router, authHandler *handler.AuthHandler, itemHandler *handler.ItemHandler, jwtSecret string, userStore store.UserStore, roleStore store.RoleStore, appLogger *logger.Logger) {
+func setupAdminRoutes(router *mux.Router, authHandler *handler.AuthHandler, itemHandler *handler.ItemHandler, roleHandler *handler.RoleHandler, userHandler *handler.UserHandler, privacyHandler *handler.PrivacyHandler, policyHandler *handler.PolicyHandler, tokens *auth.TokenConfig, sessionStore store.SessionStore, authVersionStore store.AuthVersionStore, permissionStore store.PermissionStore, roleHierarchyStore store.RoleHierarchyStore, policyEngine *policy.Engine, cookieAuth, mfaRequired bool, appLogger *logger.Logger) {
 	adminRouter := router.PathPrefix("/admin").Subrouter()
 
-	adminRouter.Use(middleware.AuthMiddleware(jwtSecret, appLogger))
-	adminRouter.Use(middleware.AuthRoleMiddleware("admin", userStore, roleStore, appLogger))
+	adminRouter.Use(middleware.AuthMiddleware(tokens, sessionStore, authVersionStore, cookieAuth, appLogger))
+	adminRouter.Use(middleware.RequireVerifiedEmail(appLogger))
+	if mfaRequired {
+		adminRouter.Use(middleware.RequireMFA(appLogger))
+	}
+	adminRouter.Use(middleware.PolicyMiddleware(policyEngine, appLogger))
+
+	// Each route requires its own permission, so a role can be given part of the
+	// admin area, e.g. editors manage the catalog but cannot touch user accounts.
+	requirePermission := func(permission string, h http.HandlerFunc) http.Handler {
+		return middleware.RequirePermission(permission, permissionStore, roleHierarchyStore, appLogger)(h)
+	}
 
-	adminRouter.HandleFunc("/items", itemHandler.CreateItem).Methods("POST")
-	adminRouter.HandleFunc("/items/{id}", itemHandler.UpdateItem).Methods("PUT")
-	adminRouter.HandleFunc("/items/{id}", itemHandler.DeleteItem).Methods("DELETE")
-	adminRouter.HandleFunc("/users/{id}/role", authHandler.UpdateUserRole).Methods("PUT")
+	adminRouter.Handle("/items", requirePermission(model.PermissionItemsWrite, itemHandler.CreateItem)).Methods("POST")
+	adminRouter.Handle("/items/{id}", requirePermission(model.PermissionItemsWrite, itemHandler.UpdateItem)).Methods("PUT")
+	adminRouter.Handle("/items/{id}", requirePermission(model.PermissionItemsWrite, itemHandler.DeleteItem)).Methods("DELETE")
+	adminRouter.Handle("/users", requirePermission(model.PermissionUsersRead, userHandler.GetUsers)).Methods("GET")
+	adminRouter.Handle("/users/{id}", requirePermission(model.PermissionUsersRead, userHandler.GetUser)).Methods("GET")
+	adminRouter.Handle("/users/{id}", requirePermission(model.PermissionUsersWrite, userHandler.SoftDeleteUser)).Methods("DELETE")
+	adminRouter.Handle("/users/{id}/restore", requirePermission(model.PermissionUsersWrite, userHandler.RestoreUser)).Methods("POST")
+	adminRouter.Handle("/users/{id}/verify-email", requirePermission(model.PermissionUsersWrite, userHandler.VerifyUserEmail)).Methods("POST")
+	adminRouter.Handle("/users/{id}/logout", requirePermission(model.PermissionUsersWrite, userHandler.LogoutUser)).Methods("POST")
+	adminRouter.Handle("/users/{id}/permanent", requirePermission(model.PermissionUsersWrite, userHandler.DeleteUser)).Methods("DELETE")
+	adminRouter.Handle("/users/{id}/export", requirePermission(model.PermissionUsersRead, privacyHandler.ExportUserData)).Methods("POST")
+	adminRouter.Handle("/users/{id}/erasure", requirePermission(model.PermissionUsersWrite, privacyHandler.RequestUserErasure)).Methods("POST")
+	adminRouter.Handle("/users/{id}/erasure", requirePermission(model.PermissionUsersWrite, privacyHandler.CancelUserErasure)).Methods("DELETE")
+	adminRouter.Handle("/users/{id}/role", requirePermission(model.PermissionUsersWrite, authHandler.UpdateUserRole)).Methods("PUT")
+	adminRouter.Handle("/users/{id}/roles", requirePermission(model.PermissionUsersRead, roleHandler.GetUserRoles)).Methods("GET")
+	adminRouter.Handle("/users/{id}/roles", requirePermission(model.PermissionUsersWrite, roleHandler.AssignUserRole)).Methods("POST")
+	adminRouter.Handle("/users/{id}/roles/{role}", requirePermission(model.PermissionUsersWrite, roleHandler.RemoveUserRole)).Methods("DELETE")
+	adminRouter.Handle("/users/{id}/unlock", requirePermission(model.PermissionUsersWrite, authHandler.UnlockUser)).Methods("POST")
+	adminRouter.Handle("/users/{id}/sessions", requirePermission(model.PermissionUsersRead, authHandler.ListUserSessions)).Methods("GET")
+	adminRouter.Handle("/users/{id}/sessions/{sessionId}", requirePermission(model.PermissionUsersWrite, authHandler.RevokeUserSession)).Methods("DELETE")
+	adminRouter.Handle("/roles", requirePermission(model.PermissionRolesRead, roleHandler.GetRoles)).Methods("GET")
+	adminRouter.Handle("/roles", requirePermission(model.PermissionRolesWrite, roleHandler.CreateRole)).Methods("POST")
+	adminRouter.Handle("/roles/{id}", requirePermission(model.PermissionRolesRead, roleHandler.GetRole)).Methods("GET")
+	adminRouter.Handle("/roles/{id}", requirePermission(model.PermissionRolesWrite, roleHandler.UpdateRole)).Methods("PUT")
+	adminRouter.Handle("/roles/{id}", requirePermission(model.PermissionRolesWrite, roleHandler.DeleteRole)).Methods("DELETE")
+	adminRouter.Handle("/policies/explain", requirePermission(model.PermissionPoliciesRead, policyHandler.Explain)).Methods("GET")
 
 	// adminRouter.HandleFunc("/categories", categoryHandler.CreateCategory).Methods("POST")
 }
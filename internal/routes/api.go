@@ -7,25 +7,38 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	_ "external-backend-go/docs"
+	"external-backend-go/internal/auth"
 	"external-backend-go/internal/handler"
 	"external-backend-go/internal/logger"
 	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/policy"
 	"external-backend-go/internal/store"
 	"external-backend-go/internal/utility"
 )
 
 type AppDependencies struct {
-	Router        *mux.Router
-	AuthHandler   *handler.AuthHandler
-	ItemHandler   *handler.ItemHandler
-	JWTSecret     string
-	UserStore     store.UserStore
-	RoleStore     store.RoleStore
-	RateLimiter   *middleware.RateLimiter
-	BasicAuthUser string
-	BasicAuthPass string
-	AppLogger     *logger.Logger
-	SearchStore   store.SearchStore
+	Router             *mux.Router
+	AuthHandler        *handler.AuthHandler
+	ItemHandler        *handler.ItemHandler
+	RoleHandler        *handler.RoleHandler
+	UserHandler        *handler.UserHandler
+	PrivacyHandler     *handler.PrivacyHandler
+	PolicyHandler      *handler.PolicyHandler
+	PolicyEngine       *policy.Engine
+	Tokens             *auth.TokenConfig
+	UserStore          store.UserStore
+	RoleStore          store.RoleStore
+	SessionStore       store.SessionStore
+	PermissionStore    store.PermissionStore
+	AuthVersionStore   store.AuthVersionStore
+	RoleHierarchyStore store.RoleHierarchyStore
+	MFARequired        bool
+	CookieAuth         bool
+	RateLimiter        *middleware.RateLimiter
+	BasicAuthUser      string
+	BasicAuthPass      string
+	AppLogger          *logger.Logger
+	SearchStore        store.SearchStore
 }
 
 func SetupAPIRoutes(deps AppDependencies) {
@@ -45,6 +58,7 @@ func SetupAPIRoutes(deps AppDependencies) {
 		deps.AuthHandler,
 		deps.BasicAuthUser,
 		deps.BasicAuthPass,
+		deps.PolicyEngine,
 		deps.AppLogger,
 	)
 
@@ -52,7 +66,14 @@ func SetupAPIRoutes(deps AppDependencies) {
 		apiV1Router,
 		deps.AuthHandler,
 		deps.ItemHandler,
-		deps.JWTSecret,
+		deps.PrivacyHandler,
+		deps.Tokens,
+		deps.SessionStore,
+		deps.AuthVersionStore,
+		deps.PermissionStore,
+		deps.RoleHierarchyStore,
+		deps.PolicyEngine,
+		deps.CookieAuth,
 		deps.AppLogger,
 	)
 
@@ -60,11 +81,22 @@ func SetupAPIRoutes(deps AppDependencies) {
 		apiV1Router,
 		deps.AuthHandler,
 		deps.ItemHandler,
-		deps.JWTSecret,
-		deps.UserStore,
-		deps.RoleStore,
+		deps.RoleHandler,
+		deps.UserHandler,
+		deps.PrivacyHandler,
+		deps.PolicyHandler,
+		deps.Tokens,
+		deps.SessionStore,
+		deps.AuthVersionStore,
+		deps.PermissionStore,
+		deps.RoleHierarchyStore,
+		deps.PolicyEngine,
+		deps.CookieAuth,
+		deps.MFARequired,
 		deps.AppLogger,
 	)
 
+	deps.Router.HandleFunc("/.well-known/jwks.json", deps.AuthHandler.JWKS).Methods("GET")
+
 	deps.Router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 }
@@ -1,21 +1,55 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gorilla/mux"
 
+	"external-backend-go/internal/auth"
 	"external-backend-go/internal/handler"
 	"external-backend-go/internal/logger"
 	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/policy"
+	"external-backend-go/internal/store"
 )
 
-func setupProtectedRoutes(router *mux.Router, authHandler *handler.AuthHandler, itemHandler *handler.ItemHandler, jwtSecret string, appLogger *logger.Logger) {
+func setupProtectedRoutes(router *mux.Router, authHandler *handler.AuthHandler, itemHandler *handler.ItemHandler, privacyHandler *handler.PrivacyHandler, tokens *auth.TokenConfig, sessionStore store.SessionStore, authVersionStore store.AuthVersionStore, permissionStore store.PermissionStore, roleHierarchyStore store.RoleHierarchyStore, policyEngine *policy.Engine, cookieAuth bool, appLogger *logger.Logger) {
 	protectedRouter := router.PathPrefix("").Subrouter()
-	protectedRouter.Use(middleware.AuthMiddleware(jwtSecret, appLogger))
+	protectedRouter.Use(middleware.AuthMiddleware(tokens, sessionStore, authVersionStore, cookieAuth, appLogger))
+	protectedRouter.Use(middleware.PolicyMiddleware(policyEngine, appLogger))
 
 	protectedRouter.HandleFunc("/protected", authHandler.ProtectedEndpoint).Methods("GET")
+	protectedRouter.HandleFunc("/logout", authHandler.Logout).Methods("POST")
+	protectedRouter.HandleFunc("/logout-all", authHandler.LogoutAll).Methods("POST")
+	protectedRouter.HandleFunc("/me", authHandler.GetMyProfile).Methods("GET")
+	protectedRouter.HandleFunc("/me", authHandler.UpdateMyProfile).Methods("PATCH")
+	protectedRouter.HandleFunc("/me", privacyHandler.RequestMyErasure).Methods("DELETE")
+	protectedRouter.HandleFunc("/me/erasure", privacyHandler.CancelMyErasure).Methods("DELETE")
+	protectedRouter.HandleFunc("/me/export", privacyHandler.ExportMyData).Methods("POST")
+	protectedRouter.HandleFunc("/me/password", authHandler.ChangeMyPassword).Methods("POST")
+	protectedRouter.HandleFunc("/me/email", authHandler.ChangeMyEmail).Methods("POST")
+	protectedRouter.HandleFunc("/me/2fa/enroll", authHandler.EnrollMFA).Methods("POST")
+	protectedRouter.HandleFunc("/me/2fa/confirm", authHandler.ConfirmMFA).Methods("POST")
+	protectedRouter.HandleFunc("/me/2fa/disable", authHandler.DisableMFA).Methods("POST")
+	protectedRouter.HandleFunc("/me/sessions", authHandler.ListMySessions).Methods("GET")
+	protectedRouter.HandleFunc("/me/sessions/{id}", authHandler.RevokeMySession).Methods("DELETE")
+
+	// Routes below are refused to users who logged in without a verified email
+	// address while unverified logins run in restricted mode.
+	verifiedRouter := protectedRouter.PathPrefix("").Subrouter()
+	verifiedRouter.Use(middleware.RequireVerifiedEmail(appLogger))
+
+	itemsRouter := verifiedRouter.PathPrefix("/items").Subrouter()
+	itemsRouter.Use(middleware.RequirePermission(model.PermissionItemsRead, permissionStore, roleHierarchyStore, appLogger))
 
-	protectedRouter.HandleFunc("/items", itemHandler.GetItems).Methods("GET")
-	protectedRouter.HandleFunc("/items/{id}", itemHandler.GetItem).Methods("GET")
+	itemsRouter.HandleFunc("", itemHandler.GetItems).Methods("GET")
+	itemsRouter.HandleFunc("/{id}", itemHandler.GetItem).Methods("GET")
 
-	// protectedRouter.HandleFunc("/profile", userHandler.GetUserProfile).Methods("GET")
+	// Authors write items here; ownership is checked per item by the handler, with
+	// admins allowed to change any item.
+	requireWriteOwn := middleware.RequirePermission(model.PermissionItemsWriteOwn, permissionStore, roleHierarchyStore, appLogger)
+	itemsRouter.Handle("", requireWriteOwn(http.HandlerFunc(itemHandler.CreateItem))).Methods("POST")
+	itemsRouter.Handle("/{id}", requireWriteOwn(http.HandlerFunc(itemHandler.UpdateOwnItem))).Methods("PUT")
+	itemsRouter.Handle("/{id}", requireWriteOwn(http.HandlerFunc(itemHandler.DeleteOwnItem))).Methods("DELETE")
 }
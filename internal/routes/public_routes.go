@@ -8,17 +8,30 @@ import (
 	"external-backend-go/internal/handler"
 	"external-backend-go/internal/logger"
 	"external-backend-go/internal/middleware"
+	"external-backend-go/internal/policy"
 	"external-backend-go/internal/utility"
 )
 
-func setupPublicRoutes(router *mux.Router, authHandler *handler.AuthHandler, basicAuthUser, basicAuthPass string, appLogger *logger.Logger) {
-	router.HandleFunc("/register", authHandler.RegisterUser).Methods("POST")
-	router.HandleFunc("/login", authHandler.LoginUser).Methods("POST")
-	// router.HandleFunc("/verify-email", authHandler.VerifyEmail).Methods("GET")
-	// router.HandleFunc("/forgot-password", authHandler.ForgotPassword).Methods("POST")
-	// router.HandleFunc("/reset-password", authHandler.ResetPassword).Methods("POST")
+func setupPublicRoutes(router *mux.Router, authHandler *handler.AuthHandler, basicAuthUser, basicAuthPass string, policyEngine *policy.Engine, appLogger *logger.Logger) {
+	publicRouter := router.PathPrefix("").Subrouter()
+	publicRouter.Use(middleware.PolicyMiddleware(policyEngine, appLogger))
 
-	basicAuthRouter := router.PathPrefix("/basic-auth").Subrouter()
+	publicRouter.HandleFunc("/register", authHandler.RegisterUser).Methods("POST")
+	publicRouter.HandleFunc("/login", authHandler.LoginUser).Methods("POST")
+	publicRouter.HandleFunc("/login/2fa", authHandler.LoginMFA).Methods("POST")
+	publicRouter.HandleFunc("/login/remember", authHandler.LoginWithRememberMe).Methods("POST")
+	publicRouter.HandleFunc("/login/magic-link", authHandler.RequestMagicLink).Methods("POST")
+	publicRouter.HandleFunc("/login/magic-link/verify", authHandler.VerifyMagicLink).Methods("GET")
+	publicRouter.HandleFunc("/login/oidc/{provider}", authHandler.StartOIDCLogin).Methods("GET")
+	publicRouter.HandleFunc("/login/oidc/{provider}/callback", authHandler.CompleteOIDCLogin).Methods("GET")
+	publicRouter.HandleFunc("/token/refresh", authHandler.RefreshToken).Methods("POST")
+	publicRouter.HandleFunc("/verify-email", authHandler.VerifyEmail).Methods("GET")
+	publicRouter.HandleFunc("/verify-email/resend", authHandler.ResendVerificationEmail).Methods("POST")
+	publicRouter.HandleFunc("/confirm-email-change", authHandler.ConfirmEmailChange).Methods("GET")
+	publicRouter.HandleFunc("/forgot-password", authHandler.ForgotPassword).Methods("POST")
+	publicRouter.HandleFunc("/reset-password", authHandler.ResetPassword).Methods("POST")
+
+	basicAuthRouter := publicRouter.PathPrefix("/basic-auth").Subrouter()
 	basicAuthRouter.Use(middleware.BasicAuthMiddleware(basicAuthUser, basicAuthPass, func(w http.ResponseWriter, r *http.Request, err error) {
 		utility.UnauthorizedBasicErrorResponse(w, r, err, appLogger)
 	}))
@@ -2,19 +2,22 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 
 	"external-backend-go/db/sqlc"
 	"external-backend-go/internal/auth"
 	"external-backend-go/internal/email"
+	"external-backend-go/internal/logger"
 	"external-backend-go/internal/model"
+	"external-backend-go/internal/password"
 	"external-backend-go/internal/store"
 )
 
@@ -25,30 +28,107 @@ var (
 	ErrInvalidToken         = errors.New("invalid or expired token")
 	ErrEmailAlreadyVerified = errors.New("email already verified")
 	ErrUserAlreadyExists    = errors.New("user with this username or email already exists")
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected")
+	ErrEmailNotVerified     = errors.New("email address is not verified")
 )
 
-type AuthService struct {
+// AuthTokens is what a client receives after a successful login or refresh. When the
+// user has two-factor authentication enabled, a password login only yields MFAToken,
+// which has to be exchanged at /login/2fa for the actual token pair. RememberToken is
+// only set when the user asked to be remembered.
+type AuthTokens struct {
+	SessionID         string
+	AccessToken       string
+	RefreshToken      string
+	ExpiresIn         int64
+	Role              string
+	Roles             []string
+	MFARequired       bool
+	MFAToken          string
+	RememberToken     string
+	RememberExpiresAt time.Time
+}
+
+// AuthSettings groups the configurable parameters of AuthService.
+type AuthSettings struct {
+	AccessTokenTTL     time.Duration
+	RefreshTokenTTL    time.Duration
+	MFAIssuer          string
+	MFAPendingTokenTTL time.Duration
+	LoginProtection    LoginProtectionSettings
+	// FrontendURL is the base URL of the web app that links in emails point to.
+	FrontendURL          string
+	EmailVerificationTTL time.Duration
+	// UnverifiedLoginMode is one of UnverifiedLoginAllow, UnverifiedLoginRestricted
+	// or UnverifiedLoginDeny.
+	UnverifiedLoginMode string
+	PasswordResetTTL    time.Duration
+	MagicLinkTTL        time.Duration
+	RememberMeTTL       time.Duration
+	// OIDCAuthRequestTTL is how long a user has to complete a login at an OIDC
+	// provider.
+	OIDCAuthRequestTTL time.Duration
+}
+
+// AuthStores are the stores AuthService reads and writes.
+type AuthStores struct {
 	UserStore               store.UserStore
 	RoleStore               store.RoleStore
+	UserRoleStore           store.UserRoleStore
 	SessionStore            store.SessionStore
 	PasswordResetTokenStore store.PasswordResetTokenStore
-	JWTSecret               string
-	EmailSender             email.EmailSender
+	MFAStore                store.MFAStore
+	LoginThrottleStore      store.LoginThrottleStore
+	EmailVerificationStore  store.EmailVerificationTokenStore
+	EmailChangeStore        store.EmailChangeTokenStore
+	MagicLinkStore          store.MagicLinkStore
+	RememberTokenStore      store.RememberTokenStore
+	AuthVersionStore        store.AuthVersionStore
+	UserIdentityStore       store.UserIdentityStore
+	OIDCAuthRequestStore    store.OIDCAuthRequestStore
+}
+
+type AuthService struct {
+	AuthStores
+	Tokens         *auth.TokenConfig
+	SecretBox      *auth.SecretBox
+	PasswordHasher *password.Hasher
+	PasswordPolicy *password.Policy
+	EmailSender    email.EmailSender
+	// OIDCProviders are the external providers users can log in with, by name.
+	OIDCProviders map[string]*OIDCProvider
+	Settings      AuthSettings
 }
 
-func NewAuthService(userStore store.UserStore, roleStore store.RoleStore, sessionStore store.SessionStore, passwordResetTokenStore store.PasswordResetTokenStore, jwtSecret string, emailSender email.EmailSender) *AuthService {
+func NewAuthService(stores AuthStores, tokens *auth.TokenConfig, secretBox *auth.SecretBox, passwordHasher *password.Hasher, passwordPolicy *password.Policy, emailSender email.EmailSender, oidcProviders map[string]*OIDCProvider, settings AuthSettings) *AuthService {
 	return &AuthService{
-		UserStore:               userStore,
-		RoleStore:               roleStore,
-		SessionStore:            sessionStore,
-		PasswordResetTokenStore: passwordResetTokenStore,
-		JWTSecret:               jwtSecret,
-		EmailSender:             emailSender,
+		AuthStores:     stores,
+		Tokens:         tokens,
+		SecretBox:      secretBox,
+		PasswordHasher: passwordHasher,
+		PasswordPolicy: passwordPolicy,
+		EmailSender:    emailSender,
+		OIDCProviders:  oidcProviders,
+		Settings:       settings,
 	}
 }
 
+// sessionPayload is the JSON stored in sessions.payload. It carries what is needed to
+// reissue access tokens on refresh without repeating the login.
+type sessionPayload struct {
+	Username string   `json:"username"`
+	Role     string   `json:"role"`
+	AMR      []string `json:"amr,omitempty"`
+}
+
+// RegisterUser creates an account. A password that breaks the password policy is
+// rejected with a *password.PolicyError.
 func (s *AuthService) RegisterUser(ctx context.Context, username, password, email, roleName string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err := s.PasswordPolicy.Check(password, username, email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.PasswordHasher.Hash(password)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -63,131 +143,328 @@ func (s *AuthService) RegisterUser(ctx context.Context, username, password, emai
 
 	arg := sqlc.CreateUserParams{
 		Username:       username,
-		HashedPassword: string(hashedPassword),
+		HashedPassword: hashedPassword,
 		Email:          email,
 		RoleID:         role.ID,
 	}
 
-	_, err = s.UserStore.CreateUser(ctx, arg)
+	dbUser, err := s.UserStore.CreateUser(ctx, arg)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
+
+	// The account exists at this point; if the email cannot be sent the user can ask
+	// for another one through the resend endpoint.
+	if err := s.sendVerificationEmail(ctx, dbUser); err != nil {
+		logger.Error("Failed to send verification email to user %d: %v", dbUser.ID, err)
+	}
 	return nil
 }
 
-func (s *AuthService) LoginUser(ctx context.Context, username, password, ipAddress, userAgent string) (string, string, error) {
+// LoginUser checks the username and password. With rememberMe, the completed login also
+// yields a remember-me token, see LoginWithRememberToken.
+func (s *AuthService) LoginUser(ctx context.Context, username, password string, rememberMe bool, ipAddress, userAgent string) (*AuthTokens, error) {
+	if err := s.checkLoginAllowed(ctx, username, ipAddress); err != nil {
+		return nil, err
+	}
+
 	dbUser, err := s.UserStore.GetUserByUsername(ctx, username)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", "", ErrUserNotFound
+			s.PasswordHasher.VerifyDummy(password)
+			if err := s.recordLoginFailure(ctx, username, ipAddress, nil); err != nil {
+				return nil, err
+			}
+			return nil, ErrUserNotFound
 		}
-		return "", "", fmt.Errorf("failed to get user by username: %w", err)
+		return nil, fmt.Errorf("failed to get user by username: %w", err)
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(dbUser.HashedPassword), []byte(password))
+	rehash, err := s.PasswordHasher.Verify(dbUser.HashedPassword, password)
 	if err != nil {
-		return "", "", ErrIncorrectPassword
+		if err := s.recordLoginFailure(ctx, username, ipAddress, &dbUser); err != nil {
+			return nil, err
+		}
+		return nil, ErrIncorrectPassword
+	}
+	s.clearLoginFailures(ctx, username)
+	if rehash {
+		s.rehashPassword(ctx, dbUser.ID, password)
 	}
 
-	role, err := s.RoleStore.GetByID(ctx, dbUser.RoleID)
+	return s.completeFirstFactor(ctx, dbUser, auth.AMRPassword, rememberMe, ipAddress, userAgent)
+}
+
+// rehashPassword replaces the stored hash of a user who just logged in with one made
+// by the current algorithm and parameters. A failure only delays the upgrade to the
+// next login, so it does not fail the login.
+func (s *AuthService) rehashPassword(ctx context.Context, userID int32, plaintext string) {
+	hashedPassword, err := s.PasswordHasher.Hash(plaintext)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get user role: %w", err)
+		logger.Error("Failed to rehash password of user %d: %v", userID, err)
+		return
+	}
+	if _, err := s.UserStore.UpdateUserPassword(ctx, userID, hashedPassword); err != nil {
+		logger.Error("Failed to store rehashed password of user %d: %v", userID, err)
+		return
 	}
+	logger.Info("Upgraded password hash of user %d to %s", userID, s.PasswordHasher.Algorithm())
+}
 
-	// Create session
-	sessionPayload, err := json.Marshal(map[string]string{"username": dbUser.Username, "role": role.Name})
-	if err != nil {
-		return "", "", fmt.Errorf("failed to marshal session payload: %w", err)
+// completeFirstFactor finishes a login once the user passed the first factor, either
+// a password or a magic link. It returns an MFA pending token when a second factor is
+// still required, and a full session otherwise. The pending token remembers rememberMe
+// until the second factor is verified.
+func (s *AuthService) completeFirstFactor(ctx context.Context, dbUser sqlc.User, method string, rememberMe bool, ipAddress, userAgent string) (*AuthTokens, error) {
+	if s.Settings.UnverifiedLoginMode == UnverifiedLoginDeny && !dbUser.EmailVerifiedAt.Valid {
+		return nil, ErrEmailNotVerified
 	}
 
-	session := &model.Session{
-		ID:           uuid.New().String(),
-		UserID:       sql.NullInt32{Int32: dbUser.ID, Valid: true},
-		IpAddress:    model.NullString{String: ipAddress, Valid: ipAddress != ""},
-		UserAgent:    model.NullString{String: userAgent, Valid: userAgent != ""},
-		Payload:      string(sessionPayload),
-		LastActivity: int32(time.Now().Unix()),
+	mfaEnabled, err := s.isMFAEnabled(ctx, dbUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mfaEnabled {
+		mfaToken, err := auth.GenerateMFAPendingToken(dbUser.ID, []string{method}, rememberMe, s.Tokens, s.Settings.MFAPendingTokenTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate MFA pending token: %w", err)
+		}
+		return &AuthTokens{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int64(s.Settings.MFAPendingTokenTTL.Seconds()),
+		}, nil
 	}
 
-	_, err = s.SessionStore.CreateSession(ctx, session)
+	return s.startSession(ctx, dbUser, []string{method}, rememberMe, ipAddress, userAgent)
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. The refresh token is
+// rotated on every call; presenting a token that has already been rotated is treated
+// as theft and revokes the whole session.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, ipAddress, userAgent string) (*AuthTokens, error) {
+	sessionID, err := auth.ParseRefreshToken(refreshToken)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create session: %w", err)
+		return nil, ErrInvalidToken
 	}
 
-	tokenString, err := auth.GenerateToken(dbUser.ID, dbUser.Username, role.Name, s.JWTSecret)
+	session, err := s.SessionStore.GetSessionByID(ctx, sessionID)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to generate token: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get session for refresh: %w", err)
 	}
 
-	return tokenString, role.Name, nil
-}
+	presentedHash := auth.HashToken(refreshToken)
+	if !session.RefreshTokenHash.Valid || subtle.ConstantTimeCompare([]byte(session.RefreshTokenHash.String), []byte(presentedHash)) != 1 {
+		// Only a token the session really had before is evidence of theft. Anything
+		// else is just rejected, or anyone who knows a session ID could log it out.
+		if session.PreviousRefreshTokenHash.Valid && subtle.ConstantTimeCompare([]byte(session.PreviousRefreshTokenHash.String), []byte(presentedHash)) == 1 {
+			s.revokeSession(ctx, session.ID)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrInvalidToken
+	}
+
+	if !session.RefreshExpiresAt.Valid || time.Now().After(session.RefreshExpiresAt.Time) {
+		s.revokeSession(ctx, session.ID)
+		return nil, ErrInvalidToken
+	}
 
-// VerifyEmail verifies the user's email address.
-func (s *AuthService) VerifyEmail(ctx context.Context, userID int32, token string) error {
-	user, err := s.UserStore.GetUserByID(ctx, userID)
+	if !session.UserID.Valid {
+		return nil, ErrInvalidToken
+	}
+	dbUser, err := s.UserStore.GetUserByID(ctx, session.UserID.Int32)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrUserNotFound
+			return nil, ErrInvalidToken
 		}
-		return fmt.Errorf("failed to get user for email verification: %w", err)
+		return nil, fmt.Errorf("failed to get user for refresh: %w", err)
 	}
 
-	if user.EmailVerifiedAt.Valid {
-		return ErrEmailAlreadyVerified
+	roleName, roleNames, err := s.userRoles(ctx, dbUser)
+	if err != nil {
+		return nil, err
 	}
 
-	if token == "" {
-		return ErrInvalidToken
+	newRefreshToken, newRefreshTokenHash, err := auth.GenerateRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session.RefreshTokenHash = model.NullString{String: newRefreshTokenHash, Valid: true}
+	session.RefreshExpiresAt = model.NullTime{Time: now.Add(s.Settings.RefreshTokenTTL), Valid: true}
+	session.IpAddress = model.NullString{String: ipAddress, Valid: ipAddress != ""}
+	session.UserAgent = model.NullString{String: userAgent, Valid: userAgent != ""}
+	session.LastActivity = int32(now.Unix())
+
+	_, err = s.SessionStore.RotateRefreshToken(ctx, session, presentedHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another request rotated this token between our read and write.
+			s.revokeSession(ctx, session.ID)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	var payload sessionPayload
+	if err := json.Unmarshal([]byte(session.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session payload: %w", err)
 	}
 
-	_, err = s.UserStore.VerifyUserEmail(ctx, userID)
+	accessToken, err := auth.GenerateToken(dbUser.ID, dbUser.Username, roleName, roleNames, session.ID, dbUser.AuthVersion, s.tokenScope(dbUser), payload.AMR, s.Tokens, s.Settings.AccessTokenTTL)
 	if err != nil {
-		return fmt.Errorf("failed to verify user email in store: %w", err)
+		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(s.Settings.AccessTokenTTL.Seconds()),
+		Role:         roleName,
+		Roles:        roleNames,
+	}, nil
+}
+
+// Logout revokes a single session and the remember-me token of its device, so logging
+// out is not undone by the next remember-me login. Other devices stay remembered.
+// Access tokens carrying the session ID stop being accepted as soon as the revocation
+// reaches AuthMiddleware's session cache.
+func (s *AuthService) Logout(ctx context.Context, userID int32, sessionID string) error {
+	if err := s.SessionStore.DeleteSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if err := s.RememberTokenStore.DeleteBySessionID(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke remember-me token: %w", err)
+	}
 	return nil
 }
 
+// LogoutAll revokes every session that belongs to userID and forgets all their devices.
+func (s *AuthService) LogoutAll(ctx context.Context, userID int32) error {
+	if err := s.SessionStore.DeleteSessionsByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	if err := s.RememberTokenStore.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke remember-me tokens: %w", err)
+	}
+	return nil
+}
+
+// userRoles returns the name of dbUser's primary role and the names of every role
+// assigned to them.
+func (s *AuthService) userRoles(ctx context.Context, dbUser sqlc.User) (string, []string, error) {
+	role, err := s.RoleStore.GetByID(ctx, dbUser.RoleID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get user role: %w", err)
+	}
+	roleNames, err := s.UserRoleStore.ListRoleNames(ctx, dbUser.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	return role.Name, roleNames, nil
+}
+
+// createSession persists a new session for dbUser and issues its first token pair.
+// amr lists the authentication methods the user passed to get here.
+func (s *AuthService) createSession(ctx context.Context, dbUser sqlc.User, amr []string, ipAddress, userAgent string) (*AuthTokens, error) {
+	roleName, roleNames, err := s.userRoles(ctx, dbUser)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(sessionPayload{Username: dbUser.Username, Role: roleName, AMR: amr})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session payload: %w", err)
+	}
+
+	sessionID := uuid.New().String()
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &model.Session{
+		ID:               sessionID,
+		UserID:           sql.NullInt32{Int32: dbUser.ID, Valid: true},
+		IpAddress:        model.NullString{String: ipAddress, Valid: ipAddress != ""},
+		UserAgent:        model.NullString{String: userAgent, Valid: userAgent != ""},
+		Payload:          string(payload),
+		LastActivity:     int32(now.Unix()),
+		RefreshTokenHash: model.NullString{String: refreshTokenHash, Valid: true},
+		RefreshExpiresAt: model.NullTime{Time: now.Add(s.Settings.RefreshTokenTTL), Valid: true},
+	}
+
+	_, err = s.SessionStore.CreateSession(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	accessToken, err := auth.GenerateToken(dbUser.ID, dbUser.Username, roleName, roleNames, sessionID, dbUser.AuthVersion, s.tokenScope(dbUser), amr, s.Tokens, s.Settings.AccessTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &AuthTokens{
+		SessionID:    sessionID,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.Settings.AccessTokenTTL.Seconds()),
+		Role:         roleName,
+		Roles:        roleNames,
+	}, nil
+}
+
+// revokeSession deletes a session and forgets its device on a best-effort basis; the
+// caller has already decided to reject the request, so a failure here is only logged.
+func (s *AuthService) revokeSession(ctx context.Context, sessionID string) {
+	if err := s.SessionStore.DeleteSession(ctx, sessionID); err != nil {
+		logger.Error("Failed to revoke session %s: %v", sessionID, err)
+	}
+	if err := s.RememberTokenStore.DeleteBySessionID(ctx, sessionID); err != nil {
+		logger.Error("Failed to revoke remember-me token of session %s: %v", sessionID, err)
+	}
+}
+
+// ForgotPassword emails a single-use reset link. Unknown addresses are ignored
+// silently so the response does not reveal which emails have accounts.
 func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
-	_, err := s.UserStore.GetUserByEmail(ctx, email)
+	user, err := s.UserStore.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrUserNotFound
+			return nil
 		}
 		return fmt.Errorf("failed to get user by email for password reset: %w", err)
 	}
 
-	token := uuid.New().String()
-	expiryTime := time.Now().Add(15 * time.Minute)
-
-	existingToken, err := s.PasswordResetTokenStore.GetPasswordResetToken(ctx, email)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("failed to check existing password reset token: %w", err)
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
 	}
 
-	if existingToken != nil {
-		err = s.PasswordResetTokenStore.DeletePasswordResetToken(ctx, email)
-		if err != nil {
-			return fmt.Errorf("failed to delete old password reset token: %w", err)
-		}
-	}
-	_, err = s.PasswordResetTokenStore.CreatePasswordResetToken(ctx, email, token, expiryTime)
+	// Creating a token replaces any previous one for the same email.
+	expiresAt := time.Now().Add(s.Settings.PasswordResetTTL)
+	_, err = s.PasswordResetTokenStore.CreatePasswordResetToken(ctx, user.Email, tokenHash, expiresAt)
 	if err != nil {
 		return fmt.Errorf("failed to create password reset token: %w", err)
 	}
 
 	// Construct email body with reset link
-	resetLink := fmt.Sprintf("http://localhost:8080/reset-password?email=%s&token=%s", email, token)
+	resetLink := fmt.Sprintf("%s/reset-password?email=%s&token=%s", s.Settings.FrontendURL, url.QueryEscape(user.Email), url.QueryEscape(token))
 	body := fmt.Sprintf(`
-		<p>Hello,</p>
+		<p>Hello %s,</p>
 		<p>You have requested to reset your password. Please click the link below to reset it:</p>
 		<p><a href="%s">%s</a></p>
-		<p>This link will expire in 15 minutes.</p>
+		<p>This link will expire in %s.</p>
 		<p>If you did not request a password reset, please ignore this email.</p>
-	`, resetLink, resetLink)
+	`, user.Username, resetLink, resetLink, s.Settings.PasswordResetTTL)
 
 	// Send email
-	err = s.EmailSender.SendEmail(email, "Password Reset Request", body)
+	err = s.EmailSender.SendEmail(user.Email, "Password Reset Request", body)
 	if err != nil {
 		return fmt.Errorf("failed to send password reset email: %w", err)
 	}
@@ -195,7 +472,12 @@ func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
 	return nil
 }
 
+// ResetPassword sets a new password using a token from ForgotPassword. On success all
+// of the user's sessions are revoked and a confirmation email is sent. A password that
+// breaks the password policy is rejected with a *password.PolicyError and the token
+// stays valid, so the user can try another one.
 func (s *AuthService) ResetPassword(ctx context.Context, email, token, newPassword string) error {
+	tokenHash := auth.HashToken(token)
 	resetToken, err := s.PasswordResetTokenStore.GetPasswordResetToken(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -203,30 +485,41 @@ func (s *AuthService) ResetPassword(ctx context.Context, email, token, newPasswo
 		}
 		return fmt.Errorf("failed to get password reset token: %w", err)
 	}
-
-	if resetToken.Token != token || !resetToken.CreatedAt.Valid || time.Now().After(resetToken.CreatedAt.Time.Add(15*time.Minute)) {
+	if subtle.ConstantTimeCompare([]byte(resetToken.TokenHash), []byte(tokenHash)) != 1 || !time.Now().Before(resetToken.ExpiresAt) {
 		return ErrInvalidToken
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	user, err := s.UserStore.GetUserByEmail(ctx, email)
 	if err != nil {
-		return fmt.Errorf("failed to hash new password: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get user by email for password reset: %w", err)
+	}
+	if err := s.PasswordPolicy.Check(newPassword, user.Username, user.Email); err != nil {
+		return err
 	}
 
-	user, err := s.UserStore.GetUserByEmail(ctx, email)
+	if _, err := s.PasswordResetTokenStore.ConsumePasswordResetToken(ctx, email, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	hashedPassword, err := s.PasswordHasher.Hash(newPassword)
 	if err != nil {
-		return fmt.Errorf("failed to get user by email for password reset: %w", err)
+		return fmt.Errorf("failed to hash new password: %w", err)
 	}
 
 	arg := sqlc.UpdateUserParams{
-		ID:                user.ID,
-		Username:          user.Username,
-		HashedPassword:    string(hashedPassword),
-		Email:             user.Email,
-		EmailVerifiedAt:   user.EmailVerifiedAt,
-		RoleID:            user.RoleID,
-		RememberTokenUuid: user.RememberTokenUuid,
-		DeletedAt:         user.DeletedAt,
+		ID:              user.ID,
+		Username:        user.Username,
+		HashedPassword:  hashedPassword,
+		Email:           user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		RoleID:          user.RoleID,
+		DeletedAt:       user.DeletedAt,
 	}
 
 	_, err = s.UserStore.UpdateUser(ctx, arg)
@@ -234,17 +527,33 @@ func (s *AuthService) ResetPassword(ctx context.Context, email, token, newPasswo
 		return fmt.Errorf("failed to update user password: %w", err)
 	}
 
-	err = s.PasswordResetTokenStore.DeletePasswordResetToken(ctx, email)
-	if err != nil {
-		return fmt.Errorf("failed to delete password reset token: %w", err)
+	if err := s.SessionStore.DeleteSessionsByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke sessions after password reset: %w", err)
+	}
+	if err := s.RememberTokenStore.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke remember-me tokens after password reset: %w", err)
+	}
+	if _, err := s.AuthVersionStore.BumpAuthVersion(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to bump auth version after password reset: %w", err)
+	}
+	s.clearLoginFailures(ctx, user.Username)
+
+	body := fmt.Sprintf(`
+		<p>Hello %s,</p>
+		<p>Your password has just been changed and you have been signed out on all devices.</p>
+		<p>If you did not make this change, please reset your password immediately and contact support.</p>
+	`, user.Username)
+	if err := s.EmailSender.SendEmail(user.Email, "Your password has been changed", body); err != nil {
+		logger.Error("Failed to send password change confirmation to user %d: %v", user.ID, err)
 	}
 
 	return nil
 }
 
+// UpdateUserRole changes the user's primary role. The new role replaces the old
+// primary role in user_roles; other assigned roles are left alone.
 func (s *AuthService) UpdateUserRole(ctx context.Context, userID int32, newRoleName string) (sqlc.User, error) {
-	_, err := s.UserStore.GetUserByID(ctx, userID)
-	if err != nil {
+	if _, err := s.UserStore.GetUserByID(ctx, userID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return sqlc.User{}, ErrUserNotFound
 		}
@@ -259,12 +568,12 @@ func (s *AuthService) UpdateUserRole(ctx context.Context, userID int32, newRoleN
 		return sqlc.User{}, fmt.Errorf("failed to get new role by name: %w", err)
 	}
 
-	updatedUser, err := s.UserStore.UpdateUserRole(ctx, sqlc.UpdateUserRoleParams{
-		ID:     userID,
-		RoleID: newRole.ID,
-	})
+	// Tokens issued under the old role must not keep its permissions until they
+	// expire, so the store bumps the auth version along with the change.
+	updatedUser, err := s.UserStore.UpdateUserRole(ctx, userID, newRole.ID)
 	if err != nil {
 		return sqlc.User{}, fmt.Errorf("failed to update user role: %w", err)
 	}
+	s.AuthVersionStore.Forget(userID)
 	return updatedUser, nil
 }
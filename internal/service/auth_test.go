@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/model"
+)
+
+const refreshTestSessionID = "session-1"
+
+// newRefreshTest returns an AuthService with one user, alice, and a session of hers,
+// together with the session's refresh token.
+func newRefreshTest(t *testing.T) (*AuthService, *fakeSessionStore, string) {
+	t.Helper()
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken(refreshTestSessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := &fakeSessionStore{sessions: map[string]model.Session{
+		refreshTestSessionID: {
+			ID:               refreshTestSessionID,
+			UserID:           sql.NullInt32{Int32: 1, Valid: true},
+			Payload:          `{"username":"alice","role":"user"}`,
+			RefreshTokenHash: model.NullString{String: refreshTokenHash, Valid: true},
+			RefreshExpiresAt: model.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		},
+	}}
+	service := &AuthService{
+		AuthStores: AuthStores{
+			UserStore:          &fakeUserStore{users: []sqlc.User{{ID: 1, Username: "alice", RoleID: 2}}},
+			RoleStore:          &fakeRoleStore{roles: []model.Role{{ID: 1, Name: "admin"}, {ID: 2, Name: "user"}}},
+			UserRoleStore:      &fakeUserRoleStore{},
+			SessionStore:       sessions,
+			RememberTokenStore: &fakeRememberTokenStore{},
+		},
+		Tokens: &auth.TokenConfig{
+			Keys:     auth.NewHMACKeyRing("test-secret"),
+			Issuer:   "test",
+			Audience: "test",
+		},
+		Settings: AuthSettings{AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour},
+	}
+	return service, sessions, refreshToken
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	service, sessions, first := newRefreshTest(t)
+	ctx := context.Background()
+
+	second, err := service.RefreshToken(ctx, first, "", "")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if second.RefreshToken == first {
+		t.Error("refresh token was not rotated")
+	}
+	if second.AccessToken == "" {
+		t.Error("no access token was issued")
+	}
+
+	// The new refresh token keeps working and is rotated in turn.
+	if _, err := service.RefreshToken(ctx, second.RefreshToken, "", ""); err != nil {
+		t.Fatalf("RefreshToken with the rotated token: %v", err)
+	}
+	if _, ok := sessions.sessions[refreshTestSessionID]; !ok {
+		t.Error("session was revoked by a normal refresh")
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	service, sessions, first := newRefreshTest(t)
+	ctx := context.Background()
+
+	second, err := service.RefreshToken(ctx, first, "", "")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	if _, err := service.RefreshToken(ctx, first, "", ""); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing a rotated token: error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, ok := sessions.sessions[refreshTestSessionID]; ok {
+		t.Error("session survived the reuse of a rotated refresh token")
+	}
+	// The token the legitimate client holds stops working as well.
+	if _, err := service.RefreshToken(ctx, second.RefreshToken, "", ""); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("refresh after revocation: error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestRefreshTokenRejectsUnknownTokens(t *testing.T) {
+	service, sessions, _ := newRefreshTest(t)
+	ctx := context.Background()
+
+	// A made-up token for a real session is rejected without logging the session out,
+	// or anyone who knows a session ID could.
+	forged, _, err := auth.GenerateRefreshToken(refreshTestSessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.RefreshToken(ctx, forged, "", ""); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("forged token: error = %v, want ErrInvalidToken", err)
+	}
+	if _, ok := sessions.sessions[refreshTestSessionID]; !ok {
+		t.Error("session was revoked by a forged refresh token")
+	}
+
+	if _, err := service.RefreshToken(ctx, "malformed", "", ""); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("malformed token: error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestRefreshTokenRejectsExpiredTokens(t *testing.T) {
+	service, sessions, first := newRefreshTest(t)
+	session := sessions.sessions[refreshTestSessionID]
+	session.RefreshExpiresAt = model.NullTime{Time: time.Now().Add(-time.Second), Valid: true}
+	sessions.sessions[refreshTestSessionID] = session
+
+	if _, err := service.RefreshToken(context.Background(), first, "", ""); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expired token: error = %v, want ErrInvalidToken", err)
+	}
+	if _, ok := sessions.sessions[refreshTestSessionID]; ok {
+		t.Error("session with an expired refresh token was not revoked")
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/auth"
+)
+
+// Modes for logins of users whose email address is not verified.
+const (
+	// UnverifiedLoginAllow issues normal tokens.
+	UnverifiedLoginAllow = "allow"
+	// UnverifiedLoginRestricted issues tokens with the "unverified" scope, which
+	// routes guarded by middleware.RequireVerifiedEmail refuse.
+	UnverifiedLoginRestricted = "restricted"
+	// UnverifiedLoginDeny refuses the login until the address is verified.
+	UnverifiedLoginDeny = "deny"
+)
+
+// VerifyEmail redeems a verification token and marks the address it was sent to as
+// verified. Tokens are single-use and are rejected once the user's email changed.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	verification, err := s.EmailVerificationStore.Consume(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to consume email verification token: %w", err)
+	}
+
+	user, err := s.UserStore.GetUserByID(ctx, verification.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get user for email verification: %w", err)
+	}
+
+	if user.Email != verification.Email {
+		return ErrInvalidToken
+	}
+	if user.EmailVerifiedAt.Valid {
+		return ErrEmailAlreadyVerified
+	}
+
+	_, err = s.UserStore.VerifyUserEmail(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to verify user email in store: %w", err)
+	}
+
+	if err := s.EmailVerificationStore.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification link, invalidating earlier ones.
+// It succeeds silently for unknown or already verified addresses so callers cannot
+// probe which emails have accounts.
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := s.UserStore.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user by email for verification: %w", err)
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		return nil
+	}
+	return s.sendVerificationEmail(ctx, user)
+}
+
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user sqlc.User) error {
+	if err := s.EmailVerificationStore.DeleteByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete previous email verification tokens: %w", err)
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.Settings.EmailVerificationTTL)
+	if _, err := s.EmailVerificationStore.Create(ctx, user.ID, user.Email, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	verifyLink := fmt.Sprintf("%s/verify-email?token=%s", s.Settings.FrontendURL, url.QueryEscape(token))
+	body := fmt.Sprintf(`
+		<p>Hello %s,</p>
+		<p>Please confirm your email address by clicking the link below:</p>
+		<p><a href="%s">%s</a></p>
+		<p>This link will expire in %s.</p>
+		<p>If you did not create an account, please ignore this email.</p>
+	`, user.Username, verifyLink, verifyLink, s.Settings.EmailVerificationTTL)
+
+	if err := s.EmailSender.SendEmail(user.Email, "Verify your email address", body); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// tokenScope returns the scope access tokens for user are issued with.
+func (s *AuthService) tokenScope(user sqlc.User) string {
+	if s.Settings.UnverifiedLoginMode == UnverifiedLoginRestricted && !user.EmailVerifiedAt.Valid {
+		return auth.ScopeUnverified
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/store"
+)
+
+// The fakes embed the store interfaces and implement only what the tests use; calling
+// anything else panics.
+
+type fakeUserStore struct {
+	store.UserStore
+	users []sqlc.User
+}
+
+func (s *fakeUserStore) find(match func(sqlc.User) bool) (sqlc.User, error) {
+	for _, user := range s.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return sqlc.User{}, sql.ErrNoRows
+}
+
+func (s *fakeUserStore) GetUserByID(ctx context.Context, id int32) (sqlc.User, error) {
+	return s.find(func(u sqlc.User) bool { return u.ID == id })
+}
+
+func (s *fakeUserStore) GetUserByEmail(ctx context.Context, email string) (sqlc.User, error) {
+	return s.find(func(u sqlc.User) bool { return u.Email == email })
+}
+
+func (s *fakeUserStore) GetUserByUsername(ctx context.Context, username string) (sqlc.User, error) {
+	return s.find(func(u sqlc.User) bool { return u.Username == username })
+}
+
+func (s *fakeUserStore) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
+	user := sqlc.User{
+		ID:             int32(len(s.users) + 1),
+		Username:       arg.Username,
+		HashedPassword: arg.HashedPassword,
+		Email:          arg.Email,
+		RoleID:         arg.RoleID,
+	}
+	s.users = append(s.users, user)
+	return user, nil
+}
+
+func (s *fakeUserStore) VerifyUserEmail(ctx context.Context, id int32) (sqlc.User, error) {
+	for i := range s.users {
+		if s.users[i].ID == id {
+			s.users[i].EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return s.users[i], nil
+		}
+	}
+	return sqlc.User{}, sql.ErrNoRows
+}
+
+type fakeRoleStore struct {
+	store.RoleStore
+	roles []model.Role
+}
+
+func (s *fakeRoleStore) GetRoleByName(ctx context.Context, name string) (*model.Role, error) {
+	for i := range s.roles {
+		if s.roles[i].Name == name {
+			return &s.roles[i], nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *fakeRoleStore) GetByID(ctx context.Context, id int32) (*model.Role, error) {
+	for i := range s.roles {
+		if s.roles[i].ID == id {
+			return &s.roles[i], nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+type fakeUserRoleStore struct {
+	store.UserRoleStore
+}
+
+func (s *fakeUserRoleStore) ListRoleNames(ctx context.Context, userID int32) ([]string, error) {
+	return []string{"user"}, nil
+}
+
+// fakeSessionStore keeps copies of the sessions, so changes made by the caller are
+// only seen once they are written back like they would be to the database.
+type fakeSessionStore struct {
+	store.SessionStore
+	sessions map[string]model.Session
+}
+
+func (s *fakeSessionStore) CreateSession(ctx context.Context, session *model.Session) (*model.Session, error) {
+	if s.sessions == nil {
+		s.sessions = map[string]model.Session{}
+	}
+	s.sessions[session.ID] = *session
+	return session, nil
+}
+
+func (s *fakeSessionStore) GetSessionByID(ctx context.Context, id string) (*model.Session, error) {
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &session, nil
+}
+
+func (s *fakeSessionStore) RotateRefreshToken(ctx context.Context, session *model.Session, currentRefreshTokenHash string) (*model.Session, error) {
+	stored, ok := s.sessions[session.ID]
+	if !ok || stored.RefreshTokenHash.String != currentRefreshTokenHash {
+		return nil, sql.ErrNoRows
+	}
+	rotated := *session
+	rotated.PreviousRefreshTokenHash = stored.RefreshTokenHash
+	s.sessions[session.ID] = rotated
+	return &rotated, nil
+}
+
+func (s *fakeSessionStore) DeleteSession(ctx context.Context, id string) error {
+	delete(s.sessions, id)
+	return nil
+}
+
+type fakeRememberTokenStore struct {
+	store.RememberTokenStore
+}
+
+func (s *fakeRememberTokenStore) DeleteBySessionID(ctx context.Context, sessionID string) error {
+	return nil
+}
+
+// fakeLoginThrottleStore counts failures per scope and subject like the
+// login_throttles table does.
+type fakeLoginThrottleStore struct {
+	store.LoginThrottleStore
+	throttles map[string]model.LoginThrottle
+}
+
+func (s *fakeLoginThrottleStore) Get(ctx context.Context, scope, subject string) (*model.LoginThrottle, error) {
+	throttle, ok := s.throttles[scope+"/"+subject]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &throttle, nil
+}
+
+func (s *fakeLoginThrottleStore) RecordFailure(ctx context.Context, scope, subject string, windowStart time.Time) (*model.LoginThrottle, error) {
+	if s.throttles == nil {
+		s.throttles = map[string]model.LoginThrottle{}
+	}
+	key := scope + "/" + subject
+	throttle, ok := s.throttles[key]
+	if !ok || throttle.LastFailedAt.Before(windowStart) {
+		throttle = model.LoginThrottle{Scope: scope, Subject: subject}
+	}
+	throttle.FailedCount++
+	throttle.LastFailedAt = time.Now()
+	s.throttles[key] = throttle
+	return &throttle, nil
+}
+
+func (s *fakeLoginThrottleStore) Lock(ctx context.Context, scope, subject string, until time.Time) error {
+	key := scope + "/" + subject
+	throttle := s.throttles[key]
+	throttle.LockedUntil = model.NullTime{Time: until, Valid: true}
+	s.throttles[key] = throttle
+	return nil
+}
+
+func (s *fakeLoginThrottleStore) Clear(ctx context.Context, scope, subject string) error {
+	delete(s.throttles, scope+"/"+subject)
+	return nil
+}
+
+type fakeMFAStore struct {
+	store.MFAStore
+}
+
+func (s *fakeMFAStore) GetByUserID(ctx context.Context, userID int32) (*model.UserMFA, error) {
+	return nil, sql.ErrNoRows
+}
+
+// fakeEmailSender records the subjects of the emails sent, which may happen on
+// another goroutine.
+type fakeEmailSender struct {
+	mu       sync.Mutex
+	subjects []string
+}
+
+func (s *fakeEmailSender) SendEmail(to, subject, body string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subjects = append(s.subjects, subject)
+	return nil
+}
+
+func (s *fakeEmailSender) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.subjects...)
+}
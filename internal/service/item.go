@@ -35,10 +35,12 @@ func NewItemService(itemStore store.ItemStore, searchStore store.SearchStore) *I
 	}
 }
 
-func (s *ItemService) CreateItem(ctx context.Context, name, description string) (*model.Item, error) {
+// CreateItem creates an item owned by ownerID, the user who created it.
+func (s *ItemService) CreateItem(ctx context.Context, name, description string, ownerID int32) (*model.Item, error) {
 	item := &model.Item{
 		Name:        name,
 		Description: description,
+		OwnerID:     model.NullInt32{Int32: ownerID, Valid: true},
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/model"
+)
+
+var ErrLoginThrottled = errors.New("too many failed login attempts")
+
+// LoginThrottledError is returned while an account or client IP is in backoff or
+// locked out. It matches ErrLoginThrottled with errors.Is.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", ErrLoginThrottled, e.RetryAfter)
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrLoginThrottled
+}
+
+// LoginProtectionSettings controls the backoff applied to failed logins. The first
+// FreeAttempts failures are not delayed; after that each failure doubles the wait,
+// starting at BaseDelay and capped at MaxDelay, until the lockout threshold is hit and
+// logins are refused for LockoutDuration. Failures older than FailureWindow are forgotten.
+type LoginProtectionSettings struct {
+	FreeAttempts            int32
+	AccountLockoutThreshold int32
+	IPLockoutThreshold      int32
+	BaseDelay               time.Duration
+	MaxDelay                time.Duration
+	LockoutDuration         time.Duration
+	FailureWindow           time.Duration
+}
+
+// UnlockAccount clears the failed-login state of a user, lifting any backoff or lockout.
+func (s *AuthService) UnlockAccount(ctx context.Context, userID int32) error {
+	dbUser, err := s.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	if err := s.LoginThrottleStore.Clear(ctx, model.LoginThrottleScopeAccount, dbUser.Username); err != nil {
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+	return nil
+}
+
+// checkLoginAllowed returns a LoginThrottledError when either the username or the
+// client IP is currently delayed or locked. The username is checked whether or not
+// it exists, so throttling does not reveal which accounts are real. Every way of
+// logging in calls it, so a locked account stays locked whichever one is used.
+func (s *AuthService) checkLoginAllowed(ctx context.Context, username, ipAddress string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, key := range loginThrottleKeys(username, ipAddress) {
+		throttle, err := s.LoginThrottleStore.Get(ctx, key.scope, key.subject)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return fmt.Errorf("failed to check login throttle: %w", err)
+		}
+		if throttle.IsLocked(now) {
+			if wait := throttle.LockedUntil.Time.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt against the username and the client IP
+// and applies the resulting delay. dbUser is nil when the username does not exist;
+// when an existing account becomes locked its owner is notified by email.
+func (s *AuthService) recordLoginFailure(ctx context.Context, username, ipAddress string, dbUser *sqlc.User) error {
+	p := s.Settings.LoginProtection
+	windowStart := time.Now().Add(-p.FailureWindow)
+
+	for _, key := range loginThrottleKeys(username, ipAddress) {
+		throttle, err := s.LoginThrottleStore.RecordFailure(ctx, key.scope, key.subject, windowStart)
+		if err != nil {
+			return fmt.Errorf("failed to record login failure: %w", err)
+		}
+
+		threshold := p.AccountLockoutThreshold
+		if key.scope == model.LoginThrottleScopeIP {
+			threshold = p.IPLockoutThreshold
+		}
+		delay := p.delayAfter(throttle.FailedCount, threshold)
+		if delay == 0 {
+			continue
+		}
+		if err := s.LoginThrottleStore.Lock(ctx, key.scope, key.subject, throttle.LastFailedAt.Add(delay)); err != nil {
+			return fmt.Errorf("failed to apply login delay: %w", err)
+		}
+
+		if key.scope == model.LoginThrottleScopeAccount && throttle.FailedCount == threshold && dbUser != nil {
+			go s.sendLockoutEmail(dbUser.Email, dbUser.Username, p.LockoutDuration)
+		}
+	}
+	return nil
+}
+
+// clearLoginFailures resets the account counter after a successful login. The IP
+// counter is left alone so logging into one account does not reset an attack on others.
+func (s *AuthService) clearLoginFailures(ctx context.Context, username string) {
+	if err := s.LoginThrottleStore.Clear(ctx, model.LoginThrottleScopeAccount, username); err != nil {
+		logger.Error("Failed to clear login failures for %s: %v", username, err)
+	}
+}
+
+func (s *AuthService) sendLockoutEmail(to, username string, lockoutDuration time.Duration) {
+	body := fmt.Sprintf(`
+		<p>Hello %s,</p>
+		<p>Your account has been temporarily locked after too many failed login attempts.</p>
+		<p>You will be able to log in again in %s.</p>
+		<p>If these attempts were not made by you, we recommend changing your password once the lock expires.</p>
+	`, username, lockoutDuration)
+
+	if err := s.EmailSender.SendEmail(to, "Your account has been locked", body); err != nil {
+		logger.Error("Failed to send lockout email to user %s: %v", username, err)
+	}
+}
+
+func (p LoginProtectionSettings) delayAfter(failedCount, lockoutThreshold int32) time.Duration {
+	if failedCount >= lockoutThreshold {
+		return p.LockoutDuration
+	}
+	if failedCount < p.FreeAttempts {
+		return 0
+	}
+	shift := failedCount - p.FreeAttempts
+	if shift > 30 {
+		return p.MaxDelay
+	}
+	delay := p.BaseDelay << shift
+	if delay > p.MaxDelay || delay <= 0 {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+type loginThrottleKey struct {
+	scope   string
+	subject string
+}
+
+func loginThrottleKeys(username, ipAddress string) []loginThrottleKey {
+	ip, _, err := net.SplitHostPort(ipAddress)
+	if err != nil {
+		ip = ipAddress
+	}
+	return []loginThrottleKey{
+		{scope: model.LoginThrottleScopeAccount, subject: username},
+		{scope: model.LoginThrottleScopeIP, subject: ip},
+	}
+}
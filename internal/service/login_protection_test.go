@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/password"
+)
+
+var testLoginProtection = LoginProtectionSettings{
+	FreeAttempts:            3,
+	AccountLockoutThreshold: 5,
+	IPLockoutThreshold:      100,
+	BaseDelay:               time.Minute,
+	MaxDelay:                4 * time.Minute,
+	LockoutDuration:         15 * time.Minute,
+	FailureWindow:           24 * time.Hour,
+}
+
+func TestDelayAfter(t *testing.T) {
+	p := LoginProtectionSettings{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutDuration: 15 * time.Minute,
+	}
+	tests := []struct {
+		failedCount int32
+		threshold   int32
+		want        time.Duration
+	}{
+		{1, 10, 0},
+		{2, 10, 0},
+		{3, 10, time.Second},
+		{4, 10, 2 * time.Second},
+		{5, 10, 4 * time.Second},
+		{9, 10, 64 * time.Second},
+		{10, 10, 15 * time.Minute},
+		{12, 10, 15 * time.Minute},
+		{12, 100, 5 * time.Minute},
+		{40, 100, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := p.delayAfter(tt.failedCount, tt.threshold); got != tt.want {
+			t.Errorf("delayAfter(%d, %d) = %s, want %s", tt.failedCount, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+// loginProtectionTest is an AuthService with a single user, alice, whose password is
+// "correct horse".
+type loginProtectionTest struct {
+	service   *AuthService
+	throttles *fakeLoginThrottleStore
+	emails    *fakeEmailSender
+}
+
+func newLoginProtectionTest(t *testing.T) *loginProtectionTest {
+	t.Helper()
+	hasher, err := password.NewHasher("argon2id", password.Argon2id{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashedPassword, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := &loginProtectionTest{
+		throttles: &fakeLoginThrottleStore{},
+		emails:    &fakeEmailSender{},
+	}
+	test.service = &AuthService{
+		AuthStores: AuthStores{
+			UserStore: &fakeUserStore{users: []sqlc.User{
+				{ID: 1, Username: "alice", Email: "alice@example.com", HashedPassword: hashedPassword, RoleID: 2},
+			}},
+			RoleStore:          &fakeRoleStore{roles: []model.Role{{ID: 1, Name: "admin"}, {ID: 2, Name: "user"}}},
+			UserRoleStore:      &fakeUserRoleStore{},
+			SessionStore:       &fakeSessionStore{},
+			MFAStore:           &fakeMFAStore{},
+			LoginThrottleStore: test.throttles,
+		},
+		PasswordHasher: hasher,
+		EmailSender:    test.emails,
+		Tokens: &auth.TokenConfig{
+			Keys:     auth.NewHMACKeyRing("test-secret"),
+			Issuer:   "test",
+			Audience: "test",
+		},
+		Settings: AuthSettings{
+			AccessTokenTTL:  time.Minute,
+			RefreshTokenTTL: time.Hour,
+			LoginProtection: testLoginProtection,
+		},
+	}
+	return test
+}
+
+// waitOut lifts the current delays without touching the failure counts, as if the
+// client had waited for them to pass.
+func (l *loginProtectionTest) waitOut() {
+	for key, throttle := range l.throttles.throttles {
+		throttle.LockedUntil = model.NullTime{}
+		l.throttles.throttles[key] = throttle
+	}
+}
+
+// retryAfter returns how long err asks the client to wait, failing the test when err
+// is not a LoginThrottledError.
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) || !errors.Is(err, ErrLoginThrottled) {
+		t.Fatalf("error = %v, want a LoginThrottledError", err)
+	}
+	return throttled.RetryAfter
+}
+
+func TestLoginBackoffAndLockout(t *testing.T) {
+	l := newLoginProtectionTest(t)
+	ctx := context.Background()
+	const ip = "203.0.113.7:52100"
+
+	// The free attempts fail with the usual error and are not delayed.
+	for i := 0; i < 3; i++ {
+		if _, err := l.service.LoginUser(ctx, "alice", "wrong", false, ip, ""); !errors.Is(err, ErrIncorrectPassword) {
+			t.Fatalf("attempt %d: error = %v, want ErrIncorrectPassword", i+1, err)
+		}
+	}
+
+	// The third failure starts the backoff; even the right password has to wait.
+	wait := retryAfter(t, func() error {
+		_, err := l.service.LoginUser(ctx, "alice", "correct horse", false, ip, "")
+		return err
+	}())
+	if wait <= 0 || wait > time.Minute {
+		t.Errorf("retry after %s, want at most the base delay", wait)
+	}
+
+	l.waitOut()
+	if _, err := l.service.LoginUser(ctx, "alice", "wrong", false, ip, ""); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("attempt 4: error = %v, want ErrIncorrectPassword", err)
+	}
+	wait = retryAfter(t, func() error {
+		_, err := l.service.LoginUser(ctx, "alice", "wrong", false, ip, "")
+		return err
+	}())
+	if wait <= time.Minute || wait > 2*time.Minute {
+		t.Errorf("retry after %s, want the delay doubled", wait)
+	}
+
+	// The fifth failure reaches the threshold and locks the account.
+	l.waitOut()
+	if _, err := l.service.LoginUser(ctx, "alice", "wrong", false, ip, ""); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("attempt 5: error = %v, want ErrIncorrectPassword", err)
+	}
+	wait = retryAfter(t, func() error {
+		_, err := l.service.LoginUser(ctx, "alice", "correct horse", false, "198.51.100.1:40000", "")
+		return err
+	}())
+	if wait <= 14*time.Minute || wait > 15*time.Minute {
+		t.Errorf("retry after %s from another IP, want the lockout duration", wait)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !slices.Contains(l.emails.sent(), "Your account has been locked") {
+		if time.Now().After(deadline) {
+			t.Fatalf("emails sent = %v, want a lockout notification", l.emails.sent())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := l.service.UnlockAccount(ctx, 1); err != nil {
+		t.Fatalf("UnlockAccount: %v", err)
+	}
+	if _, err := l.service.LoginUser(ctx, "alice", "correct horse", false, "198.51.100.1:40000", ""); err != nil {
+		t.Fatalf("login after unlock: %v", err)
+	}
+}
+
+func TestLoginSuccessClearsAccountFailures(t *testing.T) {
+	l := newLoginProtectionTest(t)
+	ctx := context.Background()
+	const ip = "203.0.113.7:52100"
+
+	for i := 0; i < 2; i++ {
+		if _, err := l.service.LoginUser(ctx, "alice", "wrong", false, ip, ""); !errors.Is(err, ErrIncorrectPassword) {
+			t.Fatalf("attempt %d: error = %v, want ErrIncorrectPassword", i+1, err)
+		}
+	}
+	if _, err := l.service.LoginUser(ctx, "alice", "correct horse", false, ip, ""); err != nil {
+		t.Fatalf("LoginUser: %v", err)
+	}
+	if _, err := l.service.LoginThrottleStore.Get(ctx, model.LoginThrottleScopeAccount, "alice"); err == nil {
+		t.Error("account failures were kept after a successful login")
+	}
+	// The IP counter stays, so logging into one account does not reset an attack on
+	// others.
+	if throttle, err := l.service.LoginThrottleStore.Get(ctx, model.LoginThrottleScopeIP, "203.0.113.7"); err != nil || throttle.FailedCount != 2 {
+		t.Errorf("IP throttle = %+v, %v, want 2 failures", throttle, err)
+	}
+}
+
+func TestLoginThrottlesClientIPAcrossUsernames(t *testing.T) {
+	l := newLoginProtectionTest(t)
+	ctx := context.Background()
+	const ip = "203.0.113.7:52100"
+
+	// Unknown usernames count against the IP like existing ones, so guessing names does
+	// not get around the backoff.
+	for _, username := range []string{"bob", "carol", "dave"} {
+		if _, err := l.service.LoginUser(ctx, username, "wrong", false, ip, ""); !errors.Is(err, ErrUserNotFound) {
+			t.Fatalf("login as %s: error = %v, want ErrUserNotFound", username, err)
+		}
+	}
+	retryAfter(t, func() error {
+		_, err := l.service.LoginUser(ctx, "alice", "correct horse", false, "203.0.113.7:52101", "")
+		return err
+	}())
+
+	if _, err := l.service.LoginUser(ctx, "alice", "correct horse", false, "198.51.100.1:40000", ""); err != nil {
+		t.Fatalf("login from another IP: %v", err)
+	}
+}
+
+func TestCurrentPasswordFailuresAreThrottled(t *testing.T) {
+	l := newLoginProtectionTest(t)
+	ctx := context.Background()
+	const ip = "203.0.113.7:52100"
+
+	// A stolen access token must not allow unlimited guesses at the password.
+	for i := 0; i < 3; i++ {
+		if err := l.service.ChangePassword(ctx, 1, "session-1", "wrong", "new password", ip); !errors.Is(err, ErrIncorrectCurrentPassword) {
+			t.Fatalf("attempt %d: error = %v, want ErrIncorrectCurrentPassword", i+1, err)
+		}
+	}
+	retryAfter(t, l.service.RequestEmailChange(ctx, 1, "correct horse", "alice@example.org", ip))
+	retryAfter(t, func() error {
+		_, err := l.service.LoginUser(ctx, "alice", "correct horse", false, ip, "")
+		return err
+	}())
+}
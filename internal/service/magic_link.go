@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+)
+
+// RequestMagicLink emails a one-time login link to the account registered with email.
+// Unknown addresses are ignored silently so the response does not reveal which emails
+// have accounts.
+func (s *AuthService) RequestMagicLink(ctx context.Context, email string) error {
+	user, err := s.UserStore.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user by email for magic link: %w", err)
+	}
+
+	token, err := auth.GenerateMagicLinkToken(user.ID, s.Tokens, s.Settings.MagicLinkTTL)
+	if err != nil {
+		return fmt.Errorf("failed to generate magic link token: %w", err)
+	}
+
+	loginLink := fmt.Sprintf("%s/login/magic-link/verify?token=%s", s.Settings.FrontendURL, url.QueryEscape(token))
+	body := fmt.Sprintf(`
+		<p>Hello %s,</p>
+		<p>Click the link below to sign in. The link can only be used once.</p>
+		<p><a href="%s">%s</a></p>
+		<p>This link will expire in %s.</p>
+		<p>If you did not ask to sign in, please ignore this email.</p>
+	`, user.Username, loginLink, loginLink, s.Settings.MagicLinkTTL)
+
+	if err := s.EmailSender.SendEmail(user.Email, "Your sign-in link", body); err != nil {
+		return fmt.Errorf("failed to send magic link email: %w", err)
+	}
+
+	// Redemptions only need to be kept until the links they block have expired.
+	if err := s.MagicLinkStore.DeleteExpired(ctx); err != nil {
+		logger.Error("Failed to delete expired magic link redemptions: %v", err)
+	}
+	return nil
+}
+
+// LoginWithMagicLink redeems a link sent by RequestMagicLink and logs the user in the
+// same way LoginUser does after a correct password, including the second factor step.
+func (s *AuthService) LoginWithMagicLink(ctx context.Context, token, ipAddress, userAgent string) (*AuthTokens, error) {
+	claims, err := auth.ValidateMagicLinkToken(token, s.Tokens)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	dbUser, err := s.UserStore.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get user for magic link login: %w", err)
+	}
+
+	if err := s.checkLoginAllowed(ctx, dbUser.Username, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// The token is accepted for the leeway after it expires, so the redemption has to
+	// be kept that long too.
+	redeemed, err := s.MagicLinkStore.Redeem(ctx, claims.ID, dbUser.ID, claims.ExpiresAt.Add(s.Tokens.Leeway))
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem magic link: %w", err)
+	}
+	if !redeemed {
+		return nil, ErrInvalidToken
+	}
+
+	return s.completeFirstFactor(ctx, dbUser, auth.AMREmail, false, ipAddress, userAgent)
+}
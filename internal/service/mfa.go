@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+)
+
+// MFAEnrollment is returned when a user starts TOTP enrollment. The secret is shown
+// once so it can be typed in manually when the QR code cannot be scanned.
+type MFAEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// EnrollMFA generates a fresh TOTP secret for the user. The secret stays pending until
+// it is confirmed with ConfirmMFA, and re-enrolling replaces any pending secret.
+func (s *AuthService) EnrollMFA(ctx context.Context, userID int32) (*MFAEnrollment, error) {
+	enabled, err := s.isMFAEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	dbUser, err := s.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user for MFA enrollment: %w", err)
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encryptedSecret, err := s.SecretBox.Encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+	if _, err := s.MFAStore.UpsertPending(ctx, userID, encryptedSecret); err != nil {
+		return nil, fmt.Errorf("failed to store pending MFA secret: %w", err)
+	}
+
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.Settings.MFAIssuer, dbUser.Username, secret),
+	}, nil
+}
+
+// ConfirmMFA enables two-factor authentication once the user proves the authenticator
+// app was set up correctly, and returns the recovery codes in plaintext. Only their
+// hashes are stored, so this is the only time they can be shown.
+func (s *AuthService) ConfirmMFA(ctx context.Context, userID int32, code string) ([]string, error) {
+	mfa, err := s.MFAStore.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, fmt.Errorf("failed to get user MFA: %w", err)
+	}
+	if mfa.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err := s.verifyTOTP(ctx, userID, mfa.TOTPSecret, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = auth.HashRecoveryCode(c)
+	}
+	if _, err := s.MFAStore.Enable(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to enable MFA: %w", err)
+	}
+	return codes, nil
+}
+
+// DisableMFA turns two-factor authentication off. It requires a current TOTP code or
+// an unused recovery code so a stolen access token alone cannot remove the second factor.
+// Every other session of the user is revoked, and the bumped auth version makes the
+// access token of sessionID, the one making the request, refresh before it is used again.
+func (s *AuthService) DisableMFA(ctx context.Context, userID int32, sessionID, code, recoveryCode string) error {
+	if err := s.verifySecondFactor(ctx, userID, code, recoveryCode); err != nil {
+		return err
+	}
+	if err := s.MFAStore.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to disable MFA: %w", err)
+	}
+
+	if err := s.SessionStore.DeleteOtherSessions(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke other sessions after disabling MFA: %w", err)
+	}
+	if err := s.RememberTokenStore.DeleteOthers(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke other remember-me tokens after disabling MFA: %w", err)
+	}
+	if _, err := s.AuthVersionStore.BumpAuthVersion(ctx, userID); err != nil {
+		return fmt.Errorf("failed to bump auth version after disabling MFA: %w", err)
+	}
+	return nil
+}
+
+// CompleteMFALogin exchanges an mfa_pending token plus a second factor for a full
+// session, finishing a login started by LoginUser. The token is redeemed once the
+// second factor is correct, so it cannot be exchanged for another session.
+func (s *AuthService) CompleteMFALogin(ctx context.Context, mfaToken, code, recoveryCode, ipAddress, userAgent string) (*AuthTokens, error) {
+	pending, err := auth.ValidateMFAPendingToken(mfaToken, s.Tokens)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	userID := pending.UserID
+
+	dbUser, err := s.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get user for MFA login: %w", err)
+	}
+
+	if err := s.checkLoginAllowed(ctx, dbUser.Username, ipAddress); err != nil {
+		return nil, err
+	}
+	if err := s.verifySecondFactor(ctx, userID, code, recoveryCode); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if err := s.recordLoginFailure(ctx, dbUser.Username, ipAddress, &dbUser); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	s.clearLoginFailures(ctx, dbUser.Username)
+
+	// The token is accepted for the leeway after it expires, so the redemption has to
+	// be kept that long too.
+	redeemed, err := s.MFAStore.RedeemPendingToken(ctx, pending.ID, userID, pending.ExpiresAt.Add(s.Tokens.Leeway))
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem MFA pending token: %w", err)
+	}
+	if !redeemed {
+		return nil, ErrInvalidToken
+	}
+	if err := s.MFAStore.DeleteExpiredPendingRedemptions(ctx); err != nil {
+		logger.Error("Failed to delete expired MFA pending redemptions: %v", err)
+	}
+
+	amr := append(pending.AMR, auth.AMROTP)
+	return s.startSession(ctx, dbUser, amr, pending.RememberMe, ipAddress, userAgent)
+}
+
+// isMFAEnabled reports whether the user has a confirmed TOTP enrollment.
+func (s *AuthService) isMFAEnabled(ctx context.Context, userID int32) (bool, error) {
+	mfa, err := s.MFAStore.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get user MFA: %w", err)
+	}
+	return mfa.Enabled(), nil
+}
+
+// verifySecondFactor checks either a TOTP code or a recovery code against an enabled
+// enrollment. Recovery codes are consumed on use.
+func (s *AuthService) verifySecondFactor(ctx context.Context, userID int32, code, recoveryCode string) error {
+	mfa, err := s.MFAStore.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMFANotEnrolled
+		}
+		return fmt.Errorf("failed to get user MFA: %w", err)
+	}
+	if !mfa.Enabled() {
+		return ErrMFANotEnrolled
+	}
+
+	if recoveryCode != "" {
+		used, err := s.MFAStore.UseRecoveryCode(ctx, userID, auth.HashRecoveryCode(recoveryCode))
+		if err != nil {
+			return fmt.Errorf("failed to use recovery code: %w", err)
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	return s.verifyTOTP(ctx, userID, mfa.TOTPSecret, code)
+}
+
+// verifyTOTP validates code against the encrypted secret and records the accepted
+// time step, so the same code cannot be replayed within its validity window.
+func (s *AuthService) verifyTOTP(ctx context.Context, userID int32, encryptedSecret, code string) error {
+	secret, err := s.SecretBox.Decrypt(encryptedSecret)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+	step, ok := auth.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	advanced, err := s.MFAStore.AdvanceLastUsedStep(ctx, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+	if !advanced {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/oidc"
+)
+
+var (
+	ErrOIDCProviderNotFound = errors.New("unknown OIDC provider")
+	ErrOIDCLoginFailed      = errors.New("OIDC login failed")
+	// ErrOIDCNoAccount is returned for an identity that is not linked to any user when
+	// the provider does not provision accounts.
+	ErrOIDCNoAccount = errors.New("no account is linked to this identity")
+	// ErrOIDCEmailConflict is returned when a local account has the identity's email
+	// but cannot be linked to it safely.
+	ErrOIDCEmailConflict = errors.New("an account with this email already exists")
+)
+
+// OIDCProvider is an external OpenID Connect provider users can log in with.
+type OIDCProvider struct {
+	*oidc.Provider
+	// AutoProvision creates a local account on the first login of an identity that
+	// cannot be linked to an existing one.
+	AutoProvision bool
+	// DefaultRole is the role of provisioned accounts.
+	DefaultRole string
+}
+
+// oidcUsernameAttempts is how many usernames are tried for a provisioned account
+// before giving up.
+const oidcUsernameAttempts = 5
+
+// StartOIDCLogin begins a login with the named provider. It returns the provider URL
+// to send the user to and the state that comes back with the callback; the PKCE
+// verifier and nonce of the login are kept server-side under a hash of the state.
+func (s *AuthService) StartOIDCLogin(ctx context.Context, providerName string) (authURL, state string, err error) {
+	provider, ok := s.OIDCProviders[providerName]
+	if !ok {
+		return "", "", ErrOIDCProviderNotFound
+	}
+
+	state, err = oidc.GenerateNonce()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.GenerateNonce()
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err = provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(codeVerifier))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build OIDC authorization URL: %w", err)
+	}
+
+	err = s.OIDCAuthRequestStore.Create(ctx, sqlc.CreateOIDCAuthRequestParams{
+		StateHash:    auth.HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.Settings.OIDCAuthRequestTTL),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to store OIDC auth request: %w", err)
+	}
+
+	// Requests the user never came back from are only kept until they expire.
+	if err := s.OIDCAuthRequestStore.DeleteExpired(ctx); err != nil {
+		logger.Error("Failed to delete expired OIDC auth requests: %v", err)
+	}
+	return authURL, state, nil
+}
+
+// CompleteOIDCLogin finishes a login started by StartOIDCLogin with the code and
+// state from the provider callback. The identity is resolved to a local user, which is
+// created when the provider allows it, and logged in the same way LoginUser does after
+// a correct password, including the second factor step.
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, providerName, code, state, ipAddress, userAgent string) (*AuthTokens, error) {
+	provider, ok := s.OIDCProviders[providerName]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+
+	request, err := s.OIDCAuthRequestStore.Consume(ctx, auth.HashToken(state))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to consume OIDC auth request: %w", err)
+	}
+	if request.Provider != providerName {
+		return nil, ErrInvalidToken
+	}
+
+	idToken, err := provider.Exchange(ctx, code, request.CodeVerifier, request.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	dbUser, err := s.resolveOIDCUser(ctx, providerName, provider, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkLoginAllowed(ctx, dbUser.Username, ipAddress); err != nil {
+		return nil, err
+	}
+	return s.completeFirstFactor(ctx, dbUser, auth.AMRFederated, false, ipAddress, userAgent)
+}
+
+// resolveOIDCUser returns the user the identity is linked to. An unlinked identity is
+// linked to the account with the same email, or to a new account when the provider
+// provisions them.
+func (s *AuthService) resolveOIDCUser(ctx context.Context, providerName string, provider *OIDCProvider, idToken *oidc.IDToken) (sqlc.User, error) {
+	identity, err := s.UserIdentityStore.Get(ctx, providerName, idToken.Subject)
+	if err == nil {
+		dbUser, err := s.UserStore.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// The linked user was deleted.
+				return sqlc.User{}, ErrOIDCNoAccount
+			}
+			return sqlc.User{}, fmt.Errorf("failed to get user for OIDC login: %w", err)
+		}
+		if err := s.UserIdentityStore.Touch(ctx, identity.ID, idToken.Email); err != nil {
+			logger.Error("Failed to record login of identity %d: %v", identity.ID, err)
+		}
+		return dbUser, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return sqlc.User{}, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	dbUser, err := s.findOIDCUserByEmail(ctx, idToken)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	if dbUser == nil {
+		if !provider.AutoProvision {
+			return sqlc.User{}, ErrOIDCNoAccount
+		}
+		if dbUser, err = s.provisionOIDCUser(ctx, provider, idToken); err != nil {
+			return sqlc.User{}, err
+		}
+	}
+
+	if _, err := s.UserIdentityStore.Create(ctx, dbUser.ID, providerName, idToken.Subject, idToken.Email); err != nil {
+		if isUniqueViolation(err) {
+			// A concurrent login linked the identity first; the user can simply retry.
+			return sqlc.User{}, ErrInvalidToken
+		}
+		return sqlc.User{}, fmt.Errorf("failed to link user identity: %w", err)
+	}
+	logger.Info("Linked %s identity %s to user %d", providerName, idToken.Subject, dbUser.ID)
+	return *dbUser, nil
+}
+
+// findOIDCUserByEmail returns the account the identity's email belongs to, or nil
+// when there is none. Both sides have to have verified the address: linking on an
+// address the provider has not verified lets anyone claim any account, and linking to
+// an account that has not verified it lets someone who registered with another
+// person's email take over their logins.
+func (s *AuthService) findOIDCUserByEmail(ctx context.Context, idToken *oidc.IDToken) (*sqlc.User, error) {
+	if idToken.Email == "" {
+		return nil, nil
+	}
+	dbUser, err := s.UserStore.GetUserByEmail(ctx, idToken.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by email for OIDC login: %w", err)
+	}
+	if !idToken.EmailVerified || !dbUser.EmailVerifiedAt.Valid {
+		return nil, ErrOIDCEmailConflict
+	}
+	return &dbUser, nil
+}
+
+// provisionOIDCUser creates an account for the identity. The account has no password,
+// so it can only log in through the provider until the user sets one with a password
+// reset.
+func (s *AuthService) provisionOIDCUser(ctx context.Context, provider *OIDCProvider, idToken *oidc.IDToken) (*sqlc.User, error) {
+	if idToken.Email == "" {
+		return nil, fmt.Errorf("%w: the provider did not return an email address", ErrOIDCLoginFailed)
+	}
+
+	role, err := s.RoleStore.GetRoleByName(ctx, provider.DefaultRole)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidRoleName
+		}
+		return nil, fmt.Errorf("failed to get role by name: %w", err)
+	}
+
+	username, err := s.availableUsername(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+	dbUser, err := s.UserStore.CreateUser(ctx, sqlc.CreateUserParams{
+		Username:       username,
+		HashedPassword: "",
+		Email:          idToken.Email,
+		RoleID:         role.ID,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	if idToken.EmailVerified {
+		if dbUser, err = s.UserStore.VerifyUserEmail(ctx, dbUser.ID); err != nil {
+			return nil, fmt.Errorf("failed to mark email as verified: %w", err)
+		}
+	}
+
+	logger.Info("Provisioned user %d for OIDC identity %s", dbUser.ID, idToken.Subject)
+	return &dbUser, nil
+}
+
+// availableUsername derives a username for a provisioned account from the identity's
+// preferred username or email, adding a random suffix while the name is taken.
+func (s *AuthService) availableUsername(ctx context.Context, idToken *oidc.IDToken) (string, error) {
+	base := idToken.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(idToken.Email, "@")
+	}
+	runes := []rune(strings.TrimSpace(base))
+	if len(runes) > 200 {
+		runes = runes[:200]
+	}
+	base = string(runes)
+	if len(runes) < 3 {
+		base = "user"
+	}
+
+	username := base
+	for i := 0; i < oidcUsernameAttempts; i++ {
+		_, err := s.UserStore.GetUserByUsername(ctx, username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return username, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to check username: %w", err)
+		}
+		username = base + "-" + uuid.New().String()[:8]
+	}
+	return "", ErrUserAlreadyExists
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/oidc"
+	"external-backend-go/internal/oidc/oidctest"
+	"external-backend-go/internal/store"
+)
+
+// The fakes embed the store interfaces and implement only what the OIDC login uses;
+// calling anything else panics. The stores shared with other tests are in fakes_test.go.
+
+type fakeUserIdentityStore struct {
+	store.UserIdentityStore
+	identities []sqlc.UserIdentity
+}
+
+func (s *fakeUserIdentityStore) Create(ctx context.Context, userID int32, provider, subject, email string) (sqlc.UserIdentity, error) {
+	identity := sqlc.UserIdentity{
+		ID:       int32(len(s.identities) + 1),
+		UserID:   userID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    sql.NullString{String: email, Valid: email != ""},
+	}
+	s.identities = append(s.identities, identity)
+	return identity, nil
+}
+
+func (s *fakeUserIdentityStore) Get(ctx context.Context, provider, subject string) (sqlc.UserIdentity, error) {
+	for _, identity := range s.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return sqlc.UserIdentity{}, sql.ErrNoRows
+}
+
+func (s *fakeUserIdentityStore) Touch(ctx context.Context, id int32, email string) error {
+	return nil
+}
+
+type fakeOIDCAuthRequestStore struct {
+	store.OIDCAuthRequestStore
+	requests map[string]sqlc.OidcAuthRequest
+}
+
+func (s *fakeOIDCAuthRequestStore) Create(ctx context.Context, arg sqlc.CreateOIDCAuthRequestParams) error {
+	s.requests[arg.StateHash] = sqlc.OidcAuthRequest{
+		StateHash:    arg.StateHash,
+		Provider:     arg.Provider,
+		CodeVerifier: arg.CodeVerifier,
+		Nonce:        arg.Nonce,
+		ExpiresAt:    arg.ExpiresAt,
+	}
+	return nil
+}
+
+func (s *fakeOIDCAuthRequestStore) Consume(ctx context.Context, stateHash string) (sqlc.OidcAuthRequest, error) {
+	request, ok := s.requests[stateHash]
+	if !ok {
+		return sqlc.OidcAuthRequest{}, sql.ErrNoRows
+	}
+	delete(s.requests, stateHash)
+	return request, nil
+}
+
+func (s *fakeOIDCAuthRequestStore) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+// oidcTest is an AuthService logging in through an in-process identity provider
+// registered as "test", together with the stores it writes to.
+type oidcTest struct {
+	service    *AuthService
+	idp        *oidctest.Provider
+	provider   *OIDCProvider
+	users      *fakeUserStore
+	identities *fakeUserIdentityStore
+	requests   *fakeOIDCAuthRequestStore
+}
+
+func newOIDCTest(t *testing.T, users ...sqlc.User) *oidcTest {
+	t.Helper()
+	idp, err := oidctest.NewProvider("client", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(idp.Close)
+
+	provider := &OIDCProvider{
+		Provider: oidc.NewProvider(oidc.Config{
+			IssuerURL:    idp.Issuer(),
+			ClientID:     "client",
+			ClientSecret: "secret",
+			RedirectURL:  "http://localhost/auth/oidc/test/callback",
+		}, idp.Server.Client()),
+		DefaultRole: "user",
+	}
+	test := &oidcTest{
+		idp:        idp,
+		provider:   provider,
+		users:      &fakeUserStore{users: users},
+		identities: &fakeUserIdentityStore{},
+		requests:   &fakeOIDCAuthRequestStore{requests: map[string]sqlc.OidcAuthRequest{}},
+	}
+	test.service = &AuthService{
+		AuthStores: AuthStores{
+			UserStore:            test.users,
+			RoleStore:            &fakeRoleStore{roles: []model.Role{{ID: 1, Name: "admin"}, {ID: 2, Name: "user"}}},
+			UserRoleStore:        &fakeUserRoleStore{},
+			SessionStore:         &fakeSessionStore{},
+			MFAStore:             &fakeMFAStore{},
+			LoginThrottleStore:   &fakeLoginThrottleStore{},
+			UserIdentityStore:    test.identities,
+			OIDCAuthRequestStore: test.requests,
+		},
+		Tokens: &auth.TokenConfig{
+			Keys:     auth.NewHMACKeyRing("test-secret"),
+			Issuer:   "test",
+			Audience: "test",
+		},
+		OIDCProviders: map[string]*OIDCProvider{"test": provider},
+		Settings: AuthSettings{
+			AccessTokenTTL:      time.Minute,
+			RefreshTokenTTL:     time.Hour,
+			OIDCAuthRequestTTL:  time.Minute,
+			UnverifiedLoginMode: UnverifiedLoginAllow,
+		},
+	}
+	return test
+}
+
+// login runs a whole login as identity: the start, the browser's visit to the
+// provider and the callback.
+func (o *oidcTest) login(t *testing.T, identity oidctest.Identity) (*AuthTokens, error) {
+	t.Helper()
+	ctx := context.Background()
+	o.idp.SetIdentity(identity)
+
+	authURL, state, err := o.service.StartOIDCLogin(ctx, "test")
+	if err != nil {
+		t.Fatalf("StartOIDCLogin: %v", err)
+	}
+	code, returnedState, err := o.idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if returnedState != state {
+		t.Fatalf("state = %q, want %q", returnedState, state)
+	}
+	return o.service.CompleteOIDCLogin(ctx, "test", code, returnedState, "127.0.0.1", "test")
+}
+
+var verifiedAt = sql.NullTime{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+
+var alice = oidctest.Identity{
+	Subject:           "subject-1",
+	Email:             "alice@example.com",
+	EmailVerified:     true,
+	PreferredUsername: "alice",
+}
+
+func TestCompleteOIDCLoginLinksVerifiedEmail(t *testing.T) {
+	test := newOIDCTest(t, sqlc.User{ID: 1, Username: "alice", Email: "alice@example.com", EmailVerifiedAt: verifiedAt, RoleID: 2})
+
+	tokens, err := test.login(t, alice)
+	if err != nil {
+		t.Fatalf("CompleteOIDCLogin: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.MFARequired {
+		t.Errorf("tokens = %+v, want a full session", tokens)
+	}
+	claims, err := auth.ValidateToken(tokens.AccessToken, test.service.Tokens)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if claims.UserID != 1 || claims.Role != "user" {
+		t.Errorf("claims user = %d role = %q, want user 1 with role user", claims.UserID, claims.Role)
+	}
+	if len(test.identities.identities) != 1 || test.identities.identities[0].UserID != 1 {
+		t.Fatalf("identities = %+v, want subject-1 linked to user 1", test.identities.identities)
+	}
+
+	// The next login goes through the linked identity, even after the email changed.
+	changed := alice
+	changed.Email = "alice@example.org"
+	if _, err := test.login(t, changed); err != nil {
+		t.Fatalf("second CompleteOIDCLogin: %v", err)
+	}
+	if len(test.identities.identities) != 1 {
+		t.Errorf("identities = %+v, want the existing link to be reused", test.identities.identities)
+	}
+}
+
+func TestCompleteOIDCLoginRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(test *oidcTest)
+	}{
+		{
+			name: "nonce mismatch",
+			setup: func(test *oidcTest) {
+				// Consume returns a request whose nonce differs from the one the
+				// provider put into the ID token.
+				for hash, request := range test.requests.requests {
+					request.Nonce = "another-nonce"
+					test.requests.requests[hash] = request
+				}
+			},
+		},
+		{
+			name: "wrong audience",
+			setup: func(test *oidcTest) {
+				test.idp.Audience = "another-client"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newOIDCTest(t, sqlc.User{ID: 1, Username: "alice", Email: "alice@example.com", EmailVerifiedAt: verifiedAt, RoleID: 2})
+			ctx := context.Background()
+			test.idp.SetIdentity(alice)
+
+			authURL, state, err := test.service.StartOIDCLogin(ctx, "test")
+			if err != nil {
+				t.Fatalf("StartOIDCLogin: %v", err)
+			}
+			tt.setup(test)
+			code, _, err := test.idp.Authorize(authURL)
+			if err != nil {
+				t.Fatalf("Authorize: %v", err)
+			}
+
+			_, err = test.service.CompleteOIDCLogin(ctx, "test", code, state, "127.0.0.1", "test")
+			if !errors.Is(err, ErrOIDCLoginFailed) {
+				t.Errorf("CompleteOIDCLogin error = %v, want ErrOIDCLoginFailed", err)
+			}
+			if len(test.identities.identities) != 0 {
+				t.Errorf("identities = %+v, want none", test.identities.identities)
+			}
+		})
+	}
+}
+
+func TestCompleteOIDCLoginRejectsUnknownState(t *testing.T) {
+	test := newOIDCTest(t)
+	test.idp.SetIdentity(alice)
+	authURL, _, err := test.service.StartOIDCLogin(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("StartOIDCLogin: %v", err)
+	}
+	code, _, err := test.idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	_, err = test.service.CompleteOIDCLogin(context.Background(), "test", code, "forged-state", "127.0.0.1", "test")
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("CompleteOIDCLogin error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestCompleteOIDCLoginRefusesUnverifiedEmailLink(t *testing.T) {
+	tests := []struct {
+		name          string
+		localVerified sql.NullTime
+		idpVerified   bool
+	}{
+		{name: "provider has not verified the email", localVerified: verifiedAt, idpVerified: false},
+		{name: "local account has not verified the email", idpVerified: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newOIDCTest(t, sqlc.User{ID: 1, Username: "alice", Email: "alice@example.com", EmailVerifiedAt: tt.localVerified, RoleID: 2})
+			// Provisioning does not get around the conflict either.
+			test.provider.AutoProvision = true
+			identity := alice
+			identity.EmailVerified = tt.idpVerified
+
+			if _, err := test.login(t, identity); !errors.Is(err, ErrOIDCEmailConflict) {
+				t.Errorf("CompleteOIDCLogin error = %v, want ErrOIDCEmailConflict", err)
+			}
+			if len(test.identities.identities) != 0 || len(test.users.users) != 1 {
+				t.Errorf("identities = %+v, users = %d, want nothing created", test.identities.identities, len(test.users.users))
+			}
+		})
+	}
+}
+
+func TestCompleteOIDCLoginProvisionsAccount(t *testing.T) {
+	// "alice" is taken by someone with another email, so the new account gets a
+	// suffixed username.
+	test := newOIDCTest(t, sqlc.User{ID: 1, Username: "alice", Email: "other@example.com", EmailVerifiedAt: verifiedAt, RoleID: 2})
+
+	if _, err := test.login(t, alice); !errors.Is(err, ErrOIDCNoAccount) {
+		t.Fatalf("CompleteOIDCLogin without provisioning error = %v, want ErrOIDCNoAccount", err)
+	}
+
+	test.provider.AutoProvision = true
+	tokens, err := test.login(t, alice)
+	if err != nil {
+		t.Fatalf("CompleteOIDCLogin: %v", err)
+	}
+	if tokens.AccessToken == "" {
+		t.Errorf("tokens = %+v, want a full session", tokens)
+	}
+
+	if len(test.users.users) != 2 {
+		t.Fatalf("users = %+v, want one provisioned user", test.users.users)
+	}
+	user := test.users.users[1]
+	if !strings.HasPrefix(user.Username, "alice-") {
+		t.Errorf("username = %q, want alice with a suffix", user.Username)
+	}
+	if user.Email != "alice@example.com" || user.RoleID != 2 || user.HashedPassword != "" {
+		t.Errorf("user = %+v, want alice@example.com with role user and no password", user)
+	}
+	if !user.EmailVerifiedAt.Valid {
+		t.Error("email of provisioned user is not verified although the provider verified it")
+	}
+	if len(test.identities.identities) != 1 || test.identities.identities[0].UserID != user.ID {
+		t.Errorf("identities = %+v, want subject-1 linked to user %d", test.identities.identities, user.ID)
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"external-backend-go/internal/email"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/store"
+)
+
+var ErrErasureNotScheduled = errors.New("no erasure is scheduled for this user")
+
+// erasureBatchSize caps how many users a single run of EraseDueUsers handles.
+const erasureBatchSize = 100
+
+// UserDataExport is everything stored about a user, as handed out for data export
+// requests.
+type UserDataExport struct {
+	GeneratedAt time.Time
+	Profile     *model.User
+	Roles       []string
+	MFA         *model.UserMFA
+	Sessions    []*model.Session
+	Items       []*model.Item
+}
+
+// PrivacyService handles data subject requests: exporting a user's data and erasing
+// it. Erasure is scheduled ErasureGracePeriod ahead so it can still be cancelled, and
+// is carried out by EraseDueUsers.
+type PrivacyService struct {
+	PrivacyStores
+	EmailSender email.EmailSender
+	// AuthService checks the current password of RequestErasure, so that failures count
+	// towards the same backoff and lockout as failed logins.
+	AuthService        *AuthService
+	ItemIndexName      string
+	ErasureGracePeriod time.Duration
+}
+
+// PrivacyStores are the stores PrivacyService exports from and erases.
+type PrivacyStores struct {
+	UserStore               store.UserStore
+	UserRoleStore           store.UserRoleStore
+	SessionStore            store.SessionStore
+	RememberTokenStore      store.RememberTokenStore
+	ItemStore               store.ItemStore
+	SearchStore             store.SearchStore
+	MFAStore                store.MFAStore
+	PasswordResetTokenStore store.PasswordResetTokenStore
+	EmailVerificationStore  store.EmailVerificationTokenStore
+	EmailChangeStore        store.EmailChangeTokenStore
+	LoginThrottleStore      store.LoginThrottleStore
+	UserIdentityStore       store.UserIdentityStore
+}
+
+func NewPrivacyService(stores PrivacyStores, emailSender email.EmailSender, authService *AuthService, itemIndexName string, erasureGracePeriod time.Duration) *PrivacyService {
+	return &PrivacyService{
+		PrivacyStores:      stores,
+		EmailSender:        emailSender,
+		AuthService:        authService,
+		ItemIndexName:      itemIndexName,
+		ErasureGracePeriod: erasureGracePeriod,
+	}
+}
+
+// ExportUserData collects the profile, roles, two-factor settings, sessions and
+// owned items of the user. Soft-deleted users can be exported as well.
+func (s *PrivacyService) ExportUserData(ctx context.Context, userID int32) (*UserDataExport, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.UserRoleStore.ListRoleNames(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	mfa, err := s.MFAStore.GetByUserID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get two-factor settings: %w", err)
+		}
+		mfa = nil
+	}
+	sessions, err := s.SessionStore.ListSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	items, err := s.ItemStore.ListByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list owned items: %w", err)
+	}
+
+	return &UserDataExport{
+		GeneratedAt: time.Now().UTC(),
+		Profile:     user,
+		Roles:       roles,
+		MFA:         mfa,
+		Sessions:    sessions,
+		Items:       items,
+	}, nil
+}
+
+// RequestErasure schedules erasure of the user's own account after checking their
+// password, which is throttled like a login. See scheduleErasure.
+func (s *PrivacyService) RequestErasure(ctx context.Context, userID int32, currentPassword, ipAddress string) (*model.User, error) {
+	dbUser, err := s.AuthService.currentUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AuthService.verifyCurrentPassword(ctx, dbUser, currentPassword, ipAddress); err != nil {
+		return nil, err
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.scheduleErasure(ctx, user)
+}
+
+// RequestUserErasure schedules erasure of a user on an admin's behalf. Admins cannot
+// erase their own account this way. See scheduleErasure.
+func (s *PrivacyService) RequestUserErasure(ctx context.Context, actorID, userID int32) (*model.User, error) {
+	if actorID == userID {
+		return nil, ErrCannotManageSelf
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.scheduleErasure(ctx, user)
+}
+
+// scheduleErasure schedules erasure of the user ErasureGracePeriod from now and tells
+// them by email. Asking again while an erasure is pending keeps the original date.
+func (s *PrivacyService) scheduleErasure(ctx context.Context, user *model.User) (*model.User, error) {
+	userID := user.ID
+	if user.ErasedAt.Valid {
+		return nil, ErrUserErased
+	}
+	if user.ErasureScheduledAt.Valid {
+		return user, nil
+	}
+
+	erasureAt := time.Now().Add(s.ErasureGracePeriod)
+	if _, err := s.UserStore.ScheduleErasure(ctx, userID, erasureAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserErased
+		}
+		return nil, fmt.Errorf("failed to schedule user erasure: %w", err)
+	}
+
+	if !user.DeletedAt.Valid {
+		body := fmt.Sprintf(`
+			<p>Hello %s,</p>
+			<p>Your account and the personal data we hold about you will be erased on %s.</p>
+			<p>If you change your mind, sign in and cancel the erasure before then.</p>
+		`, user.Username, erasureAt.UTC().Format(time.RFC1123))
+		if err := s.EmailSender.SendEmail(user.Email, "Your account is scheduled for erasure", body); err != nil {
+			logger.Error("Failed to send erasure notice to user %d: %v", userID, err)
+		}
+	}
+	return s.getUser(ctx, userID)
+}
+
+// CancelErasure withdraws a pending erasure of the user.
+func (s *PrivacyService) CancelErasure(ctx context.Context, userID int32) (*model.User, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.UserStore.CancelErasure(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrErasureNotScheduled
+		}
+		return nil, fmt.Errorf("failed to cancel user erasure: %w", err)
+	}
+	return s.getUser(ctx, userID)
+}
+
+// EraseUser erases the user right away. Their items are deleted together with their
+// search documents, sessions, pending tokens, two-factor settings and linked
+// identities are removed, and the account is anonymized and soft-deleted. Every step
+// can be repeated, so a failed erasure is simply retried on the next run.
+func (s *PrivacyService) EraseUser(ctx context.Context, userID int32) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.ErasedAt.Valid {
+		return nil
+	}
+
+	deletedItems, err := s.ItemStore.DeleteByOwnerID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete owned items: %w", err)
+	}
+	if _, err := s.SearchStore.DeleteDocumentsByField(ctx, s.ItemIndexName, "ownerId", userID); err != nil {
+		return fmt.Errorf("failed to delete owned items from the search index: %w", err)
+	}
+
+	if err := s.SessionStore.DeleteSessionsByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	if err := s.RememberTokenStore.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete remember-me tokens: %w", err)
+	}
+	// A soft-deleted user's email and username may belong to someone else by now; their
+	// reset token and login throttle were already dropped when they were deleted.
+	if !user.DeletedAt.Valid {
+		if err := s.PasswordResetTokenStore.DeletePasswordResetToken(ctx, user.Email); err != nil {
+			return fmt.Errorf("failed to delete password reset token: %w", err)
+		}
+		if err := s.LoginThrottleStore.Clear(ctx, model.LoginThrottleScopeAccount, user.Username); err != nil {
+			return fmt.Errorf("failed to clear login throttle: %w", err)
+		}
+	}
+	if err := s.EmailVerificationStore.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+	if err := s.EmailChangeStore.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete email change tokens: %w", err)
+	}
+	if err := s.MFAStore.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete two-factor settings: %w", err)
+	}
+	if err := s.UserIdentityStore.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete linked identities: %w", err)
+	}
+
+	if _, err := s.UserStore.AnonymizeUser(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Erased concurrently.
+			return nil
+		}
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	logger.Info("Erased user %d, deleting %d owned items", userID, len(deletedItems))
+	return nil
+}
+
+// EraseDueUsers erases users whose grace period is over and returns how many were
+// erased. A user that fails is logged and retried on the next run.
+func (s *PrivacyService) EraseDueUsers(ctx context.Context) (int, error) {
+	ids, err := s.UserStore.ListDueForErasure(ctx, erasureBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list users due for erasure: %w", err)
+	}
+
+	erased := 0
+	for _, id := range ids {
+		if err := s.EraseUser(ctx, id); err != nil {
+			logger.Error("Failed to erase user %d: %v", id, err)
+			continue
+		}
+		erased++
+	}
+	return erased, nil
+}
+
+// ScheduleErasure runs EraseDueUsers every interval in the background.
+func (s *PrivacyService) ScheduleErasure(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			if _, err := s.EraseDueUsers(context.Background()); err != nil {
+				logger.Error("Failed to erase users: %v", err)
+			}
+		}
+	}()
+}
+
+func (s *PrivacyService) getUser(ctx context.Context, userID int32) (*model.User, error) {
+	user, err := s.UserStore.GetByIDIncludingDeleted(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/model"
+)
+
+var (
+	ErrIncorrectCurrentPassword = errors.New("current password is incorrect")
+	ErrEmailUnchanged           = errors.New("new email is the same as the current one")
+)
+
+// ProfileUpdate lists the profile fields to change. Nil fields are left alone; an
+// empty DisplayName or AvatarURL clears it.
+type ProfileUpdate struct {
+	Username    *string
+	DisplayName *string
+	AvatarURL   *string
+}
+
+// GetProfile returns the user's own account.
+func (s *AuthService) GetProfile(ctx context.Context, userID int32) (*model.User, error) {
+	user, err := s.UserStore.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	return user, nil
+}
+
+// UpdateProfile changes the username and display fields of the user. A new username
+// bumps the auth version, so access tokens carrying the old one have to be refreshed.
+func (s *AuthService) UpdateProfile(ctx context.Context, userID int32, update ProfileUpdate) (*model.User, error) {
+	dbUser, err := s.currentUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := sqlc.UpdateUserProfileParams{
+		ID:          dbUser.ID,
+		Username:    dbUser.Username,
+		DisplayName: dbUser.DisplayName,
+		AvatarUrl:   dbUser.AvatarUrl,
+	}
+	if update.Username != nil {
+		arg.Username = *update.Username
+	}
+	if update.DisplayName != nil {
+		arg.DisplayName = sql.NullString{String: *update.DisplayName, Valid: *update.DisplayName != ""}
+	}
+	if update.AvatarURL != nil {
+		arg.AvatarUrl = sql.NullString{String: *update.AvatarURL, Valid: *update.AvatarURL != ""}
+	}
+
+	if _, err := s.UserStore.UpdateUserProfile(ctx, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to update user profile: %w", err)
+	}
+
+	if arg.Username != dbUser.Username {
+		if _, err := s.AuthVersionStore.BumpAuthVersion(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to bump auth version after username change: %w", err)
+		}
+	}
+	return s.GetProfile(ctx, userID)
+}
+
+// ChangePassword replaces the user's password after checking the current one, see
+// verifyCurrentPassword. Every other session of the user is revoked, as is their
+// remember-me token; sessionID, the one making the request, stays signed in. A new
+// password that breaks the password policy is rejected with a *password.PolicyError.
+func (s *AuthService) ChangePassword(ctx context.Context, userID int32, sessionID, currentPassword, newPassword, ipAddress string) error {
+	dbUser, err := s.currentUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCurrentPassword(ctx, dbUser, currentPassword, ipAddress); err != nil {
+		return err
+	}
+	if err := s.PasswordPolicy.Check(newPassword, dbUser.Username, dbUser.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.PasswordHasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash new password: %w", err)
+	}
+	if _, err := s.UserStore.UpdateUserPassword(ctx, userID, hashedPassword); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to update user password: %w", err)
+	}
+
+	if err := s.SessionStore.DeleteOtherSessions(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke other sessions after password change: %w", err)
+	}
+	if err := s.RememberTokenStore.DeleteOthers(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke other remember-me tokens after password change: %w", err)
+	}
+	if err := s.PasswordResetTokenStore.DeletePasswordResetToken(ctx, dbUser.Email); err != nil {
+		return fmt.Errorf("failed to delete password reset token: %w", err)
+	}
+
+	body := fmt.Sprintf(`
+		<p>Hello %s,</p>
+		<p>Your password has just been changed and you have been signed out on your other devices.</p>
+		<p>If you did not make this change, please reset your password immediately and contact support.</p>
+	`, dbUser.Username)
+	if err := s.EmailSender.SendEmail(dbUser.Email, "Your password has been changed", body); err != nil {
+		logger.Error("Failed to send password change confirmation to user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// RequestEmailChange emails a confirmation link to newEmail after checking the
+// current password, see verifyCurrentPassword. The address on the account only changes
+// once the link is followed, see ConfirmEmailChange; requesting again invalidates
+// earlier links.
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID int32, currentPassword, newEmail, ipAddress string) error {
+	dbUser, err := s.currentUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCurrentPassword(ctx, dbUser, currentPassword, ipAddress); err != nil {
+		return err
+	}
+	if strings.EqualFold(dbUser.Email, newEmail) {
+		return ErrEmailUnchanged
+	}
+
+	if _, err := s.UserStore.GetUserByEmail(ctx, newEmail); err == nil {
+		return ErrUserAlreadyExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check whether the new email is taken: %w", err)
+	}
+
+	if err := s.EmailChangeStore.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete previous email change tokens: %w", err)
+	}
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.Settings.EmailVerificationTTL)
+	if _, err := s.EmailChangeStore.Create(ctx, userID, newEmail, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to create email change token: %w", err)
+	}
+
+	confirmLink := fmt.Sprintf("%s/confirm-email-change?token=%s", s.Settings.FrontendURL, url.QueryEscape(token))
+	body := fmt.Sprintf(`
+		<p>Hello %s,</p>
+		<p>Please confirm that you want to use this address for your account by clicking the link below:</p>
+		<p><a href="%s">%s</a></p>
+		<p>This link will expire in %s.</p>
+		<p>If you did not request this change, please ignore this email.</p>
+	`, dbUser.Username, confirmLink, confirmLink, s.Settings.EmailVerificationTTL)
+	if err := s.EmailSender.SendEmail(newEmail, "Confirm your new email address", body); err != nil {
+		return fmt.Errorf("failed to send email change confirmation: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange redeems a token from RequestEmailChange and moves the account to
+// the new address, which counts as verified. The previous address is notified.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, token string) error {
+	change, err := s.EmailChangeStore.Consume(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to consume email change token: %w", err)
+	}
+
+	dbUser, err := s.UserStore.GetUserByID(ctx, change.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get user for email change: %w", err)
+	}
+
+	if _, err := s.UserStore.UpdateUserEmail(ctx, dbUser.ID, change.NewEmail); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		if isUniqueViolation(err) {
+			return ErrUserAlreadyExists
+		}
+		return fmt.Errorf("failed to update user email: %w", err)
+	}
+
+	// Links sent to the old address must not work any more.
+	if err := s.EmailChangeStore.DeleteByUserID(ctx, dbUser.ID); err != nil {
+		return fmt.Errorf("failed to delete email change tokens: %w", err)
+	}
+	if err := s.EmailVerificationStore.DeleteByUserID(ctx, dbUser.ID); err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+	if err := s.PasswordResetTokenStore.DeletePasswordResetToken(ctx, dbUser.Email); err != nil {
+		return fmt.Errorf("failed to delete password reset token: %w", err)
+	}
+
+	body := fmt.Sprintf(`
+		<p>Hello %s,</p>
+		<p>The email address of your account has been changed to %s.</p>
+		<p>If you did not make this change, please contact support immediately.</p>
+	`, dbUser.Username, change.NewEmail)
+	if err := s.EmailSender.SendEmail(dbUser.Email, "Your email address has been changed", body); err != nil {
+		logger.Error("Failed to notify user %d of their email change: %v", dbUser.ID, err)
+	}
+	return nil
+}
+
+// verifyCurrentPassword checks the password a signed-in user entered to confirm a
+// sensitive change. It is throttled like a login: wrong passwords count towards the
+// backoff and lockout of the account and the client IP, so a stolen access token
+// cannot be used to guess the password.
+func (s *AuthService) verifyCurrentPassword(ctx context.Context, dbUser sqlc.User, currentPassword, ipAddress string) error {
+	if err := s.checkLoginAllowed(ctx, dbUser.Username, ipAddress); err != nil {
+		return err
+	}
+	if _, err := s.PasswordHasher.Verify(dbUser.HashedPassword, currentPassword); err != nil {
+		if err := s.recordLoginFailure(ctx, dbUser.Username, ipAddress, &dbUser); err != nil {
+			return err
+		}
+		return ErrIncorrectCurrentPassword
+	}
+	s.clearLoginFailures(ctx, dbUser.Username)
+	return nil
+}
+
+// currentUser loads the account of an authenticated user, reporting ErrUserNotFound
+// when it no longer exists.
+func (s *AuthService) currentUser(ctx context.Context, userID int32) (sqlc.User, error) {
+	dbUser, err := s.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sqlc.User{}, ErrUserNotFound
+		}
+		return sqlc.User{}, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	return dbUser, nil
+}
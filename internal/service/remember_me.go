@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/auth"
+	"external-backend-go/internal/logger"
+)
+
+// A remember-me token is "<selector>:<validator>". The selector looks the token up in
+// remember_tokens; only a hash of the validator is stored, so a leaked database does
+// not yield usable tokens. Each remembered device has its own token, tied to the
+// session it last started, so logging out of that session forgets only that device.
+
+// newRememberToken returns a token with a fresh selector and validator, together with
+// the selector and the validator hash to store.
+func newRememberToken() (token, selector, validatorHash string, err error) {
+	validator, validatorHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	selector = uuid.New().String()
+	return selector + ":" + validator, selector, validatorHash, nil
+}
+
+// rememberUser issues a remember-me token for the device of the session in tokens and
+// adds it to tokens.
+func (s *AuthService) rememberUser(ctx context.Context, userID int32, tokens *AuthTokens) error {
+	token, selector, validatorHash, err := newRememberToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate remember-me token: %w", err)
+	}
+	expiresAt := time.Now().Add(s.Settings.RememberMeTTL)
+	if err := s.RememberTokenStore.Create(ctx, selector, userID, validatorHash, tokens.SessionID, expiresAt); err != nil {
+		return fmt.Errorf("failed to store remember-me token: %w", err)
+	}
+	tokens.RememberToken = token
+	tokens.RememberExpiresAt = expiresAt
+
+	// Tokens only need to be kept until they expire.
+	if err := s.RememberTokenStore.DeleteExpired(ctx); err != nil {
+		logger.Error("Failed to delete expired remember-me tokens: %v", err)
+	}
+	return nil
+}
+
+// startSession creates a session like createSession and, when rememberMe is set, also
+// issues a remember-me token.
+func (s *AuthService) startSession(ctx context.Context, dbUser sqlc.User, amr []string, rememberMe bool, ipAddress, userAgent string) (*AuthTokens, error) {
+	tokens, err := s.createSession(ctx, dbUser, amr, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	if rememberMe {
+		if err := s.rememberUser(ctx, dbUser.ID, tokens); err != nil {
+			return nil, err
+		}
+	}
+	return tokens, nil
+}
+
+// LoginWithRememberToken starts a new session with a remember-me token instead of a
+// password. The token is rotated: the returned tokens carry its replacement, tied to
+// the new session, and the presented one stops working. A token whose selector exists
+// but whose validator does not match has been tampered with or stolen, so that
+// device's token is revoked.
+func (s *AuthService) LoginWithRememberToken(ctx context.Context, rememberToken, ipAddress, userAgent string) (*AuthTokens, error) {
+	selector, validator, ok := strings.Cut(rememberToken, ":")
+	if !ok || selector == "" || validator == "" {
+		return nil, ErrInvalidToken
+	}
+
+	stored, err := s.RememberTokenStore.GetBySelector(ctx, selector)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get remember-me token: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(stored.ValidatorHash), []byte(auth.HashToken(validator))) != 1 {
+		logger.Warn("Remember-me token of user %d presented with a wrong validator, revoking it", stored.UserID)
+		s.forgetDevice(ctx, selector)
+		return nil, ErrInvalidToken
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	dbUser, err := s.UserStore.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get user by remember-me token: %w", err)
+	}
+	if err := s.checkLoginAllowed(ctx, dbUser.Username, ipAddress); err != nil {
+		return nil, err
+	}
+	if s.Settings.UnverifiedLoginMode == UnverifiedLoginDeny && !dbUser.EmailVerifiedAt.Valid {
+		return nil, ErrEmailNotVerified
+	}
+
+	tokens, err := s.createSession(ctx, dbUser, []string{auth.AMRRememberMe}, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	newToken, newSelector, newValidatorHash, err := newRememberToken()
+	if err != nil {
+		s.revokeSession(ctx, tokens.SessionID)
+		return nil, fmt.Errorf("failed to generate remember-me token: %w", err)
+	}
+	expiresAt := time.Now().Add(s.Settings.RememberMeTTL)
+	rotated, err := s.RememberTokenStore.Rotate(ctx, selector, newSelector, newValidatorHash, tokens.SessionID, expiresAt)
+	if err != nil || !rotated {
+		// Without the rotation the new session must not outlive this request.
+		s.revokeSession(ctx, tokens.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rotate remember-me token: %w", err)
+		}
+		// Another request redeemed this token between our read and write.
+		return nil, ErrInvalidToken
+	}
+
+	tokens.RememberToken = newToken
+	tokens.RememberExpiresAt = expiresAt
+	return tokens, nil
+}
+
+// forgetDevice revokes a remember-me token on a best-effort basis; it is used where
+// the request is rejected anyway, so a failure is only logged.
+func (s *AuthService) forgetDevice(ctx context.Context, selector string) {
+	if err := s.RememberTokenStore.Delete(ctx, selector); err != nil {
+		logger.Error("Failed to revoke remember-me token: %v", err)
+	}
+}
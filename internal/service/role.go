@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/lib/pq"
+
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/store"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleAlreadyExists  = errors.New("role with this name already exists")
+	ErrRoleInUse          = errors.New("role is still assigned to users")
+	ErrParentRoleNotFound = errors.New("parent role not found")
+	ErrRoleCycle          = errors.New("role cannot inherit from itself or one of its descendants")
+	ErrBuiltInRole        = errors.New("built-in roles cannot be renamed or deleted")
+)
+
+// isBuiltInRole reports whether the application refers to the role by name, so
+// renaming or deleting it would break admin checks, registration or the item
+// ownership policy.
+func isBuiltInRole(name string) bool {
+	switch name {
+	case model.RoleAdmin, model.RoleUser, model.RoleAuthor:
+		return true
+	}
+	return false
+}
+
+type PaginatedRoles struct {
+	Roles      []model.Role `json:"roles"`
+	TotalCount int          `json:"totalCount"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"pageSize"`
+	TotalPages int          `json:"totalPages"`
+}
+
+type RoleService struct {
+	RoleStore          store.RoleStore
+	UserStore          store.UserStore
+	UserRoleStore      store.UserRoleStore
+	RoleHierarchyStore store.RoleHierarchyStore
+	AuthVersionStore   store.AuthVersionStore
+}
+
+func NewRoleService(roleStore store.RoleStore, userStore store.UserStore, userRoleStore store.UserRoleStore, roleHierarchyStore store.RoleHierarchyStore, authVersionStore store.AuthVersionStore) *RoleService {
+	return &RoleService{
+		RoleStore:          roleStore,
+		UserStore:          userStore,
+		UserRoleStore:      userRoleStore,
+		RoleHierarchyStore: roleHierarchyStore,
+		AuthVersionStore:   authVersionStore,
+	}
+}
+
+// CreateRole creates a role. When parentName is set the new role inherits everything
+// granted to that role.
+func (s *RoleService) CreateRole(ctx context.Context, name, description, parentName string) (*model.Role, error) {
+	if err := s.ensureNameAvailable(ctx, name, 0); err != nil {
+		return nil, err
+	}
+	parentID, err := s.resolveParent(ctx, 0, parentName)
+	if err != nil {
+		return nil, err
+	}
+
+	createdRole, err := s.RoleStore.Create(ctx, &model.Role{
+		Name:        name,
+		Description: model.NullString{String: description, Valid: description != ""},
+		ParentID:    parentID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+	return createdRole, nil
+}
+
+func (s *RoleService) GetRoleByID(ctx context.Context, id int32) (*model.Role, error) {
+	role, err := s.RoleStore.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role by ID: %w", err)
+	}
+	return role, nil
+}
+
+// UpdateRole renames a role, changes its description and sets the role it inherits
+// from. An empty parentName removes the parent. Built-in roles keep their name.
+func (s *RoleService) UpdateRole(ctx context.Context, id int32, name, description, parentName string) (*model.Role, error) {
+	existingRole, err := s.GetRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if name != existingRole.Name && isBuiltInRole(existingRole.Name) {
+		return nil, ErrBuiltInRole
+	}
+	if err := s.ensureNameAvailable(ctx, name, id); err != nil {
+		return nil, err
+	}
+	parentID, err := s.resolveParent(ctx, id, parentName)
+	if err != nil {
+		return nil, err
+	}
+
+	existingRole.Name = name
+	existingRole.Description = model.NullString{String: description, Valid: description != ""}
+	existingRole.ParentID = parentID
+
+	updatedRole, err := s.RoleStore.Update(ctx, existingRole)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	return updatedRole, nil
+}
+
+// DeleteRole removes a role and its permission grants. Built-in roles cannot be
+// deleted, and roles that are still assigned to users are refused with ErrRoleInUse;
+// reassign those users first.
+func (s *RoleService) DeleteRole(ctx context.Context, id int32) error {
+	role, err := s.GetRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if isBuiltInRole(role.Name) {
+		return ErrBuiltInRole
+	}
+
+	// The foreign keys from users and user_roles refuse the delete while the role is
+	// assigned, so there is no window between a check and the delete.
+	if err := s.RoleStore.Delete(ctx, id); err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrRoleInUse
+		}
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	return nil
+}
+
+func (s *RoleService) GetRoles(ctx context.Context, page, pageSize int) (*PaginatedRoles, error) {
+	offset := (page - 1) * pageSize
+	ptrRoles, err := s.RoleStore.List(ctx, int32(offset), int32(pageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	roles := []model.Role{}
+	for _, rolePtr := range ptrRoles {
+		roles = append(roles, *rolePtr)
+	}
+
+	totalCount64, err := s.RoleStore.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count roles: %w", err)
+	}
+	totalCount := int(totalCount64)
+
+	totalPages := int(math.Ceil(float64(totalCount) / float64(pageSize)))
+	if totalPages == 0 && totalCount > 0 {
+		totalPages = 1
+	}
+
+	return &PaginatedRoles{
+		Roles:      roles,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// resolveParent looks up the role named parentName and makes sure that making it the
+// parent of roleID does not create a cycle. roleID is 0 for a role that does not exist yet.
+func (s *RoleService) resolveParent(ctx context.Context, roleID int32, parentName string) (model.NullInt32, error) {
+	if parentName == "" {
+		return model.NullInt32{}, nil
+	}
+
+	parent, err := s.RoleStore.GetRoleByName(ctx, parentName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.NullInt32{}, ErrParentRoleNotFound
+		}
+		return model.NullInt32{}, fmt.Errorf("failed to get parent role: %w", err)
+	}
+
+	if roleID != 0 {
+		seen := make(map[int32]bool)
+		for ancestor := parent; ; {
+			if ancestor.ID == roleID {
+				return model.NullInt32{}, ErrRoleCycle
+			}
+			if !ancestor.ParentID.Valid || seen[ancestor.ID] {
+				break
+			}
+			seen[ancestor.ID] = true
+			ancestor, err = s.RoleStore.GetByID(ctx, ancestor.ParentID.Int32)
+			if err != nil {
+				return model.NullInt32{}, fmt.Errorf("failed to walk role hierarchy: %w", err)
+			}
+		}
+	}
+	return model.NullInt32{Int32: parent.ID, Valid: true}, nil
+}
+
+// roleByName returns ErrInvalidRoleName when no role is called name.
+func (s *RoleService) roleByName(ctx context.Context, name string) (*model.Role, error) {
+	role, err := s.RoleStore.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidRoleName
+		}
+		return nil, fmt.Errorf("failed to get role by name: %w", err)
+	}
+	return role, nil
+}
+
+// ensureNameAvailable returns ErrRoleAlreadyExists when a role other than exceptID
+// already uses name.
+func (s *RoleService) ensureNameAvailable(ctx context.Context, name string, exceptID int32) error {
+	role, err := s.RoleStore.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get role by name: %w", err)
+	}
+	if role.ID != exceptID {
+		return ErrRoleAlreadyExists
+	}
+	return nil
+}
+
+// isForeignKeyViolation reports whether err comes from a foreign key, e.g. deleting a
+// row that others still reference.
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
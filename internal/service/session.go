@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"external-backend-go/internal/model"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ListSessions returns the sessions of userID that can still be refreshed, most
+// recently active first.
+func (s *AuthService) ListSessions(ctx context.Context, userID int32) ([]*model.Session, error) {
+	if _, err := s.UserStore.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	sessions, err := s.SessionStore.ListSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession deletes one session of userID and forgets its device. A session that
+// does not exist or belongs to someone else is reported as ErrSessionNotFound.
+func (s *AuthService) RevokeSession(ctx context.Context, userID int32, sessionID string) error {
+	if err := s.SessionStore.DeleteUserSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if err := s.RememberTokenStore.DeleteBySessionID(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke remember-me token of session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/logger"
+	"external-backend-go/internal/model"
+	"external-backend-go/internal/store"
+)
+
+var (
+	ErrCannotManageSelf   = errors.New("admins cannot delete or log out their own account through user management")
+	ErrUserAlreadyDeleted = errors.New("user is already deleted")
+	ErrUserNotDeleted     = errors.New("user is not deleted")
+	ErrUserErased         = errors.New("user has been erased")
+)
+
+// Fields users can be sorted by.
+const (
+	UserSortID        = "id"
+	UserSortUsername  = "username"
+	UserSortEmail     = "email"
+	UserSortCreatedAt = "created_at"
+)
+
+// UserFilter narrows down the users listed by ListUsers. Zero values do not filter,
+// except that soft-deleted users are left out unless Deleted or IncludeDeleted is set.
+type UserFilter struct {
+	Role           string
+	Verified       *bool
+	Deleted        *bool
+	IncludeDeleted bool
+	CreatedFrom    time.Time
+	CreatedTo      time.Time
+	// Search matches part of the username or email, regardless of case.
+	Search   string
+	SortBy   string
+	SortDesc bool
+}
+
+type PaginatedUsers struct {
+	Users      []model.User `json:"users"`
+	TotalCount int          `json:"totalCount"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"pageSize"`
+	TotalPages int          `json:"totalPages"`
+}
+
+// UserService implements user management for the admin area.
+type UserService struct {
+	UserStore               store.UserStore
+	SessionStore            store.SessionStore
+	RememberTokenStore      store.RememberTokenStore
+	AuthVersionStore        store.AuthVersionStore
+	EmailVerificationStore  store.EmailVerificationTokenStore
+	PasswordResetTokenStore store.PasswordResetTokenStore
+}
+
+func NewUserService(userStore store.UserStore, sessionStore store.SessionStore, rememberTokenStore store.RememberTokenStore, authVersionStore store.AuthVersionStore, emailVerificationStore store.EmailVerificationTokenStore, passwordResetTokenStore store.PasswordResetTokenStore) *UserService {
+	return &UserService{
+		UserStore:               userStore,
+		SessionStore:            sessionStore,
+		RememberTokenStore:      rememberTokenStore,
+		AuthVersionStore:        authVersionStore,
+		EmailVerificationStore:  emailVerificationStore,
+		PasswordResetTokenStore: passwordResetTokenStore,
+	}
+}
+
+func (s *UserService) ListUsers(ctx context.Context, filter UserFilter, page, pageSize int) (*PaginatedUsers, error) {
+	if filter.SortBy == "" {
+		filter.SortBy = UserSortID
+	}
+	role := sql.NullString{String: filter.Role, Valid: filter.Role != ""}
+	verified := nullBool(filter.Verified)
+	deleted := nullBool(filter.Deleted)
+	if filter.Deleted == nil && !filter.IncludeDeleted {
+		deleted = sql.NullBool{Bool: false, Valid: true}
+	}
+	createdFrom := sql.NullTime{Time: filter.CreatedFrom, Valid: !filter.CreatedFrom.IsZero()}
+	createdTo := sql.NullTime{Time: filter.CreatedTo, Valid: !filter.CreatedTo.IsZero()}
+	search := sql.NullString{String: filter.Search, Valid: filter.Search != ""}
+
+	offset := (page - 1) * pageSize
+	ptrUsers, err := s.UserStore.SearchUsers(ctx, sqlc.SearchUsersParams{
+		Role:        role,
+		Verified:    verified,
+		Deleted:     deleted,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		Search:      search,
+		SortBy:      filter.SortBy,
+		SortDesc:    filter.SortDesc,
+		Limit:       int32(pageSize),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	users := []model.User{}
+	for _, userPtr := range ptrUsers {
+		users = append(users, *userPtr)
+	}
+
+	totalCount64, err := s.UserStore.CountSearchUsers(ctx, sqlc.CountSearchUsersParams{
+		Role:        role,
+		Verified:    verified,
+		Deleted:     deleted,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		Search:      search,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	totalCount := int(totalCount64)
+
+	totalPages := int(math.Ceil(float64(totalCount) / float64(pageSize)))
+	if totalPages == 0 && totalCount > 0 {
+		totalPages = 1
+	}
+
+	return &PaginatedUsers{
+		Users:      users,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetUser returns the user whether or not they are soft-deleted.
+func (s *UserService) GetUser(ctx context.Context, id int32) (*model.User, error) {
+	user, err := s.UserStore.GetByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	return user, nil
+}
+
+// SoftDeleteUser marks the user as deleted and revokes their sessions and pending
+// password reset. The account can be brought back with RestoreUser until it is purged.
+// actorID is the admin making the request.
+func (s *UserService) SoftDeleteUser(ctx context.Context, actorID, id int32) (*model.User, error) {
+	if actorID == id {
+		return nil, ErrCannotManageSelf
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletedAt.Valid {
+		return nil, ErrUserAlreadyDeleted
+	}
+
+	if _, err := s.UserStore.SoftDeleteUser(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserAlreadyDeleted
+		}
+		return nil, fmt.Errorf("failed to soft delete user: %w", err)
+	}
+	if err := s.PasswordResetTokenStore.DeletePasswordResetToken(ctx, user.Email); err != nil {
+		return nil, fmt.Errorf("failed to delete password reset token: %w", err)
+	}
+	if err := s.revokeAccess(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, id)
+}
+
+// RestoreUser undoes a soft delete. It fails with ErrUserAlreadyExists when the
+// username or email has been taken by another user in the meantime, and with
+// ErrUserErased for accounts whose personal data has been erased.
+func (s *UserService) RestoreUser(ctx context.Context, id int32) (*model.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.ErasedAt.Valid {
+		return nil, ErrUserErased
+	}
+	if !user.DeletedAt.Valid {
+		return nil, ErrUserNotDeleted
+	}
+
+	if _, err := s.UserStore.RestoreUser(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotDeleted
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+	return s.GetUser(ctx, id)
+}
+
+// VerifyUserEmail marks the user's email address as verified without a token, and
+// invalidates verification links that are still pending.
+func (s *UserService) VerifyUserEmail(ctx context.Context, id int32) (*model.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt.Valid {
+		return nil, ErrEmailAlreadyVerified
+	}
+
+	if _, err := s.UserStore.VerifyUserEmail(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to verify user email: %w", err)
+	}
+	if err := s.EmailVerificationStore.DeleteByUserID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+	return s.GetUser(ctx, id)
+}
+
+// LogoutUser revokes every session of the user and invalidates their access tokens.
+func (s *UserService) LogoutUser(ctx context.Context, actorID, id int32) error {
+	if actorID == id {
+		return ErrCannotManageSelf
+	}
+	if _, err := s.GetUser(ctx, id); err != nil {
+		return err
+	}
+	return s.revokeAccess(ctx, id)
+}
+
+// DeleteUser removes the user permanently. Sessions, MFA settings, role assignments
+// and pending tokens go with the account; items they own are kept without an owner.
+func (s *UserService) DeleteUser(ctx context.Context, actorID, id int32) error {
+	if actorID == id {
+		return ErrCannotManageSelf
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Reset tokens are keyed by email rather than user, so they are not cascaded. A
+	// soft-deleted user's email may belong to someone else by now, and their token was
+	// already removed when they were deleted.
+	if !user.DeletedAt.Valid {
+		if err := s.PasswordResetTokenStore.DeletePasswordResetToken(ctx, user.Email); err != nil {
+			return fmt.Errorf("failed to delete password reset token: %w", err)
+		}
+	}
+	if err := s.revokeAccess(ctx, id); err != nil {
+		return err
+	}
+	if err := s.UserStore.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeletedUsers permanently deletes users that were soft-deleted more than
+// retention ago and returns how many were removed. Users whose erasure is pending are
+// kept until PrivacyService erases them, so their items are deleted too.
+func (s *UserService) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int, error) {
+	ids, err := s.UserStore.PurgeDeletedUsers(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+	return len(ids), nil
+}
+
+// SchedulePurge runs PurgeDeletedUsers every interval in the background.
+func (s *UserService) SchedulePurge(interval, retention time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			purged, err := s.PurgeDeletedUsers(context.Background(), retention)
+			if err != nil {
+				logger.Error("Failed to purge deleted users: %v", err)
+				continue
+			}
+			if purged > 0 {
+				logger.Info("Purged %d users deleted more than %s ago", purged, retention)
+			}
+		}
+	}()
+}
+
+// revokeAccess deletes the user's sessions and remember-me tokens and bumps their auth
+// version, so neither refresh tokens nor outstanding access tokens keep working.
+func (s *UserService) revokeAccess(ctx context.Context, id int32) error {
+	if err := s.SessionStore.DeleteSessionsByUserID(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	if err := s.RememberTokenStore.DeleteByUserID(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke remember-me tokens: %w", err)
+	}
+	if _, err := s.AuthVersionStore.BumpAuthVersion(ctx, id); err != nil {
+		return fmt.Errorf("failed to bump auth version: %w", err)
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err comes from a unique index, e.g. a username
+// or email that is already taken.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func nullBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
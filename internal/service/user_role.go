@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUserRoleNotAssigned = errors.New("role is not assigned to the user")
+	ErrPrimaryRoleRemoval  = errors.New("the primary role cannot be removed")
+)
+
+// UserRoles describes the roles of a user. Roles are the roles assigned directly and
+// EffectiveRoles additionally contain every role inherited through the hierarchy.
+type UserRoles struct {
+	UserID         int32    `json:"userId"`
+	PrimaryRole    string   `json:"primaryRole"`
+	Roles          []string `json:"roles"`
+	EffectiveRoles []string `json:"effectiveRoles"`
+}
+
+func (s *RoleService) GetUserRoles(ctx context.Context, userID int32) (*UserRoles, error) {
+	dbUser, err := s.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	primaryRole, err := s.RoleStore.GetByID(ctx, dbUser.RoleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user role: %w", err)
+	}
+	roles, err := s.UserRoleStore.ListRoleNames(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	hierarchy, err := s.RoleHierarchyStore.GetHierarchy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role hierarchy: %w", err)
+	}
+
+	return &UserRoles{
+		UserID:         userID,
+		PrimaryRole:    primaryRole.Name,
+		Roles:          roles,
+		EffectiveRoles: hierarchy.Expand(roles),
+	}, nil
+}
+
+// AssignUserRole gives the user an additional role. Assigning a role the user already
+// has is not an error. The user's access tokens have to be refreshed to carry it.
+func (s *RoleService) AssignUserRole(ctx context.Context, userID int32, roleName string) (*UserRoles, error) {
+	if _, err := s.GetUserRoles(ctx, userID); err != nil {
+		return nil, err
+	}
+	role, err := s.roleByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.UserRoleStore.AddRole(ctx, userID, role.ID); err != nil {
+		return nil, fmt.Errorf("failed to assign role: %w", err)
+	}
+	s.AuthVersionStore.Forget(userID)
+	return s.GetUserRoles(ctx, userID)
+}
+
+// RemoveUserRole takes a role away from the user. The primary role can only be
+// replaced through UpdateUserRole, never removed.
+func (s *RoleService) RemoveUserRole(ctx context.Context, userID int32, roleName string) (*UserRoles, error) {
+	current, err := s.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current.PrimaryRole == roleName {
+		return nil, ErrPrimaryRoleRemoval
+	}
+	role, err := s.roleByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := s.UserRoleStore.RemoveRole(ctx, userID, role.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove role: %w", err)
+	}
+	if !removed {
+		return nil, ErrUserRoleNotAssigned
+	}
+	// Tokens that still list the removed role must not keep its permissions.
+	s.AuthVersionStore.Forget(userID)
+	return s.GetUserRoles(ctx, userID)
+}
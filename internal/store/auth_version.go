@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"external-backend-go/db/sqlc"
+)
+
+// AuthVersionStore reads and bumps users.auth_version. Access tokens carry the version
+// they were issued under and are rejected once it has been bumped.
+type AuthVersionStore interface {
+	GetAuthVersion(ctx context.Context, userID int32) (int32, error)
+	BumpAuthVersion(ctx context.Context, userID int32) (int32, error)
+	// Forget drops any cached version of the user, after it was bumped as part of a
+	// transaction in another store.
+	Forget(userID int32)
+}
+
+type authVersionStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewAuthVersionStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) AuthVersionStore {
+	return &authVersionStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *authVersionStore) GetAuthVersion(ctx context.Context, userID int32) (int32, error) {
+	version, err := s.queries.GetUserAuthVersion(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, sql.ErrNoRows
+		}
+		return 0, fmt.Errorf("failed to get user auth version from DB: %w", err)
+	}
+	return version, nil
+}
+
+func (s *authVersionStore) BumpAuthVersion(ctx context.Context, userID int32) (int32, error) {
+	version, err := s.queries.BumpUserAuthVersion(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, sql.ErrNoRows
+		}
+		return 0, fmt.Errorf("failed to bump user auth version in DB: %w", err)
+	}
+	return version, nil
+}
+
+func (s *authVersionStore) Forget(userID int32) {}
+
+type authVersionCacheEntry struct {
+	version   int32
+	expiresAt time.Time
+}
+
+// cachedAuthVersionStore keeps auth versions in memory for ttl. Bumps made through
+// this store take effect immediately; bumps made by other processes become visible
+// once the entry expires.
+type cachedAuthVersionStore struct {
+	AuthVersionStore
+	ttl     time.Duration
+	entries map[int32]authVersionCacheEntry
+	mu      sync.RWMutex
+}
+
+func NewCachedAuthVersionStore(inner AuthVersionStore, ttl time.Duration) AuthVersionStore {
+	c := &cachedAuthVersionStore{
+		AuthVersionStore: inner,
+		ttl:              ttl,
+		entries:          make(map[int32]authVersionCacheEntry),
+	}
+	if ttl > 0 {
+		go c.cleanupEntries()
+	}
+	return c
+}
+
+func (c *cachedAuthVersionStore) GetAuthVersion(ctx context.Context, userID int32) (int32, error) {
+	if c.ttl <= 0 {
+		return c.AuthVersionStore.GetAuthVersion(ctx, userID)
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[userID]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.version, nil
+	}
+
+	version, err := c.AuthVersionStore.GetAuthVersion(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.entries[userID] = authVersionCacheEntry{version: version, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return version, nil
+}
+
+func (c *cachedAuthVersionStore) BumpAuthVersion(ctx context.Context, userID int32) (int32, error) {
+	version, err := c.AuthVersionStore.BumpAuthVersion(ctx, userID)
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+	return version, err
+}
+
+func (c *cachedAuthVersionStore) Forget(userID int32) {
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+}
+
+func (c *cachedAuthVersionStore) cleanupEntries() {
+	for range time.Tick(c.ttl) {
+		now := time.Now()
+		c.mu.Lock()
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/model"
+)
+
+type EmailChangeTokenStore interface {
+	Create(ctx context.Context, userID int32, newEmail, tokenHash string, expiresAt time.Time) (*model.EmailChangeToken, error)
+	// Consume marks an unused, unexpired token as used and returns it, or returns
+	// sql.ErrNoRows when no such token exists.
+	Consume(ctx context.Context, tokenHash string) (*model.EmailChangeToken, error)
+	DeleteByUserID(ctx context.Context, userID int32) error
+}
+
+type emailChangeTokenStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewEmailChangeTokenStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) EmailChangeTokenStore {
+	return &emailChangeTokenStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *emailChangeTokenStore) Create(ctx context.Context, userID int32, newEmail, tokenHash string, expiresAt time.Time) (*model.EmailChangeToken, error) {
+	dbToken, err := s.queries.CreateEmailChangeToken(ctx, sqlc.CreateEmailChangeTokenParams{
+		UserID:    userID,
+		NewEmail:  newEmail,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email change token in DB: %w", err)
+	}
+	return &model.EmailChangeToken{
+		ID:        dbToken.ID,
+		UserID:    dbToken.UserID,
+		NewEmail:  dbToken.NewEmail,
+		TokenHash: dbToken.TokenHash,
+		ExpiresAt: dbToken.ExpiresAt,
+		UsedAt:    model.FromSQLNullTime(dbToken.UsedAt),
+		CreatedAt: dbToken.CreatedAt,
+	}, nil
+}
+
+func (s *emailChangeTokenStore) Consume(ctx context.Context, tokenHash string) (*model.EmailChangeToken, error) {
+	dbToken, err := s.queries.ConsumeEmailChangeToken(ctx, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to consume email change token in DB: %w", err)
+	}
+	return &model.EmailChangeToken{
+		ID:        dbToken.ID,
+		UserID:    dbToken.UserID,
+		NewEmail:  dbToken.NewEmail,
+		TokenHash: dbToken.TokenHash,
+		ExpiresAt: dbToken.ExpiresAt,
+		UsedAt:    model.FromSQLNullTime(dbToken.UsedAt),
+		CreatedAt: dbToken.CreatedAt,
+	}, nil
+}
+
+func (s *emailChangeTokenStore) DeleteByUserID(ctx context.Context, userID int32) error {
+	err := s.queries.DeleteEmailChangeTokensByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete email change tokens from DB: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/model"
+)
+
+type EmailVerificationTokenStore interface {
+	Create(ctx context.Context, userID int32, email, tokenHash string, expiresAt time.Time) (*model.EmailVerificationToken, error)
+	// Consume marks an unused, unexpired token as used and returns it, or returns
+	// sql.ErrNoRows when no such token exists.
+	Consume(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error)
+	DeleteByUserID(ctx context.Context, userID int32) error
+}
+
+type emailVerificationTokenStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewEmailVerificationTokenStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) EmailVerificationTokenStore {
+	return &emailVerificationTokenStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *emailVerificationTokenStore) Create(ctx context.Context, userID int32, email, tokenHash string, expiresAt time.Time) (*model.EmailVerificationToken, error) {
+	dbToken, err := s.queries.CreateEmailVerificationToken(ctx, sqlc.CreateEmailVerificationTokenParams{
+		UserID:    userID,
+		Email:     email,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email verification token in DB: %w", err)
+	}
+	return &model.EmailVerificationToken{
+		ID:        dbToken.ID,
+		UserID:    dbToken.UserID,
+		Email:     dbToken.Email,
+		TokenHash: dbToken.TokenHash,
+		ExpiresAt: dbToken.ExpiresAt,
+		UsedAt:    model.FromSQLNullTime(dbToken.UsedAt),
+		CreatedAt: dbToken.CreatedAt,
+	}, nil
+}
+
+func (s *emailVerificationTokenStore) Consume(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error) {
+	dbToken, err := s.queries.ConsumeEmailVerificationToken(ctx, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to consume email verification token in DB: %w", err)
+	}
+	return &model.EmailVerificationToken{
+		ID:        dbToken.ID,
+		UserID:    dbToken.UserID,
+		Email:     dbToken.Email,
+		TokenHash: dbToken.TokenHash,
+		ExpiresAt: dbToken.ExpiresAt,
+		UsedAt:    model.FromSQLNullTime(dbToken.UsedAt),
+		CreatedAt: dbToken.CreatedAt,
+	}, nil
+}
+
+func (s *emailVerificationTokenStore) DeleteByUserID(ctx context.Context, userID int32) error {
+	err := s.queries.DeleteEmailVerificationTokensByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete email verification tokens from DB: %w", err)
+	}
+	return nil
+}
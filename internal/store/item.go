@@ -11,6 +11,10 @@ import (
 
 type ItemStore interface {
 	RepositoryInterface[*model.Item]
+
+	ListByOwnerID(ctx context.Context, ownerID int32) ([]*model.Item, error)
+	// DeleteByOwnerID deletes every item owned by ownerID and returns their IDs.
+	DeleteByOwnerID(ctx context.Context, ownerID int32) ([]int32, error)
 }
 
 type itemStore struct {
@@ -26,6 +30,7 @@ func (s *itemStore) Create(ctx context.Context, item *model.Item) (*model.Item,
 	params := sqlc.CreateItemParams{
 		Name:        item.Name,
 		Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
+		OwnerID:     item.OwnerID.ToSQLNullInt32(),
 	}
 	createdItem, err := s.queries.CreateItem(ctx, params)
 	if err != nil {
@@ -35,6 +40,7 @@ func (s *itemStore) Create(ctx context.Context, item *model.Item) (*model.Item,
 		ID:          createdItem.ID,
 		Name:        createdItem.Name,
 		Description: createdItem.Description.String,
+		OwnerID:     model.FromSQLNullInt32(createdItem.OwnerID),
 		CreatedAt:   createdItem.CreatedAt,
 		UpdatedAt:   createdItem.UpdatedAt,
 	}, nil
@@ -52,6 +58,7 @@ func (s *itemStore) GetByID(ctx context.Context, id int32) (*model.Item, error)
 		ID:          dbItem.ID,
 		Name:        dbItem.Name,
 		Description: dbItem.Description.String,
+		OwnerID:     model.FromSQLNullInt32(dbItem.OwnerID),
 		CreatedAt:   dbItem.CreatedAt,
 		UpdatedAt:   dbItem.UpdatedAt,
 	}, nil
@@ -74,6 +81,7 @@ func (s *itemStore) Update(ctx context.Context, item *model.Item) (*model.Item,
 		ID:          updatedItem.ID,
 		Name:        updatedItem.Name,
 		Description: updatedItem.Description.String,
+		OwnerID:     model.FromSQLNullInt32(updatedItem.OwnerID),
 		CreatedAt:   updatedItem.CreatedAt,
 		UpdatedAt:   updatedItem.UpdatedAt,
 	}, nil
@@ -106,6 +114,7 @@ func (s *itemStore) List(ctx context.Context, offset, limit int32) ([]*model.Ite
 			ID:          dbItem.ID,
 			Name:        dbItem.Name,
 			Description: dbItem.Description.String,
+			OwnerID:     model.FromSQLNullInt32(dbItem.OwnerID),
 			CreatedAt:   dbItem.CreatedAt,
 			UpdatedAt:   dbItem.UpdatedAt,
 		})
@@ -120,3 +129,31 @@ func (s *itemStore) Count(ctx context.Context) (int64, error) {
 	}
 	return count, nil
 }
+
+func (s *itemStore) ListByOwnerID(ctx context.Context, ownerID int32) ([]*model.Item, error) {
+	dbItems, err := s.queries.ListItemsByOwnerID(ctx, sql.NullInt32{Int32: ownerID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items of owner %d from DB: %w", ownerID, err)
+	}
+
+	items := make([]*model.Item, 0, len(dbItems))
+	for _, dbItem := range dbItems {
+		items = append(items, &model.Item{
+			ID:          dbItem.ID,
+			Name:        dbItem.Name,
+			Description: dbItem.Description.String,
+			OwnerID:     model.FromSQLNullInt32(dbItem.OwnerID),
+			CreatedAt:   dbItem.CreatedAt,
+			UpdatedAt:   dbItem.UpdatedAt,
+		})
+	}
+	return items, nil
+}
+
+func (s *itemStore) DeleteByOwnerID(ctx context.Context, ownerID int32) ([]int32, error) {
+	ids, err := s.queries.DeleteItemsByOwnerID(ctx, sql.NullInt32{Int32: ownerID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete items of owner %d from DB: %w", ownerID, err)
+	}
+	return ids, nil
+}
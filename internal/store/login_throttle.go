@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/model"
+)
+
+type LoginThrottleStore interface {
+	Get(ctx context.Context, scope, subject string) (*model.LoginThrottle, error)
+	RecordFailure(ctx context.Context, scope, subject string, windowStart time.Time) (*model.LoginThrottle, error)
+	Lock(ctx context.Context, scope, subject string, until time.Time) error
+	Clear(ctx context.Context, scope, subject string) error
+}
+
+type loginThrottleStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewLoginThrottleStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) LoginThrottleStore {
+	return &loginThrottleStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *loginThrottleStore) Get(ctx context.Context, scope, subject string) (*model.LoginThrottle, error) {
+	dbThrottle, err := s.queries.GetLoginThrottle(ctx, sqlc.GetLoginThrottleParams{
+		Scope:   scope,
+		Subject: subject,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get login throttle from DB: %w", err)
+	}
+	return &model.LoginThrottle{
+		Scope:        dbThrottle.Scope,
+		Subject:      dbThrottle.Subject,
+		FailedCount:  dbThrottle.FailedCount,
+		LastFailedAt: dbThrottle.LastFailedAt,
+		LockedUntil:  model.FromSQLNullTime(dbThrottle.LockedUntil),
+	}, nil
+}
+
+// RecordFailure increments the failure counter, restarting it when the previous
+// failure happened before windowStart.
+func (s *loginThrottleStore) RecordFailure(ctx context.Context, scope, subject string, windowStart time.Time) (*model.LoginThrottle, error) {
+	dbThrottle, err := s.queries.RecordLoginFailure(ctx, sqlc.RecordLoginFailureParams{
+		Scope:       scope,
+		Subject:     subject,
+		WindowStart: windowStart,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure in DB: %w", err)
+	}
+	return &model.LoginThrottle{
+		Scope:        dbThrottle.Scope,
+		Subject:      dbThrottle.Subject,
+		FailedCount:  dbThrottle.FailedCount,
+		LastFailedAt: dbThrottle.LastFailedAt,
+		LockedUntil:  model.FromSQLNullTime(dbThrottle.LockedUntil),
+	}, nil
+}
+
+func (s *loginThrottleStore) Lock(ctx context.Context, scope, subject string, until time.Time) error {
+	err := s.queries.LockLoginThrottle(ctx, sqlc.LockLoginThrottleParams{
+		Scope:       scope,
+		Subject:     subject,
+		LockedUntil: sql.NullTime{Time: until, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock login throttle in DB: %w", err)
+	}
+	return nil
+}
+
+func (s *loginThrottleStore) Clear(ctx context.Context, scope, subject string) error {
+	err := s.queries.ClearLoginThrottle(ctx, sqlc.ClearLoginThrottleParams{
+		Scope:   scope,
+		Subject: subject,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear login throttle in DB: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"external-backend-go/db/sqlc"
+)
+
+type MagicLinkStore interface {
+	// Redeem marks the magic link identified by jti as used until expiresAt, which has
+	// to be no earlier than the last moment the link is accepted. It returns false when
+	// the link was already redeemed.
+	Redeem(ctx context.Context, jti string, userID int32, expiresAt time.Time) (bool, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type magicLinkStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewMagicLinkStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) MagicLinkStore {
+	return &magicLinkStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *magicLinkStore) Redeem(ctx context.Context, jti string, userID int32, expiresAt time.Time) (bool, error) {
+	rows, err := s.queries.RedeemMagicLink(ctx, sqlc.RedeemMagicLinkParams{
+		Jti:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to redeem magic link in DB: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (s *magicLinkStore) DeleteExpired(ctx context.Context) error {
+	err := s.queries.DeleteExpiredMagicLinkRedemptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired magic link redemptions from DB: %w", err)
+	}
+	return nil
+}
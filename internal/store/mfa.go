@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/model"
+)
+
+type MFAStore interface {
+	UpsertPending(ctx context.Context, userID int32, encryptedSecret string) (*model.UserMFA, error)
+	GetByUserID(ctx context.Context, userID int32) (*model.UserMFA, error)
+	// Enable turns MFA on and stores the recovery codes in one transaction, so a user
+	// never ends up with one but not the other.
+	Enable(ctx context.Context, userID int32, recoveryCodeHashes []string) (*model.UserMFA, error)
+	AdvanceLastUsedStep(ctx context.Context, userID int32, step int64) (bool, error)
+	Delete(ctx context.Context, userID int32) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int32, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int32, codeHash string) (bool, error)
+	// RedeemPendingToken marks the mfa_pending token identified by jti as used until
+	// expiresAt. It returns false when the token was already redeemed.
+	RedeemPendingToken(ctx context.Context, jti string, userID int32, expiresAt time.Time) (bool, error)
+	DeleteExpiredPendingRedemptions(ctx context.Context) error
+}
+
+type mfaStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewMFAStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) MFAStore {
+	return &mfaStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *mfaStore) UpsertPending(ctx context.Context, userID int32, encryptedSecret string) (*model.UserMFA, error) {
+	dbMFA, err := s.queries.UpsertUserMFA(ctx, sqlc.UpsertUserMFAParams{
+		UserID:     userID,
+		TotpSecret: encryptedSecret,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert user MFA in DB: %w", err)
+	}
+	return &model.UserMFA{
+		UserID:       dbMFA.UserID,
+		TOTPSecret:   dbMFA.TotpSecret,
+		EnabledAt:    model.FromSQLNullTime(dbMFA.EnabledAt),
+		LastUsedStep: dbMFA.LastUsedStep,
+		CreatedAt:    dbMFA.CreatedAt,
+		UpdatedAt:    dbMFA.UpdatedAt,
+	}, nil
+}
+
+func (s *mfaStore) GetByUserID(ctx context.Context, userID int32) (*model.UserMFA, error) {
+	dbMFA, err := s.queries.GetUserMFA(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get user MFA from DB: %w", err)
+	}
+	return &model.UserMFA{
+		UserID:       dbMFA.UserID,
+		TOTPSecret:   dbMFA.TotpSecret,
+		EnabledAt:    model.FromSQLNullTime(dbMFA.EnabledAt),
+		LastUsedStep: dbMFA.LastUsedStep,
+		CreatedAt:    dbMFA.CreatedAt,
+		UpdatedAt:    dbMFA.UpdatedAt,
+	}, nil
+}
+
+func (s *mfaStore) Enable(ctx context.Context, userID int32, recoveryCodeHashes []string) (*model.UserMFA, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	if err := replaceRecoveryCodes(ctx, qtx, userID, recoveryCodeHashes); err != nil {
+		return nil, err
+	}
+	dbMFA, err := qtx.EnableUserMFA(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to enable user MFA in DB: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &model.UserMFA{
+		UserID:       dbMFA.UserID,
+		TOTPSecret:   dbMFA.TotpSecret,
+		EnabledAt:    model.FromSQLNullTime(dbMFA.EnabledAt),
+		LastUsedStep: dbMFA.LastUsedStep,
+		CreatedAt:    dbMFA.CreatedAt,
+		UpdatedAt:    dbMFA.UpdatedAt,
+	}, nil
+}
+
+// AdvanceLastUsedStep records step as the latest accepted TOTP time step. It returns
+// false when the step is not newer than the stored one, i.e. the code was replayed.
+func (s *mfaStore) AdvanceLastUsedStep(ctx context.Context, userID int32, step int64) (bool, error) {
+	rows, err := s.queries.AdvanceUserMFAStep(ctx, sqlc.AdvanceUserMFAStepParams{
+		UserID:       userID,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to advance user MFA step in DB: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (s *mfaStore) Delete(ctx context.Context, userID int32) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	if err := qtx.DeleteMFARecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete MFA recovery codes from DB: %w", err)
+	}
+	if err := qtx.DeleteUserMFA(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user MFA from DB: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes discards every existing recovery code of the user and stores
+// the given hashes in their place.
+func (s *mfaStore) ReplaceRecoveryCodes(ctx context.Context, userID int32, codeHashes []string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, s.queries.WithTx(tx), userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, qtx *sqlc.Queries, userID int32, codeHashes []string) error {
+	if err := qtx.DeleteMFARecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete MFA recovery codes from DB: %w", err)
+	}
+	for _, codeHash := range codeHashes {
+		err := qtx.CreateMFARecoveryCode(ctx, sqlc.CreateMFARecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: codeHash,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create MFA recovery code in DB: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the matching unused recovery code as used. It returns false
+// when no such code exists.
+func (s *mfaStore) UseRecoveryCode(ctx context.Context, userID int32, codeHash string) (bool, error) {
+	rows, err := s.queries.UseMFARecoveryCode(ctx, sqlc.UseMFARecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: codeHash,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to use MFA recovery code in DB: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (s *mfaStore) RedeemPendingToken(ctx context.Context, jti string, userID int32, expiresAt time.Time) (bool, error) {
+	rows, err := s.queries.RedeemMFAPendingToken(ctx, sqlc.RedeemMFAPendingTokenParams{
+		Jti:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to redeem MFA pending token in DB: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (s *mfaStore) DeleteExpiredPendingRedemptions(ctx context.Context) error {
+	err := s.queries.DeleteExpiredMFAPendingRedemptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired MFA pending redemptions from DB: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"external-backend-go/db/sqlc"
+)
+
+// OIDCAuthRequestStore keeps OpenID Connect logins between the redirect to the
+// provider and the callback.
+type OIDCAuthRequestStore interface {
+	Create(ctx context.Context, arg sqlc.CreateOIDCAuthRequestParams) error
+	// Consume deletes an unexpired request and returns it, or returns sql.ErrNoRows
+	// when no such request exists.
+	Consume(ctx context.Context, stateHash string) (sqlc.OidcAuthRequest, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type oidcAuthRequestStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewOIDCAuthRequestStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) OIDCAuthRequestStore {
+	return &oidcAuthRequestStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *oidcAuthRequestStore) Create(ctx context.Context, arg sqlc.CreateOIDCAuthRequestParams) error {
+	if err := s.queries.CreateOIDCAuthRequest(ctx, arg); err != nil {
+		return fmt.Errorf("failed to create OIDC auth request in DB: %w", err)
+	}
+	return nil
+}
+
+func (s *oidcAuthRequestStore) Consume(ctx context.Context, stateHash string) (sqlc.OidcAuthRequest, error) {
+	request, err := s.queries.ConsumeOIDCAuthRequest(ctx, stateHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return sqlc.OidcAuthRequest{}, sql.ErrNoRows
+		}
+		return sqlc.OidcAuthRequest{}, fmt.Errorf("failed to consume OIDC auth request in DB: %w", err)
+	}
+	return request, nil
+}
+
+func (s *oidcAuthRequestStore) DeleteExpired(ctx context.Context) error {
+	if err := s.queries.DeleteExpiredOIDCAuthRequests(ctx); err != nil {
+		return fmt.Errorf("failed to delete expired OIDC auth requests from DB: %w", err)
+	}
+	return nil
+}
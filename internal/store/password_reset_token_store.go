@@ -11,8 +11,11 @@ import (
 )
 
 type PasswordResetTokenStore interface {
-	CreatePasswordResetToken(ctx context.Context, email, token string, createdAt time.Time) (*model.PasswordResetToken, error)
+	CreatePasswordResetToken(ctx context.Context, email, tokenHash string, expiresAt time.Time) (*model.PasswordResetToken, error)
 	GetPasswordResetToken(ctx context.Context, email string) (*model.PasswordResetToken, error)
+	// ConsumePasswordResetToken deletes and returns the token for email if tokenHash
+	// matches and it has not expired, or returns sql.ErrNoRows otherwise.
+	ConsumePasswordResetToken(ctx context.Context, email, tokenHash string) (*model.PasswordResetToken, error)
 	DeletePasswordResetToken(ctx context.Context, email string) error
 }
 
@@ -25,11 +28,11 @@ func NewPasswordResetTokenStore(db *sql.DB, queries *sqlc.Queries, baseRepo *Bas
 	return &passwordResetTokenStore{BaseRepository: baseRepo, queries: queries}
 }
 
-func (s *passwordResetTokenStore) CreatePasswordResetToken(ctx context.Context, email, token string, createdAt time.Time) (*model.PasswordResetToken, error) {
+func (s *passwordResetTokenStore) CreatePasswordResetToken(ctx context.Context, email, tokenHash string, expiresAt time.Time) (*model.PasswordResetToken, error) {
 	params := sqlc.CreatePasswordResetTokenParams{
 		Email:     email,
-		Token:     token,
-		CreatedAt: sql.NullTime{Time: createdAt, Valid: true},
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
 	}
 	createdToken, err := s.queries.CreatePasswordResetToken(ctx, params)
 	if err != nil {
@@ -37,8 +40,9 @@ func (s *passwordResetTokenStore) CreatePasswordResetToken(ctx context.Context,
 	}
 	return &model.PasswordResetToken{
 		Email:     createdToken.Email,
-		Token:     createdToken.Token,
-		CreatedAt: model.FromSQLNullTime(createdToken.CreatedAt),
+		TokenHash: createdToken.TokenHash,
+		CreatedAt: createdToken.CreatedAt,
+		ExpiresAt: createdToken.ExpiresAt,
 	}, nil
 }
 
@@ -52,8 +56,28 @@ func (s *passwordResetTokenStore) GetPasswordResetToken(ctx context.Context, ema
 	}
 	return &model.PasswordResetToken{
 		Email:     dbToken.Email,
-		Token:     dbToken.Token,
-		CreatedAt: model.FromSQLNullTime(dbToken.CreatedAt),
+		TokenHash: dbToken.TokenHash,
+		CreatedAt: dbToken.CreatedAt,
+		ExpiresAt: dbToken.ExpiresAt,
+	}, nil
+}
+
+func (s *passwordResetTokenStore) ConsumePasswordResetToken(ctx context.Context, email, tokenHash string) (*model.PasswordResetToken, error) {
+	dbToken, err := s.queries.ConsumePasswordResetToken(ctx, sqlc.ConsumePasswordResetTokenParams{
+		Email:     email,
+		TokenHash: tokenHash,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to consume password reset token in DB: %w", err)
+	}
+	return &model.PasswordResetToken{
+		Email:     dbToken.Email,
+		TokenHash: dbToken.TokenHash,
+		CreatedAt: dbToken.CreatedAt,
+		ExpiresAt: dbToken.ExpiresAt,
 	}, nil
 }
 
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"external-backend-go/db/sqlc"
+)
+
+type PermissionStore interface {
+	// ListByRoleName returns the names of the permissions granted to a role.
+	ListByRoleName(ctx context.Context, roleName string) ([]string, error)
+}
+
+type permissionStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewPermissionStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) PermissionStore {
+	return &permissionStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *permissionStore) ListByRoleName(ctx context.Context, roleName string) ([]string, error) {
+	permissions, err := s.queries.ListPermissionNamesByRoleName(ctx, roleName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions for role %s from DB: %w", roleName, err)
+	}
+	return permissions, nil
+}
+
+type permissionCacheEntry struct {
+	permissions []string
+	expiresAt   time.Time
+}
+
+// cachedPermissionStore keeps the permissions of each role in memory for ttl, so
+// permission checks on every request do not cost a DB round trip. Changes to
+// role_permissions become visible once the entry expires.
+type cachedPermissionStore struct {
+	PermissionStore
+	ttl     time.Duration
+	entries map[string]permissionCacheEntry
+	mu      sync.RWMutex
+}
+
+func NewCachedPermissionStore(inner PermissionStore, ttl time.Duration) PermissionStore {
+	return &cachedPermissionStore{
+		PermissionStore: inner,
+		ttl:             ttl,
+		entries:         make(map[string]permissionCacheEntry),
+	}
+}
+
+func (c *cachedPermissionStore) ListByRoleName(ctx context.Context, roleName string) ([]string, error) {
+	if c.ttl <= 0 {
+		return c.PermissionStore.ListByRoleName(ctx, roleName)
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[roleName]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.permissions, nil
+	}
+
+	permissions, err := c.PermissionStore.ListByRoleName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[roleName] = permissionCacheEntry{permissions: permissions, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return permissions, nil
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"external-backend-go/db/sqlc"
+)
+
+// RememberTokenStore keeps the remember-me tokens of each user, one per remembered
+// device. Each token is tied to the session it last started.
+type RememberTokenStore interface {
+	Create(ctx context.Context, selector string, userID int32, validatorHash, sessionID string, expiresAt time.Time) error
+	GetBySelector(ctx context.Context, selector string) (sqlc.RememberToken, error)
+	// Rotate replaces the token with oldSelector, tying the new one to sessionID. It
+	// returns false when the token was already rotated or has expired.
+	Rotate(ctx context.Context, oldSelector, newSelector, newValidatorHash, sessionID string, expiresAt time.Time) (bool, error)
+	Delete(ctx context.Context, selector string) error
+	DeleteBySessionID(ctx context.Context, sessionID string) error
+	DeleteByUserID(ctx context.Context, userID int32) error
+	// DeleteOthers deletes every token of the user except the one tied to sessionID.
+	DeleteOthers(ctx context.Context, userID int32, sessionID string) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type rememberTokenStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewRememberTokenStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) RememberTokenStore {
+	return &rememberTokenStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *rememberTokenStore) Create(ctx context.Context, selector string, userID int32, validatorHash, sessionID string, expiresAt time.Time) error {
+	err := s.queries.CreateRememberToken(ctx, sqlc.CreateRememberTokenParams{
+		Selector:      selector,
+		UserID:        userID,
+		ValidatorHash: validatorHash,
+		SessionID:     sessionID,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create remember token in DB: %w", err)
+	}
+	return nil
+}
+
+func (s *rememberTokenStore) GetBySelector(ctx context.Context, selector string) (sqlc.RememberToken, error) {
+	token, err := s.queries.GetRememberToken(ctx, selector)
+	if err != nil {
+		return sqlc.RememberToken{}, fmt.Errorf("failed to get remember token from DB: %w", err)
+	}
+	return token, nil
+}
+
+func (s *rememberTokenStore) Rotate(ctx context.Context, oldSelector, newSelector, newValidatorHash, sessionID string, expiresAt time.Time) (bool, error) {
+	rows, err := s.queries.RotateRememberToken(ctx, sqlc.RotateRememberTokenParams{
+		NewSelector:      newSelector,
+		NewValidatorHash: newValidatorHash,
+		SessionID:        sessionID,
+		ExpiresAt:        expiresAt,
+		OldSelector:      oldSelector,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate remember token in DB: %w", err)
+	}
+	return rows == 1, nil
+}
+
+func (s *rememberTokenStore) Delete(ctx context.Context, selector string) error {
+	if err := s.queries.DeleteRememberToken(ctx, selector); err != nil {
+		return fmt.Errorf("failed to delete remember token from DB: %w", err)
+	}
+	return nil
+}
+
+func (s *rememberTokenStore) DeleteBySessionID(ctx context.Context, sessionID string) error {
+	if err := s.queries.DeleteRememberTokensBySessionID(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete remember tokens of session from DB: %w", err)
+	}
+	return nil
+}
+
+func (s *rememberTokenStore) DeleteByUserID(ctx context.Context, userID int32) error {
+	if err := s.queries.DeleteRememberTokensByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete remember tokens of user from DB: %w", err)
+	}
+	return nil
+}
+
+func (s *rememberTokenStore) DeleteOthers(ctx context.Context, userID int32, sessionID string) error {
+	err := s.queries.DeleteOtherRememberTokensByUserID(ctx, sqlc.DeleteOtherRememberTokensByUserIDParams{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete other remember tokens of user from DB: %w", err)
+	}
+	return nil
+}
+
+func (s *rememberTokenStore) DeleteExpired(ctx context.Context) error {
+	if err := s.queries.DeleteExpiredRememberTokens(ctx); err != nil {
+		return fmt.Errorf("failed to delete expired remember tokens from DB: %w", err)
+	}
+	return nil
+}
@@ -28,6 +28,7 @@ func (s *roleStore) Create(ctx context.Context, role *model.Role) (*model.Role,
 	params := sqlc.CreateRoleParams{
 		Name:        role.Name,
 		Description: role.Description.ToSQLNullString(),
+		ParentID:    role.ParentID.ToSQLNullInt32(),
 	}
 	createdRole, err := s.queries.CreateRole(ctx, params)
 	if err != nil {
@@ -37,6 +38,7 @@ func (s *roleStore) Create(ctx context.Context, role *model.Role) (*model.Role,
 		ID:          createdRole.ID,
 		Name:        createdRole.Name,
 		Description: model.FromSQLNullString(createdRole.Description),
+		ParentID:    model.FromSQLNullInt32(createdRole.ParentID),
 		CreatedAt:   createdRole.CreatedAt,
 		UpdatedAt:   createdRole.UpdatedAt,
 	}, nil
@@ -54,6 +56,7 @@ func (s *roleStore) GetByID(ctx context.Context, id int32) (*model.Role, error)
 		ID:          dbRole.ID,
 		Name:        dbRole.Name,
 		Description: model.FromSQLNullString(dbRole.Description),
+		ParentID:    model.FromSQLNullInt32(dbRole.ParentID),
 		CreatedAt:   dbRole.CreatedAt,
 		UpdatedAt:   dbRole.UpdatedAt,
 	}, nil
@@ -64,6 +67,7 @@ func (s *roleStore) Update(ctx context.Context, role *model.Role) (*model.Role,
 		ID:          role.ID,
 		Name:        role.Name,
 		Description: role.Description.ToSQLNullString(),
+		ParentID:    role.ParentID.ToSQLNullInt32(),
 	}
 	updatedRole, err := s.queries.UpdateRole(ctx, params)
 	if err != nil {
@@ -76,6 +80,7 @@ func (s *roleStore) Update(ctx context.Context, role *model.Role) (*model.Role,
 		ID:          updatedRole.ID,
 		Name:        updatedRole.Name,
 		Description: model.FromSQLNullString(updatedRole.Description),
+		ParentID:    model.FromSQLNullInt32(updatedRole.ParentID),
 		CreatedAt:   updatedRole.CreatedAt,
 		UpdatedAt:   updatedRole.UpdatedAt,
 	}, nil
@@ -108,6 +113,7 @@ func (s *roleStore) List(ctx context.Context, offset, limit int32) ([]*model.Rol
 			ID:          dbRole.ID,
 			Name:        dbRole.Name,
 			Description: model.FromSQLNullString(dbRole.Description),
+			ParentID:    model.FromSQLNullInt32(dbRole.ParentID),
 			CreatedAt:   dbRole.CreatedAt,
 			UpdatedAt:   dbRole.UpdatedAt,
 		})
@@ -135,6 +141,7 @@ func (s *roleStore) GetRoleByName(ctx context.Context, name string) (*model.Role
 		ID:          dbRole.ID,
 		Name:        dbRole.Name,
 		Description: model.FromSQLNullString(dbRole.Description),
+		ParentID:    model.FromSQLNullInt32(dbRole.ParentID),
 		CreatedAt:   dbRole.CreatedAt,
 		UpdatedAt:   dbRole.UpdatedAt,
 	}, nil
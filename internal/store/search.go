@@ -15,6 +15,9 @@ type SearchStore interface {
 	IndexDocument(ctx context.Context, indexName string, docID string, document interface{}) error
 	Search(ctx context.Context, indexName string, query string, fields []string, page, pageSize int) ([]json.RawMessage, int64, error)
 	DeleteDocument(ctx context.Context, indexName string, docID string) error
+	// DeleteDocumentsByField deletes every document whose field equals value and
+	// returns how many were deleted.
+	DeleteDocumentsByField(ctx context.Context, indexName string, field string, value interface{}) (int64, error)
 }
 
 type genericSearchStore struct {
@@ -92,3 +95,20 @@ func (s *genericSearchStore) DeleteDocument(ctx context.Context, indexName strin
 	s.logger.Info("Deleted document %s from index %s", docID, indexName)
 	return nil
 }
+
+func (s *genericSearchStore) DeleteDocumentsByField(ctx context.Context, indexName string, field string, value interface{}) (int64, error) {
+	res, err := s.esClient.ESClient.DeleteByQuery(indexName).
+		Query(elastic.NewTermQuery(field, value)).
+		ProceedOnVersionConflict().
+		Refresh("true").
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return 0, nil
+		}
+		s.logger.Error("Failed to delete documents with %s=%v from index %s: %v", field, value, indexName, err)
+		return 0, fmt.Errorf("failed to delete documents: %w", err)
+	}
+	s.logger.Info("Deleted %d documents with %s=%v from index %s", res.Deleted, field, value, indexName)
+	return res.Deleted, nil
+}
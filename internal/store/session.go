@@ -13,7 +13,16 @@ type SessionStore interface {
 	CreateSession(ctx context.Context, session *model.Session) (*model.Session, error)
 	GetSessionByID(ctx context.Context, id string) (*model.Session, error)
 	UpdateSession(ctx context.Context, session *model.Session) (*model.Session, error)
+	RotateRefreshToken(ctx context.Context, session *model.Session, currentRefreshTokenHash string) (*model.Session, error)
 	DeleteSession(ctx context.Context, id string) error
+	DeleteSessionsByUserID(ctx context.Context, userID int32) error
+	// DeleteOtherSessions deletes every session of userID except keepID.
+	DeleteOtherSessions(ctx context.Context, userID int32, keepID string) error
+	ListSessionsByUserID(ctx context.Context, userID int32) ([]*model.Session, error)
+	// DeleteUserSession deletes the session only if it belongs to userID, and returns
+	// sql.ErrNoRows otherwise.
+	DeleteUserSession(ctx context.Context, userID int32, id string) error
+	IsSessionActive(ctx context.Context, id string) (bool, error)
 	DeleteExpiredSessions(ctx context.Context, lastActivity int32) error
 }
 
@@ -28,24 +37,30 @@ func NewSessionStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository
 
 func (s *sessionStore) CreateSession(ctx context.Context, session *model.Session) (*model.Session, error) {
 	params := sqlc.CreateSessionParams{
-		ID:           session.ID,
-		UserID:       session.UserID,
-		IpAddress:    session.IpAddress.ToSQLNullString(),
-		UserAgent:    session.UserAgent.ToSQLNullString(),
-		Payload:      session.Payload,
-		LastActivity: session.LastActivity,
+		ID:               session.ID,
+		UserID:           session.UserID,
+		IpAddress:        session.IpAddress.ToSQLNullString(),
+		UserAgent:        session.UserAgent.ToSQLNullString(),
+		Payload:          session.Payload,
+		LastActivity:     session.LastActivity,
+		RefreshTokenHash: session.RefreshTokenHash.ToSQLNullString(),
+		RefreshExpiresAt: session.RefreshExpiresAt.ToSQLNullTime(),
 	}
 	createdSession, err := s.queries.CreateSession(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session in DB: %w", err)
 	}
 	return &model.Session{
-		ID:           createdSession.ID,
-		UserID:       createdSession.UserID,
-		IpAddress:    model.FromSQLNullString(createdSession.IpAddress),
-		UserAgent:    model.FromSQLNullString(createdSession.UserAgent),
-		Payload:      createdSession.Payload,
-		LastActivity: createdSession.LastActivity,
+		ID:                       createdSession.ID,
+		UserID:                   createdSession.UserID,
+		IpAddress:                model.FromSQLNullString(createdSession.IpAddress),
+		UserAgent:                model.FromSQLNullString(createdSession.UserAgent),
+		Payload:                  createdSession.Payload,
+		LastActivity:             createdSession.LastActivity,
+		RefreshTokenHash:         model.FromSQLNullString(createdSession.RefreshTokenHash),
+		RefreshExpiresAt:         model.FromSQLNullTime(createdSession.RefreshExpiresAt),
+		CreatedAt:                createdSession.CreatedAt,
+		PreviousRefreshTokenHash: model.FromSQLNullString(createdSession.PreviousRefreshTokenHash),
 	}, nil
 }
 
@@ -58,12 +73,16 @@ func (s *sessionStore) GetSessionByID(ctx context.Context, id string) (*model.Se
 		return nil, fmt.Errorf("failed to get session by ID from DB: %w", err)
 	}
 	return &model.Session{
-		ID:           dbSession.ID,
-		UserID:       dbSession.UserID,
-		IpAddress:    model.FromSQLNullString(dbSession.IpAddress),
-		UserAgent:    model.FromSQLNullString(dbSession.UserAgent),
-		Payload:      dbSession.Payload,
-		LastActivity: dbSession.LastActivity,
+		ID:                       dbSession.ID,
+		UserID:                   dbSession.UserID,
+		IpAddress:                model.FromSQLNullString(dbSession.IpAddress),
+		UserAgent:                model.FromSQLNullString(dbSession.UserAgent),
+		Payload:                  dbSession.Payload,
+		LastActivity:             dbSession.LastActivity,
+		RefreshTokenHash:         model.FromSQLNullString(dbSession.RefreshTokenHash),
+		RefreshExpiresAt:         model.FromSQLNullTime(dbSession.RefreshExpiresAt),
+		CreatedAt:                dbSession.CreatedAt,
+		PreviousRefreshTokenHash: model.FromSQLNullString(dbSession.PreviousRefreshTokenHash),
 	}, nil
 }
 
@@ -84,12 +103,51 @@ func (s *sessionStore) UpdateSession(ctx context.Context, session *model.Session
 		return nil, fmt.Errorf("failed to update session in DB: %w", err)
 	}
 	return &model.Session{
-		ID:           updatedSession.ID,
-		UserID:       updatedSession.UserID,
-		IpAddress:    model.FromSQLNullString(updatedSession.IpAddress),
-		UserAgent:    model.FromSQLNullString(updatedSession.UserAgent),
-		Payload:      updatedSession.Payload,
-		LastActivity: updatedSession.LastActivity,
+		ID:                       updatedSession.ID,
+		UserID:                   updatedSession.UserID,
+		IpAddress:                model.FromSQLNullString(updatedSession.IpAddress),
+		UserAgent:                model.FromSQLNullString(updatedSession.UserAgent),
+		Payload:                  updatedSession.Payload,
+		LastActivity:             updatedSession.LastActivity,
+		RefreshTokenHash:         model.FromSQLNullString(updatedSession.RefreshTokenHash),
+		RefreshExpiresAt:         model.FromSQLNullTime(updatedSession.RefreshExpiresAt),
+		CreatedAt:                updatedSession.CreatedAt,
+		PreviousRefreshTokenHash: model.FromSQLNullString(updatedSession.PreviousRefreshTokenHash),
+	}, nil
+}
+
+// RotateRefreshToken swaps the session's refresh token hash for the one in session,
+// keeping the replaced hash as the previous one, but only if the stored hash still
+// equals currentRefreshTokenHash. It returns
+// sql.ErrNoRows when the token has already been rotated by another request.
+func (s *sessionStore) RotateRefreshToken(ctx context.Context, session *model.Session, currentRefreshTokenHash string) (*model.Session, error) {
+	params := sqlc.RotateSessionRefreshTokenParams{
+		NewRefreshTokenHash:     session.RefreshTokenHash.ToSQLNullString(),
+		RefreshExpiresAt:        session.RefreshExpiresAt.ToSQLNullTime(),
+		IpAddress:               session.IpAddress.ToSQLNullString(),
+		UserAgent:               session.UserAgent.ToSQLNullString(),
+		LastActivity:            session.LastActivity,
+		ID:                      session.ID,
+		CurrentRefreshTokenHash: sql.NullString{String: currentRefreshTokenHash, Valid: true},
+	}
+	rotatedSession, err := s.queries.RotateSessionRefreshToken(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to rotate session refresh token in DB: %w", err)
+	}
+	return &model.Session{
+		ID:                       rotatedSession.ID,
+		UserID:                   rotatedSession.UserID,
+		IpAddress:                model.FromSQLNullString(rotatedSession.IpAddress),
+		UserAgent:                model.FromSQLNullString(rotatedSession.UserAgent),
+		Payload:                  rotatedSession.Payload,
+		LastActivity:             rotatedSession.LastActivity,
+		RefreshTokenHash:         model.FromSQLNullString(rotatedSession.RefreshTokenHash),
+		RefreshExpiresAt:         model.FromSQLNullTime(rotatedSession.RefreshExpiresAt),
+		CreatedAt:                rotatedSession.CreatedAt,
+		PreviousRefreshTokenHash: model.FromSQLNullString(rotatedSession.PreviousRefreshTokenHash),
 	}, nil
 }
 
@@ -104,6 +162,73 @@ func (s *sessionStore) DeleteSession(ctx context.Context, id string) error {
 	return nil
 }
 
+func (s *sessionStore) DeleteSessionsByUserID(ctx context.Context, userID int32) error {
+	err := s.queries.DeleteSessionsByUserID(ctx, sql.NullInt32{Int32: userID, Valid: true})
+	if err != nil {
+		return fmt.Errorf("failed to delete sessions for user %d from DB: %w", userID, err)
+	}
+	return nil
+}
+
+func (s *sessionStore) DeleteOtherSessions(ctx context.Context, userID int32, keepID string) error {
+	err := s.queries.DeleteOtherSessionsByUserID(ctx, sqlc.DeleteOtherSessionsByUserIDParams{
+		UserID: sql.NullInt32{Int32: userID, Valid: true},
+		ID:     keepID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete other sessions for user %d from DB: %w", userID, err)
+	}
+	return nil
+}
+
+func (s *sessionStore) ListSessionsByUserID(ctx context.Context, userID int32) ([]*model.Session, error) {
+	dbSessions, err := s.queries.ListSessionsByUserID(ctx, sql.NullInt32{Int32: userID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions for user %d from DB: %w", userID, err)
+	}
+	sessions := make([]*model.Session, len(dbSessions))
+	for i, dbSession := range dbSessions {
+		sessions[i] = &model.Session{
+			ID:                       dbSession.ID,
+			UserID:                   dbSession.UserID,
+			IpAddress:                model.FromSQLNullString(dbSession.IpAddress),
+			UserAgent:                model.FromSQLNullString(dbSession.UserAgent),
+			Payload:                  dbSession.Payload,
+			LastActivity:             dbSession.LastActivity,
+			RefreshTokenHash:         model.FromSQLNullString(dbSession.RefreshTokenHash),
+			RefreshExpiresAt:         model.FromSQLNullTime(dbSession.RefreshExpiresAt),
+			CreatedAt:                dbSession.CreatedAt,
+			PreviousRefreshTokenHash: model.FromSQLNullString(dbSession.PreviousRefreshTokenHash),
+		}
+	}
+	return sessions, nil
+}
+
+func (s *sessionStore) DeleteUserSession(ctx context.Context, userID int32, id string) error {
+	rows, err := s.queries.DeleteUserSession(ctx, sqlc.DeleteUserSessionParams{
+		ID:     id,
+		UserID: sql.NullInt32{Int32: userID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete session from DB: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *sessionStore) IsSessionActive(ctx context.Context, id string) (bool, error) {
+	_, err := s.queries.GetSessionByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check session in DB: %w", err)
+	}
+	return true, nil
+}
+
 func (s *sessionStore) DeleteExpiredSessions(ctx context.Context, lastActivity int32) error {
 	err := s.queries.DeleteExpiredSessions(ctx, lastActivity)
 	if err != nil {
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"external-backend-go/internal/model"
+)
+
+type sessionCacheEntry struct {
+	active    bool
+	userID    int32
+	expiresAt time.Time
+}
+
+// cachedSessionStore wraps a SessionStore and keeps the result of IsSessionActive in
+// memory for ttl, so validating a session on every request does not cost a DB round
+// trip. Deletions made through this store drop the affected entries immediately;
+// deletions made by other processes become visible once the entry expires.
+type cachedSessionStore struct {
+	SessionStore
+	ttl     time.Duration
+	entries map[string]sessionCacheEntry
+	mu      sync.RWMutex
+}
+
+func NewCachedSessionStore(inner SessionStore, ttl time.Duration) SessionStore {
+	c := &cachedSessionStore{
+		SessionStore: inner,
+		ttl:          ttl,
+		entries:      make(map[string]sessionCacheEntry),
+	}
+	if ttl > 0 {
+		go c.cleanupEntries()
+	}
+	return c
+}
+
+func (c *cachedSessionStore) IsSessionActive(ctx context.Context, id string) (bool, error) {
+	if c.ttl <= 0 {
+		return c.SessionStore.IsSessionActive(ctx, id)
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[id]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.active, nil
+	}
+
+	session, err := c.SessionStore.GetSessionByID(ctx, id)
+	active := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	entry = sessionCacheEntry{active: active, expiresAt: time.Now().Add(c.ttl)}
+	if active && session.UserID.Valid {
+		entry.userID = session.UserID.Int32
+	}
+
+	c.mu.Lock()
+	c.entries[id] = entry
+	c.mu.Unlock()
+	return active, nil
+}
+
+func (c *cachedSessionStore) DeleteSession(ctx context.Context, id string) error {
+	err := c.SessionStore.DeleteSession(ctx, id)
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+	return err
+}
+
+func (c *cachedSessionStore) DeleteSessionsByUserID(ctx context.Context, userID int32) error {
+	err := c.SessionStore.DeleteSessionsByUserID(ctx, userID)
+	c.mu.Lock()
+	for id, entry := range c.entries {
+		if entry.userID == userID {
+			delete(c.entries, id)
+		}
+	}
+	c.mu.Unlock()
+	return err
+}
+
+func (c *cachedSessionStore) DeleteOtherSessions(ctx context.Context, userID int32, keepID string) error {
+	err := c.SessionStore.DeleteOtherSessions(ctx, userID, keepID)
+	c.mu.Lock()
+	for id, entry := range c.entries {
+		if entry.userID == userID && id != keepID {
+			delete(c.entries, id)
+		}
+	}
+	c.mu.Unlock()
+	return err
+}
+
+func (c *cachedSessionStore) DeleteUserSession(ctx context.Context, userID int32, id string) error {
+	err := c.SessionStore.DeleteUserSession(ctx, userID, id)
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+	return err
+}
+
+func (c *cachedSessionStore) DeleteExpiredSessions(ctx context.Context, lastActivity int32) error {
+	err := c.SessionStore.DeleteExpiredSessions(ctx, lastActivity)
+	c.mu.Lock()
+	c.entries = make(map[string]sessionCacheEntry)
+	c.mu.Unlock()
+	return err
+}
+
+func (c *cachedSessionStore) CreateSession(ctx context.Context, session *model.Session) (*model.Session, error) {
+	created, err := c.SessionStore.CreateSession(ctx, session)
+	if err == nil {
+		c.mu.Lock()
+		delete(c.entries, created.ID)
+		c.mu.Unlock()
+	}
+	return created, err
+}
+
+func (c *cachedSessionStore) cleanupEntries() {
+	for range time.Tick(c.ttl) {
+		now := time.Now()
+		c.mu.Lock()
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"external-backend-go/db/sqlc"
 	"external-backend-go/internal/model"
@@ -12,15 +13,37 @@ import (
 type UserStore interface {
 	RepositoryInterface[*model.User]
 
+	// CreateUser creates the user and records the primary role in user_roles in the
+	// same transaction.
 	CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error)
 	GetUserByUsername(ctx context.Context, username string) (sqlc.User, error)
 	GetUserByID(ctx context.Context, id int32) (sqlc.User, error)
-	UpdateUserRole(ctx context.Context, arg sqlc.UpdateUserRoleParams) (sqlc.User, error)
+	// UpdateUserRole replaces the primary role in users and user_roles and bumps the
+	// auth version, all in one transaction.
+	UpdateUserRole(ctx context.Context, id, roleID int32) (sqlc.User, error)
 	SoftDeleteUser(ctx context.Context, id int32) (sqlc.User, error)
 	RestoreUser(ctx context.Context, id int32) (sqlc.User, error)
 	VerifyUserEmail(ctx context.Context, id int32) (sqlc.User, error)
 	GetUserByEmail(ctx context.Context, email string) (sqlc.User, error)
 	UpdateUser(ctx context.Context, arg sqlc.UpdateUserParams) (sqlc.User, error)
+	UpdateUserProfile(ctx context.Context, arg sqlc.UpdateUserProfileParams) (sqlc.User, error)
+	UpdateUserPassword(ctx context.Context, id int32, hashedPassword string) (sqlc.User, error)
+	// UpdateUserEmail sets a confirmed email address and marks it as verified.
+	UpdateUserEmail(ctx context.Context, id int32, email string) (sqlc.User, error)
+	SearchUsers(ctx context.Context, arg sqlc.SearchUsersParams) ([]*model.User, error)
+	CountSearchUsers(ctx context.Context, arg sqlc.CountSearchUsersParams) (int64, error)
+	// GetByIDIncludingDeleted is GetByID without the soft-delete scope, for admin tools.
+	GetByIDIncludingDeleted(ctx context.Context, id int32) (*model.User, error)
+	// PurgeDeletedUsers permanently deletes users soft-deleted before cutoff and returns
+	// their IDs. Users with a pending erasure are skipped.
+	PurgeDeletedUsers(ctx context.Context, cutoff time.Time) ([]int32, error)
+	ScheduleErasure(ctx context.Context, id int32, at time.Time) (sqlc.User, error)
+	// CancelErasure returns sql.ErrNoRows when no erasure is pending for the user.
+	CancelErasure(ctx context.Context, id int32) (sqlc.User, error)
+	// ListDueForErasure returns the IDs of up to limit users whose erasure is due.
+	ListDueForErasure(ctx context.Context, limit int32) ([]int32, error)
+	// AnonymizeUser replaces the user's personal data and soft-deletes the account.
+	AnonymizeUser(ctx context.Context, id int32) (sqlc.User, error)
 }
 
 type userStore struct {
@@ -47,16 +70,19 @@ func (s *userStore) Create(ctx context.Context, user *model.User) (*model.User,
 		return nil, fmt.Errorf("failed to create user via generic store: %w", err)
 	}
 	return &model.User{
-		ID:                createdUser.ID,
-		Username:          createdUser.Username,
-		Email:             createdUser.Email,
-		HashedPassword:    createdUser.HashedPassword,
-		EmailVerifiedAt:   model.FromSQLNullTime(createdUser.EmailVerifiedAt),
-		RoleID:            createdUser.RoleID,
-		RememberTokenUUID: model.FromSQLNullString(createdUser.RememberTokenUuid),
-		CreatedAt:         createdUser.CreatedAt,
-		UpdatedAt:         createdUser.UpdatedAt,
-		DeletedAt:         model.FromSQLNullTime(createdUser.DeletedAt),
+		ID:                 createdUser.ID,
+		Username:           createdUser.Username,
+		Email:              createdUser.Email,
+		HashedPassword:     createdUser.HashedPassword,
+		EmailVerifiedAt:    model.FromSQLNullTime(createdUser.EmailVerifiedAt),
+		RoleID:             createdUser.RoleID,
+		CreatedAt:          createdUser.CreatedAt,
+		UpdatedAt:          createdUser.UpdatedAt,
+		DeletedAt:          model.FromSQLNullTime(createdUser.DeletedAt),
+		DisplayName:        model.FromSQLNullString(createdUser.DisplayName),
+		AvatarURL:          model.FromSQLNullString(createdUser.AvatarUrl),
+		ErasureScheduledAt: model.FromSQLNullTime(createdUser.ErasureScheduledAt),
+		ErasedAt:           model.FromSQLNullTime(createdUser.ErasedAt),
 	}, nil
 }
 
@@ -66,45 +92,72 @@ func (s *userStore) GetByID(ctx context.Context, id int32) (*model.User, error)
 		return nil, fmt.Errorf("failed to get user by ID via generic store: %w", err)
 	}
 	return &model.User{
-		ID:                dbUser.ID,
-		Username:          dbUser.Username,
-		Email:             dbUser.Email,
-		HashedPassword:    dbUser.HashedPassword,
-		EmailVerifiedAt:   model.FromSQLNullTime(dbUser.EmailVerifiedAt),
-		RoleID:            dbUser.RoleID,
-		RememberTokenUUID: model.FromSQLNullString(dbUser.RememberTokenUuid),
-		CreatedAt:         dbUser.CreatedAt,
-		UpdatedAt:         dbUser.UpdatedAt,
-		DeletedAt:         model.FromSQLNullTime(dbUser.DeletedAt),
+		ID:                 dbUser.ID,
+		Username:           dbUser.Username,
+		Email:              dbUser.Email,
+		HashedPassword:     dbUser.HashedPassword,
+		EmailVerifiedAt:    model.FromSQLNullTime(dbUser.EmailVerifiedAt),
+		RoleID:             dbUser.RoleID,
+		CreatedAt:          dbUser.CreatedAt,
+		UpdatedAt:          dbUser.UpdatedAt,
+		DeletedAt:          model.FromSQLNullTime(dbUser.DeletedAt),
+		DisplayName:        model.FromSQLNullString(dbUser.DisplayName),
+		AvatarURL:          model.FromSQLNullString(dbUser.AvatarUrl),
+		ErasureScheduledAt: model.FromSQLNullTime(dbUser.ErasureScheduledAt),
+		ErasedAt:           model.FromSQLNullTime(dbUser.ErasedAt),
+	}, nil
+}
+
+func (s *userStore) GetByIDIncludingDeleted(ctx context.Context, id int32) (*model.User, error) {
+	dbUser, err := s.queries.GetUserByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by ID including deleted via generic store: %w", err)
+	}
+	return &model.User{
+		ID:                 dbUser.ID,
+		Username:           dbUser.Username,
+		Email:              dbUser.Email,
+		HashedPassword:     dbUser.HashedPassword,
+		EmailVerifiedAt:    model.FromSQLNullTime(dbUser.EmailVerifiedAt),
+		RoleID:             dbUser.RoleID,
+		CreatedAt:          dbUser.CreatedAt,
+		UpdatedAt:          dbUser.UpdatedAt,
+		DeletedAt:          model.FromSQLNullTime(dbUser.DeletedAt),
+		DisplayName:        model.FromSQLNullString(dbUser.DisplayName),
+		AvatarURL:          model.FromSQLNullString(dbUser.AvatarUrl),
+		ErasureScheduledAt: model.FromSQLNullTime(dbUser.ErasureScheduledAt),
+		ErasedAt:           model.FromSQLNullTime(dbUser.ErasedAt),
 	}, nil
 }
 
 func (s *userStore) Update(ctx context.Context, user *model.User) (*model.User, error) {
 	params := sqlc.UpdateUserParams{
-		ID:                user.ID,
-		Username:          user.Username,
-		HashedPassword:    user.HashedPassword,
-		Email:             user.Email,
-		EmailVerifiedAt:   user.EmailVerifiedAt.ToSQLNullTime(),
-		RoleID:            user.RoleID,
-		RememberTokenUuid: user.RememberTokenUUID.ToSQLNullString(),
-		DeletedAt:         user.DeletedAt.ToSQLNullTime(),
+		ID:              user.ID,
+		Username:        user.Username,
+		HashedPassword:  user.HashedPassword,
+		Email:           user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt.ToSQLNullTime(),
+		RoleID:          user.RoleID,
+		DeletedAt:       user.DeletedAt.ToSQLNullTime(),
 	}
 	updatedUser, err := s.queries.UpdateUser(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user via generic store: %w", err)
 	}
 	return &model.User{
-		ID:                updatedUser.ID,
-		Username:          updatedUser.Username,
-		Email:             updatedUser.Email,
-		HashedPassword:    updatedUser.HashedPassword,
-		EmailVerifiedAt:   model.FromSQLNullTime(updatedUser.EmailVerifiedAt),
-		RoleID:            updatedUser.RoleID,
-		RememberTokenUUID: model.FromSQLNullString(updatedUser.RememberTokenUuid),
-		CreatedAt:         updatedUser.CreatedAt,
-		UpdatedAt:         updatedUser.UpdatedAt,
-		DeletedAt:         model.FromSQLNullTime(updatedUser.DeletedAt),
+		ID:                 updatedUser.ID,
+		Username:           updatedUser.Username,
+		Email:              updatedUser.Email,
+		HashedPassword:     updatedUser.HashedPassword,
+		EmailVerifiedAt:    model.FromSQLNullTime(updatedUser.EmailVerifiedAt),
+		RoleID:             updatedUser.RoleID,
+		CreatedAt:          updatedUser.CreatedAt,
+		UpdatedAt:          updatedUser.UpdatedAt,
+		DeletedAt:          model.FromSQLNullTime(updatedUser.DeletedAt),
+		DisplayName:        model.FromSQLNullString(updatedUser.DisplayName),
+		AvatarURL:          model.FromSQLNullString(updatedUser.AvatarUrl),
+		ErasureScheduledAt: model.FromSQLNullTime(updatedUser.ErasureScheduledAt),
+		ErasedAt:           model.FromSQLNullTime(updatedUser.ErasedAt),
 	}, nil
 }
 
@@ -125,16 +178,19 @@ func (s *userStore) List(ctx context.Context, offset, limit int32) ([]*model.Use
 	var users []*model.User
 	for _, dbUser := range dbUsers {
 		users = append(users, &model.User{
-			ID:                dbUser.ID,
-			Username:          dbUser.Username,
-			Email:             dbUser.Email,
-			HashedPassword:    dbUser.HashedPassword,
-			EmailVerifiedAt:   model.FromSQLNullTime(dbUser.EmailVerifiedAt),
-			RoleID:            dbUser.RoleID,
-			RememberTokenUUID: model.FromSQLNullString(dbUser.RememberTokenUuid),
-			CreatedAt:         dbUser.CreatedAt,
-			UpdatedAt:         dbUser.UpdatedAt,
-			DeletedAt:         model.FromSQLNullTime(dbUser.DeletedAt),
+			ID:                 dbUser.ID,
+			Username:           dbUser.Username,
+			Email:              dbUser.Email,
+			HashedPassword:     dbUser.HashedPassword,
+			EmailVerifiedAt:    model.FromSQLNullTime(dbUser.EmailVerifiedAt),
+			RoleID:             dbUser.RoleID,
+			CreatedAt:          dbUser.CreatedAt,
+			UpdatedAt:          dbUser.UpdatedAt,
+			DeletedAt:          model.FromSQLNullTime(dbUser.DeletedAt),
+			DisplayName:        model.FromSQLNullString(dbUser.DisplayName),
+			AvatarURL:          model.FromSQLNullString(dbUser.AvatarUrl),
+			ErasureScheduledAt: model.FromSQLNullTime(dbUser.ErasureScheduledAt),
+			ErasedAt:           model.FromSQLNullTime(dbUser.ErasedAt),
 		})
 	}
 	return users, nil
@@ -148,11 +204,67 @@ func (s *userStore) Count(ctx context.Context) (int64, error) {
 	return count, nil
 }
 
+func (s *userStore) SearchUsers(ctx context.Context, arg sqlc.SearchUsersParams) ([]*model.User, error) {
+	dbUsers, err := s.queries.SearchUsers(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users in DB: %w", err)
+	}
+
+	users := make([]*model.User, 0, len(dbUsers))
+	for _, dbUser := range dbUsers {
+		users = append(users, &model.User{
+			ID:                 dbUser.ID,
+			Username:           dbUser.Username,
+			Email:              dbUser.Email,
+			HashedPassword:     dbUser.HashedPassword,
+			EmailVerifiedAt:    model.FromSQLNullTime(dbUser.EmailVerifiedAt),
+			RoleID:             dbUser.RoleID,
+			CreatedAt:          dbUser.CreatedAt,
+			UpdatedAt:          dbUser.UpdatedAt,
+			DeletedAt:          model.FromSQLNullTime(dbUser.DeletedAt),
+			DisplayName:        model.FromSQLNullString(dbUser.DisplayName),
+			AvatarURL:          model.FromSQLNullString(dbUser.AvatarUrl),
+			ErasureScheduledAt: model.FromSQLNullTime(dbUser.ErasureScheduledAt),
+			ErasedAt:           model.FromSQLNullTime(dbUser.ErasedAt),
+		})
+	}
+	return users, nil
+}
+
+func (s *userStore) CountSearchUsers(ctx context.Context, arg sqlc.CountSearchUsersParams) (int64, error) {
+	count, err := s.queries.CountSearchUsers(ctx, arg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count searched users in DB: %w", err)
+	}
+	return count, nil
+}
+
+func (s *userStore) PurgeDeletedUsers(ctx context.Context, cutoff time.Time) ([]int32, error) {
+	ids, err := s.queries.PurgeDeletedUsers(ctx, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge deleted users in DB: %w", err)
+	}
+	return ids, nil
+}
+
 func (s *userStore) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
-	user, err := s.queries.CreateUser(ctx, arg)
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	user, err := qtx.CreateUser(ctx, arg)
 	if err != nil {
 		return sqlc.User{}, fmt.Errorf("failed to create user via store: %w", err)
 	}
+	if err := qtx.AddUserRole(ctx, sqlc.AddUserRoleParams{UserID: user.ID, RoleID: user.RoleID}); err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to assign role to new user via store: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return user, nil
 }
 
@@ -172,11 +284,36 @@ func (s *userStore) GetUserByID(ctx context.Context, id int32) (sqlc.User, error
 	return user, nil
 }
 
-func (s *userStore) UpdateUserRole(ctx context.Context, arg sqlc.UpdateUserRoleParams) (sqlc.User, error) {
-	user, err := s.queries.UpdateUserRole(ctx, arg)
+func (s *userStore) UpdateUserRole(ctx context.Context, id, roleID int32) (sqlc.User, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	existing, err := qtx.GetUserByID(ctx, id)
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to get user via store: %w", err)
+	}
+	user, err := qtx.UpdateUserRole(ctx, sqlc.UpdateUserRoleParams{ID: id, RoleID: roleID})
 	if err != nil {
 		return sqlc.User{}, fmt.Errorf("failed to update user role via store: %w", err)
 	}
+	if err := qtx.AddUserRole(ctx, sqlc.AddUserRoleParams{UserID: id, RoleID: roleID}); err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to assign new primary role via store: %w", err)
+	}
+	if existing.RoleID != roleID {
+		if _, err := qtx.RemoveUserRole(ctx, sqlc.RemoveUserRoleParams{UserID: id, RoleID: existing.RoleID}); err != nil {
+			return sqlc.User{}, fmt.Errorf("failed to remove old primary role via store: %w", err)
+		}
+	}
+	if user.AuthVersion, err = qtx.BumpUserAuthVersion(ctx, id); err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to bump auth version via store: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return user, nil
 }
 
@@ -219,3 +356,62 @@ func (s *userStore) UpdateUser(ctx context.Context, arg sqlc.UpdateUserParams) (
 	}
 	return user, nil
 }
+
+func (s *userStore) UpdateUserProfile(ctx context.Context, arg sqlc.UpdateUserProfileParams) (sqlc.User, error) {
+	user, err := s.queries.UpdateUserProfile(ctx, arg)
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to update user profile via store: %w", err)
+	}
+	return user, nil
+}
+
+func (s *userStore) UpdateUserPassword(ctx context.Context, id int32, hashedPassword string) (sqlc.User, error) {
+	user, err := s.queries.UpdateUserPassword(ctx, sqlc.UpdateUserPasswordParams{ID: id, HashedPassword: hashedPassword})
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to update user password via store: %w", err)
+	}
+	return user, nil
+}
+
+func (s *userStore) UpdateUserEmail(ctx context.Context, id int32, email string) (sqlc.User, error) {
+	user, err := s.queries.UpdateUserEmail(ctx, sqlc.UpdateUserEmailParams{ID: id, Email: email})
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to update user email via store: %w", err)
+	}
+	return user, nil
+}
+
+func (s *userStore) ScheduleErasure(ctx context.Context, id int32, at time.Time) (sqlc.User, error) {
+	user, err := s.queries.ScheduleUserErasure(ctx, sqlc.ScheduleUserErasureParams{
+		ID:                 id,
+		ErasureScheduledAt: sql.NullTime{Time: at, Valid: true},
+	})
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to schedule user erasure via store: %w", err)
+	}
+	return user, nil
+}
+
+func (s *userStore) CancelErasure(ctx context.Context, id int32) (sqlc.User, error) {
+	user, err := s.queries.CancelUserErasure(ctx, id)
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to cancel user erasure via store: %w", err)
+	}
+	return user, nil
+}
+
+func (s *userStore) ListDueForErasure(ctx context.Context, limit int32) ([]int32, error) {
+	ids, err := s.queries.ListUsersDueForErasure(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users due for erasure in DB: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *userStore) AnonymizeUser(ctx context.Context, id int32) (sqlc.User, error) {
+	user, err := s.queries.AnonymizeUser(ctx, id)
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to anonymize user via store: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"external-backend-go/db/sqlc"
+)
+
+// UserIdentityStore keeps the accounts at external identity providers that users
+// log in with.
+type UserIdentityStore interface {
+	// Create links the provider's subject to the user. It fails with a unique
+	// violation when the subject is already linked.
+	Create(ctx context.Context, userID int32, provider, subject, email string) (sqlc.UserIdentity, error)
+	// Get returns sql.ErrNoRows when the subject is not linked to any user.
+	Get(ctx context.Context, provider, subject string) (sqlc.UserIdentity, error)
+	// Touch records a login through the identity.
+	Touch(ctx context.Context, id int32, email string) error
+	DeleteByUserID(ctx context.Context, userID int32) error
+}
+
+type userIdentityStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewUserIdentityStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) UserIdentityStore {
+	return &userIdentityStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *userIdentityStore) Create(ctx context.Context, userID int32, provider, subject, email string) (sqlc.UserIdentity, error) {
+	identity, err := s.queries.CreateUserIdentity(ctx, sqlc.CreateUserIdentityParams{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    sql.NullString{String: email, Valid: email != ""},
+	})
+	if err != nil {
+		return sqlc.UserIdentity{}, fmt.Errorf("failed to create user identity in DB: %w", err)
+	}
+	return identity, nil
+}
+
+func (s *userIdentityStore) Get(ctx context.Context, provider, subject string) (sqlc.UserIdentity, error) {
+	identity, err := s.queries.GetUserIdentity(ctx, sqlc.GetUserIdentityParams{
+		Provider: provider,
+		Subject:  subject,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return sqlc.UserIdentity{}, sql.ErrNoRows
+		}
+		return sqlc.UserIdentity{}, fmt.Errorf("failed to get user identity from DB: %w", err)
+	}
+	return identity, nil
+}
+
+func (s *userIdentityStore) Touch(ctx context.Context, id int32, email string) error {
+	err := s.queries.TouchUserIdentity(ctx, sqlc.TouchUserIdentityParams{
+		ID:    id,
+		Email: sql.NullString{String: email, Valid: email != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update user identity in DB: %w", err)
+	}
+	return nil
+}
+
+func (s *userIdentityStore) DeleteByUserID(ctx context.Context, userID int32) error {
+	err := s.queries.DeleteUserIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user identities from DB: %w", err)
+	}
+	return nil
+}
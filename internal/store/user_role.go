@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/model"
+)
+
+// UserRoleStore manages the roles assigned to a user through user_roles. The user's
+// primary role (users.role_id) is kept in user_roles as well. Adding or removing a
+// role bumps the user's auth version in the same transaction, so tokens issued
+// before the change stop working.
+type UserRoleStore interface {
+	ListRoleNames(ctx context.Context, userID int32) ([]string, error)
+	AddRole(ctx context.Context, userID, roleID int32) error
+	// RemoveRole returns false when the role was not assigned to the user.
+	RemoveRole(ctx context.Context, userID, roleID int32) (bool, error)
+}
+
+type userRoleStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewUserRoleStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) UserRoleStore {
+	return &userRoleStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *userRoleStore) ListRoleNames(ctx context.Context, userID int32) ([]string, error) {
+	roles, err := s.queries.ListUserRoleNames(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles of user %d from DB: %w", userID, err)
+	}
+	return roles, nil
+}
+
+func (s *userRoleStore) AddRole(ctx context.Context, userID, roleID int32) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	if err := qtx.AddUserRole(ctx, sqlc.AddUserRoleParams{UserID: userID, RoleID: roleID}); err != nil {
+		return fmt.Errorf("failed to add user role in DB: %w", err)
+	}
+	if _, err := qtx.BumpUserAuthVersion(ctx, userID); err != nil {
+		return fmt.Errorf("failed to bump user auth version in DB: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *userRoleStore) RemoveRole(ctx context.Context, userID, roleID int32) (bool, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	rows, err := qtx.RemoveUserRole(ctx, sqlc.RemoveUserRoleParams{UserID: userID, RoleID: roleID})
+	if err != nil {
+		return false, fmt.Errorf("failed to remove user role in DB: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+	if _, err := qtx.BumpUserAuthVersion(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to bump user auth version in DB: %w", err)
+	}
+	return true, tx.Commit()
+}
+
+// RoleHierarchyStore loads the parent links between roles.
+type RoleHierarchyStore interface {
+	GetHierarchy(ctx context.Context) (model.RoleHierarchy, error)
+}
+
+type roleHierarchyStore struct {
+	*BaseRepository
+	queries *sqlc.Queries
+}
+
+func NewRoleHierarchyStore(db *sql.DB, queries *sqlc.Queries, baseRepo *BaseRepository) RoleHierarchyStore {
+	return &roleHierarchyStore{BaseRepository: baseRepo, queries: queries}
+}
+
+func (s *roleHierarchyStore) GetHierarchy(ctx context.Context) (model.RoleHierarchy, error) {
+	rows, err := s.queries.ListRoleParents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role hierarchy from DB: %w", err)
+	}
+	hierarchy := make(model.RoleHierarchy, len(rows))
+	for _, row := range rows {
+		hierarchy[row.Name] = row.ParentName.String
+	}
+	return hierarchy, nil
+}
+
+// cachedRoleHierarchyStore keeps the whole hierarchy in memory for ttl. It is read on
+// every role and permission check, and changes to it are rare.
+type cachedRoleHierarchyStore struct {
+	RoleHierarchyStore
+	ttl       time.Duration
+	hierarchy model.RoleHierarchy
+	expiresAt time.Time
+	mu        sync.RWMutex
+}
+
+func NewCachedRoleHierarchyStore(inner RoleHierarchyStore, ttl time.Duration) RoleHierarchyStore {
+	return &cachedRoleHierarchyStore{RoleHierarchyStore: inner, ttl: ttl}
+}
+
+func (c *cachedRoleHierarchyStore) GetHierarchy(ctx context.Context) (model.RoleHierarchy, error) {
+	if c.ttl <= 0 {
+		return c.RoleHierarchyStore.GetHierarchy(ctx)
+	}
+
+	c.mu.RLock()
+	hierarchy, expiresAt := c.hierarchy, c.expiresAt
+	c.mu.RUnlock()
+	if hierarchy != nil && time.Now().Before(expiresAt) {
+		return hierarchy, nil
+	}
+
+	hierarchy, err := c.RoleHierarchyStore.GetHierarchy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.hierarchy = hierarchy
+	c.expiresAt = time.Now().Add(c.ttl)
+	c.mu.Unlock()
+	return hierarchy, nil
+}
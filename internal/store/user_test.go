@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"external-backend-go/db/sqlc"
+	"external-backend-go/internal/database"
+	"external-backend-go/internal/logger"
+)
+
+// newTestDB connects to the database named by TEST_DATABASE_URL and migrates it. Tests
+// that need it are skipped when the variable is not set.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	if err := database.RunMigrations(databaseURL, "../../db/migrations"); err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.ConnectDB(databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// createTestUser creates a user with a unique name that is removed when the test ends.
+func createTestUser(t *testing.T, queries *sqlc.Queries, name string) sqlc.User {
+	t.Helper()
+	ctx := context.Background()
+	role, err := queries.GetRoleByName(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	username := fmt.Sprintf("%s-%d", name, time.Now().UnixNano())
+	user, err := queries.CreateUser(ctx, sqlc.CreateUserParams{
+		Username:       username,
+		HashedPassword: "",
+		Email:          username + "@example.com",
+		RoleID:         role.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = queries.DeleteUser(context.Background(), user.ID) })
+	return user
+}
+
+func TestPurgeDeletedUsersKeepsPendingErasures(t *testing.T) {
+	db := newTestDB(t)
+	queries := sqlc.New(db)
+	users := NewUserStore(db, queries, NewBaseRepository(db, logger.NewLogger()))
+	ctx := context.Background()
+
+	// Both users were soft-deleted past the retention period; one of them asked to be
+	// erased shortly before the purge ran.
+	deleted := createTestUser(t, queries, "purge-deleted")
+	erasing := createTestUser(t, queries, "purge-erasing")
+	for _, user := range []sqlc.User{deleted, erasing} {
+		if _, err := users.SoftDeleteUser(ctx, user.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.ExecContext(ctx, "UPDATE users SET deleted_at = NOW() - INTERVAL '40 days' WHERE id = $1", user.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := users.ScheduleErasure(ctx, erasing.ID, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := users.PurgeDeletedUsers(ctx, time.Now().Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeDeletedUsers: %v", err)
+	}
+	if !slices.Contains(purged, deleted.ID) {
+		t.Errorf("purged = %v, want it to contain %d", purged, deleted.ID)
+	}
+	if slices.Contains(purged, erasing.ID) {
+		t.Errorf("purged = %v, want user %d with a pending erasure kept", purged, erasing.ID)
+	}
+
+	due, err := users.ListDueForErasure(ctx, 1000)
+	if err != nil {
+		t.Fatalf("ListDueForErasure: %v", err)
+	}
+	if !slices.Contains(due, erasing.ID) {
+		t.Errorf("due for erasure = %v, want it to contain %d", due, erasing.ID)
+	}
+}
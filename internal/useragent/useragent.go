@@ -0,0 +1,120 @@
+// Package useragent extracts a human readable browser, operating system and device
+// type from a User-Agent header. It only recognises common clients; anything else is
+// reported as "Unknown".
+package useragent
+
+import "strings"
+
+const Unknown = "Unknown"
+
+// Device types reported in Info.Device.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+type Info struct {
+	Browser string
+	OS      string
+	Device  string
+}
+
+type browserRule struct {
+	name   string
+	tokens []string
+}
+
+// browserRules are checked in order. Most browsers include the tokens of the engines
+// they are based on (Edge sends "Chrome/" and "Safari/"), so the more specific
+// products come first.
+var browserRules = []browserRule{
+	{name: "Edge", tokens: []string{"Edg/", "EdgA/", "EdgiOS/"}},
+	{name: "Opera", tokens: []string{"OPR/", "Opera/"}},
+	{name: "Samsung Internet", tokens: []string{"SamsungBrowser/"}},
+	{name: "Firefox", tokens: []string{"Firefox/", "FxiOS/"}},
+	{name: "Chrome", tokens: []string{"Chrome/", "CriOS/"}},
+	{name: "Safari", tokens: []string{"Version/"}},
+	{name: "curl", tokens: []string{"curl/"}},
+	{name: "Postman", tokens: []string{"PostmanRuntime/"}},
+}
+
+type osRule struct {
+	name  string
+	token string
+}
+
+var osRules = []osRule{
+	{name: "iPadOS", token: "iPad"},
+	{name: "iOS", token: "iPhone"},
+	{name: "Android", token: "Android"},
+	{name: "ChromeOS", token: "CrOS"},
+	{name: "Windows", token: "Windows"},
+	{name: "macOS", token: "Mac OS X"},
+	{name: "Linux", token: "Linux"},
+}
+
+// Parse describes the client that sent userAgent.
+func Parse(userAgent string) Info {
+	info := Info{Browser: Unknown, OS: Unknown, Device: Unknown}
+	if userAgent == "" {
+		return info
+	}
+
+	info.Browser = parseBrowser(userAgent)
+	for _, rule := range osRules {
+		if strings.Contains(userAgent, rule.token) {
+			info.OS = rule.name
+			break
+		}
+	}
+	info.Device = parseDevice(userAgent, info.OS)
+	return info
+}
+
+func parseBrowser(userAgent string) string {
+	for _, rule := range browserRules {
+		for _, token := range rule.tokens {
+			idx := strings.Index(userAgent, token)
+			if idx < 0 {
+				continue
+			}
+			if version := majorVersion(userAgent[idx+len(token):]); version != "" {
+				return rule.name + " " + version
+			}
+			return rule.name
+		}
+	}
+	return Unknown
+}
+
+// majorVersion returns the leading number of a product version such as "120.0.6099.71".
+func majorVersion(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
+
+func parseDevice(userAgent, os string) string {
+	lower := strings.ToLower(userAgent)
+	for _, token := range []string{"bot", "crawler", "spider"} {
+		if strings.Contains(lower, token) {
+			return DeviceBot
+		}
+	}
+
+	switch {
+	case os == "iPadOS", strings.Contains(lower, "tablet"):
+		return DeviceTablet
+	case os == "Android" && !strings.Contains(userAgent, "Mobile"):
+		return DeviceTablet
+	case os == "iOS", os == "Android", strings.Contains(userAgent, "Mobile"):
+		return DeviceMobile
+	case os != Unknown:
+		return DeviceDesktop
+	}
+	return Unknown
+}
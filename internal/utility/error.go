@@ -39,6 +39,11 @@ func BadRequestResponse(w http.ResponseWriter, r *http.Request, err error, appLo
 	ErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err))
 }
 
+func ConflictResponse(w http.ResponseWriter, r *http.Request, err error, appLogger *logger.Logger) {
+	appLogger.Warn("Conflict: %v", err)
+	ErrorResponse(w, http.StatusConflict, err.Error())
+}
+
 func NotFoundResponse(w http.ResponseWriter, r *http.Request, appLogger *logger.Logger) {
 	appLogger.Warn("Resource not found: %s %s", r.Method, r.URL.Path)
 	ErrorResponse(w, http.StatusNotFound, "Resource not found.")